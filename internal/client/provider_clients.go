@@ -54,6 +54,11 @@ type OracleClients struct {
 	Configuration     map[string]string
 	SdkClientMap      map[string]interface{}
 	WorkRequestClient *oci_work_requests.WorkRequestClient
+	// The default_tags and the ignored tags of the provider configuration, each aliased provider has its own
+	DefaultFreeformTags map[string]interface{}
+	DefaultDefinedTags  map[string]interface{}
+	IgnoreFreeformTags  []string
+	IgnoreDefinedTags   []string
 }
 
 func (m *OracleClients) GetClient(name string) interface{} {
@@ -55,10 +55,30 @@ func (ociRes *OCIResource) GetHCLString(builder *strings.Builder, interpolationM
 		}
 	}
 
+	getHclStringFn := GetHclStringFromGenericMap
 	if ociRes.GetHclStringFn != nil {
-		return ociRes.GetHclStringFn(builder, ociRes, resourceInterpolationMap)
+		getHclStringFn = ociRes.GetHclStringFn
 	}
-	return GetHclStringFromGenericMap(builder, ociRes, resourceInterpolationMap)
+	if ociRes.Provider == "" {
+		return getHclStringFn(builder, ociRes, resourceInterpolationMap)
+	}
+
+	// The provider is added as the first argument of the block, since the HCL string functions do not know about it
+	resourceBuilder := &strings.Builder{}
+	if err := getHclStringFn(resourceBuilder, ociRes, resourceInterpolationMap); err != nil {
+		return err
+	}
+	resourceHcl := resourceBuilder.String()
+	if blockStart := strings.Index(resourceHcl, "{\n"); blockStart >= 0 {
+		resourceHcl = fmt.Sprintf("%sprovider = %s\n%s", resourceHcl[:blockStart+2], TfHclVersionvar.GetReference(ociRes.Provider), resourceHcl[blockStart+2:])
+	}
+	builder.WriteString(resourceHcl)
+	return nil
+}
+
+// GetRegionProviderAlias returns the alias of the provider configuration for a region e.g. us_ashburn_1 for us-ashburn-1
+func GetRegionProviderAlias(region string) string {
+	return strings.ReplaceAll(region, "-", "_")
 }
 
 func (tr *TerraformResource) GetHclReferenceIdString() string {
@@ -83,6 +103,14 @@ func GetHCLStringFromMap(builder *strings.Builder, sourceAttributes map[string]i
 			continue
 		}
 
+		if attributeVal, exists := sourceAttributes[tfAttribute]; exists && isSensitiveVariableAttribute(tfSchema) {
+			switch v := attributeVal.(type) {
+			case string, int, bool, float64:
+				builder.WriteString(fmt.Sprintf("%s = %s\n", tfAttribute, exportSensitiveAttributeAsVariable(ociRes, attributePrefix, tfAttribute, fmt.Sprintf("%v", v))))
+				continue
+			}
+		}
+
 		if attributeVal, exists := sourceAttributes[tfAttribute]; exists {
 			utils.Debugf("Writing attribute %s and value %s", tfAttribute, attributeVal)
 			switch v := attributeVal.(type) {
@@ -233,6 +261,9 @@ func GetHCLStringFromMap(builder *strings.Builder, sourceAttributes map[string]i
 			}
 			if tfAttributeVal, exists := ociRes.TerraformTypeInfo.DefaultValuesForMissingAttributes[tfAttribute]; exists {
 				builder.WriteString(fmt.Sprintf("%s = %q", tfAttribute, tfAttributeVal))
+			} else if isSensitiveVariableAttribute(tfSchema) {
+				// the value of a sensitive attribute is left empty in the secrets file instead of writing a placeholder to the configuration
+				builder.WriteString(fmt.Sprintf("%s = %s", tfAttribute, exportSensitiveAttributeAsVariable(ociRes, attributePrefix, tfAttribute, "")))
 			} else {
 				builder.WriteString(fmt.Sprintf("%s = %q", tfAttribute, globalvar.PlaceholderValueForMissingAttribute))
 			}
@@ -324,6 +355,112 @@ func (args *ExportCommandArgs) Validate() error {
 		return fmt.Errorf("[ERROR] invalid value for arument parallelism, specify a value >= 1")
 	}
 
+	if args.RateLimit < 0 {
+		return fmt.Errorf("[ERROR] invalid value for argument rate_limit, specify a value >= 0")
+	}
+
+	if args.GenerateImportBlocks {
+		if args.GenerateState {
+			return fmt.Errorf("[ERROR] generate_state and generate_imports cannot be used together")
+		}
+		if args.TFVersion != nil && *args.TFVersion != nil && (*args.TFVersion).ToString() == string(TfVersion11) {
+			return fmt.Errorf("[ERROR] generate_imports is not supported with tf_version %s", TfVersion11)
+		}
+	}
+
+	if args.NativeState {
+		if !args.GenerateState {
+			return fmt.Errorf("[ERROR] native_state can only be used with generate_state")
+		}
+		if args.TFVersion != nil && *args.TFVersion != nil && (*args.TFVersion).ToString() == string(TfVersion11) {
+			return fmt.Errorf("[ERROR] native_state is not supported with tf_version %s", TfVersion11)
+		}
+	}
+
+	if len(args.Regions) > 0 {
+		if args.GenerateState {
+			return fmt.Errorf("[ERROR] regions cannot be used with generate_state, use generate_imports to import the resources of multiple regions")
+		}
+		if args.Recursive {
+			return fmt.Errorf("[ERROR] regions cannot be used with recursive")
+		}
+	}
+
+	if args.Resume && (args.Recursive || len(args.Regions) > 0) {
+		return fmt.Errorf("[ERROR] resume cannot be used with recursive or regions")
+	}
+
+	if args.Incremental {
+		if args.Recursive || len(args.Regions) > 0 {
+			return fmt.Errorf("[ERROR] incremental cannot be used with recursive or regions")
+		}
+		if args.TFVersion != nil && *args.TFVersion != nil && (*args.TFVersion).ToString() == string(TfVersionJson) {
+			return fmt.Errorf("[ERROR] incremental is not supported with tf_version %s", TfVersionJson)
+		}
+	}
+
+	if args.ResolveExternalReferences && (args.Incremental || len(args.Regions) > 0) {
+		return fmt.Errorf("[ERROR] resolve_external_references cannot be used with incremental or regions")
+	}
+
+	if args.GroupResources {
+		if args.Incremental {
+			return fmt.Errorf("[ERROR] group_resources cannot be used with incremental")
+		}
+		if args.TFVersion != nil && *args.TFVersion != nil && (*args.TFVersion).ToString() == string(TfVersion11) {
+			return fmt.Errorf("[ERROR] group_resources is not supported with tf_version %s", TfVersion11)
+		}
+	}
+
+	if args.Snapshot {
+		if args.FromSnapshot != "" || args.Recursive || len(args.Regions) > 0 || args.Resume || args.Incremental {
+			return fmt.Errorf("[ERROR] the snapshot command cannot be used with from_snapshot, recursive, regions, resume or incremental")
+		}
+		if args.GenerateState || args.GenerateImportBlocks {
+			return fmt.Errorf("[ERROR] the snapshot command does not write the configuration, use generate_state or generate_imports with export -from_snapshot")
+		}
+	}
+
+	if args.FromSnapshot != "" {
+		if args.Recursive || len(args.Regions) > 0 || args.Resume || args.Incremental || args.ResolveExternalReferences || len(args.IDs) > 0 {
+			return fmt.Errorf("[ERROR] from_snapshot cannot be used with recursive, regions, resume, incremental, resolve_external_references or ids")
+		}
+		if args.GenerateState && !args.NativeState {
+			return fmt.Errorf("[ERROR] from_snapshot can only generate the state with native_state, terraform import reads the resources from the services")
+		}
+	}
+
+	if args.Layout != "" && args.Layout != LayoutFlat && args.Layout != LayoutModules {
+		return fmt.Errorf("[ERROR] invalid value for argument layout: '%s', supported values: %s, %s", args.Layout, LayoutFlat, LayoutModules)
+	}
+
+	if args.Layout == LayoutModules {
+		if args.Recursive {
+			return fmt.Errorf("[ERROR] layout %s cannot be used with recursive, the compartments are already exported as child modules", LayoutModules)
+		}
+		if args.GenerateState || args.GenerateImportBlocks {
+			return fmt.Errorf("[ERROR] layout %s cannot be used with generate_state or generate_imports, the services are exported as child modules", LayoutModules)
+		}
+		if len(args.Regions) > 0 || args.Incremental {
+			return fmt.Errorf("[ERROR] layout %s cannot be used with regions or incremental", LayoutModules)
+		}
+	}
+
+	if args.Recursive {
+		if args.GenerateState || args.GenerateImportBlocks {
+			return fmt.Errorf("[ERROR] recursive cannot be used with generate_state or generate_imports, the compartments are exported as child modules")
+		}
+		if len(args.IDs) > 0 {
+			return fmt.Errorf("[ERROR] recursive cannot be used with ids")
+		}
+	}
+
+	// validate and extract naming_strategy
+	if ResourceNamingStrategyVar, err = ParseResourceNamingStrategy(args.NamingStrategy); err != nil {
+		utils.Logln(err.Error())
+		return err
+	}
+
 	// validate and extract variables_resource_level
 	if args.VarsExportResourceLevel != nil {
 		VarsExportForResourceLevel, err = extractVarsExportResourceLevel(args.VarsExportResourceLevel)
@@ -586,7 +723,7 @@ func FindResourcesGeneric(ctx *ResourceDiscoveryContext, tfMeta *TerraformResour
 				}
 			}
 
-			if resource.TerraformName, err = GenerateTerraformNameFromResource(resource.SourceAttributes, elemResource.Schema); err != nil {
+			if resource.TerraformName, err = GenerateTerraformNameForResource(resource, elemResource.Schema); err != nil {
 				resource.TerraformName = fmt.Sprintf("%s_%s", parent.TerraformName, tfMeta.ResourceAbbreviation)
 				resource.TerraformName = CheckDuplicateResourceName(resource.TerraformName)
 			}
@@ -600,7 +737,7 @@ func FindResourcesGeneric(ctx *ResourceDiscoveryContext, tfMeta *TerraformResour
 			return results, err
 		}
 
-		if resource.TerraformName, err = GenerateTerraformNameFromResource(resource.SourceAttributes, datasource.Schema); err != nil {
+		if resource.TerraformName, err = GenerateTerraformNameForResource(resource, datasource.Schema); err != nil {
 			resource.TerraformName = fmt.Sprintf("%s_%s", parent.TerraformName, tfMeta.ResourceAbbreviation)
 			resource.TerraformName = CheckDuplicateResourceName(resource.TerraformName)
 		}
@@ -766,6 +903,15 @@ func GetOciResource(d *schema.ResourceData, resourceSchema map[string]*schema.Sc
 	return resource, nil
 }
 
+// GetNotFoundChildren returns the child resource types which were not discovered because of the error in discovering their parent
+func (rdError *ResourceDiscoveryError) GetNotFoundChildren() []string {
+	var notFoundChildren []string
+	if rdError.ResourceGraph != nil {
+		getNotFoundChildren(rdError.ResourceType, rdError.ResourceGraph, &notFoundChildren)
+	}
+	return notFoundChildren
+}
+
 func getNotFoundChildren(parent string, resourceGraph *TerraformResourceGraph, children *[]string) {
 	childResources, exists := (*resourceGraph)[parent]
 	if exists {
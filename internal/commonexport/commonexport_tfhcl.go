@@ -3,8 +3,9 @@ package commonexport
 import "fmt"
 
 const (
-	TfVersion11 TfVersionEnum = "0.11"
-	TfVersion12 TfVersionEnum = "0.12"
+	TfVersion11   TfVersionEnum = "0.11"
+	TfVersion12   TfVersionEnum = "0.12"
+	TfVersionJson TfVersionEnum = "json"
 )
 
 func (tfversion *TfHclVersion11) GetReference(reference string) string {
@@ -58,3 +59,34 @@ func (tfversion *TfHclVersion12) GetSingleExpHclString(expString string) string
 func (tfversion *TfHclVersion12) GetDoubleExpHclString(expString1 string, expString2 string) string {
 	return fmt.Sprintf("%s.%s", expString1, expString2)
 }
+
+// TfHclVersionJson generates configurations in Terraform JSON syntax (.tf.json)
+// Interpolations are written as quoted "${...}" templates, which have the same meaning in JSON syntax,
+// the generated configuration is then converted to JSON using ConvertHclToJson
+type TfHclVersionJson struct {
+	Value TfVersionEnum
+}
+
+func (tfversion *TfHclVersionJson) GetReference(reference string) string {
+	return fmt.Sprintf("\"%s\"", reference)
+}
+
+func (tfversion *TfHclVersionJson) ToString() string {
+	return "json"
+}
+
+func (tfversion *TfHclVersionJson) GetVarHclString(varName string) string {
+	return fmt.Sprintf("\"${var.%s}\"", varName)
+}
+
+func (tfversion *TfHclVersionJson) GetDataSourceHclString(datasourceType string, datasourceName string) string {
+	return fmt.Sprintf("\"${data.%s.%s}\"", datasourceType, datasourceName)
+}
+
+func (tfversion *TfHclVersionJson) GetSingleExpHclString(expString string) string {
+	return fmt.Sprintf("\"${%s}\"", expString)
+}
+
+func (tfversion *TfHclVersionJson) GetDoubleExpHclString(expString1 string, expString2 string) string {
+	return fmt.Sprintf("\"${%s.%s}\"", expString1, expString2)
+}
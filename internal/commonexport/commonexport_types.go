@@ -32,6 +32,7 @@ type TerraformResourceHints struct {
 	DiscoverableLifecycleStates  []string                // List of lifecycle states that should be discovered. If empty, then all lifecycle states are discoverable.
 	ProcessDiscoveredResourcesFn ProcessOCIResourcesFunc // Custom function for processing resources discovered by the data source
 	AlwaysExportable             bool                    // Some resources always need to be exportable, regardless of whether they are being targeted for export
+	IsGroupableByParent          bool                    // Whether the resources with the same parent can be written as a single resource using for_each, see group_resources
 	IsDataSource                 bool
 	GetIdFn                      func(*OCIResource) (string, error) // If the resource has no OCID generated by services, then implement this to generate one from the OCIResource. Typically used for composite IDs.
 
@@ -58,12 +59,23 @@ type OCIResource struct {
 	GetHclStringFn   func(*strings.Builder, *OCIResource, map[string]string) error
 	Parent           *OCIResource
 	IsErrorResource  bool
+	Provider         string         // provider configuration of the resource e.g. oci.us_ashburn_1, the default provider is used if not set
+	ForEachResources []*OCIResource // resources written as the instances of this resource using for_each, keyed by their Terraform name
+	SchemaViolations []*SchemaViolation // problems found by validating the configuration of the resource against its schema, set when the configuration is written
 }
 type TfHclVersion11 struct {
 	Value TfVersionEnum
 }
 type TfVersionEnum string
 
+// ExportLayoutEnum is the layout of the configuration written to the output_path
+type ExportLayoutEnum string
+
+const (
+	LayoutFlat    ExportLayoutEnum = "flat"    // the resources of each service are written to <service>.tf in the output_path
+	LayoutModules ExportLayoutEnum = "modules" // each service is written to its own directory as a child module of the root module
+)
+
 // Wrapper around string value to differentiate strings from interpolations
 // Differentiation needed to write oci_resource.resource_name vs "oci_resource.resource_name" for v0.12
 type InterpolationString struct {
@@ -96,6 +108,7 @@ type ResourceDiscoveryContext struct {
 	TimeTakenToDiscover          time.Duration
 	TimeTakenToGenerateState     time.Duration
 	TimeTakenForEntireExport     time.Duration
+	Region                       string // region of the discovery when exporting multiple regions, empty otherwise
 }
 type TerraformResource struct {
 	Id                         string
@@ -121,11 +134,26 @@ type ExportCommandArgs struct {
 	Services                     []string
 	OutputDir                    *string
 	GenerateState                bool
+	NativeState                  bool
+	GenerateImportBlocks         bool
+	Recursive                    bool
+	Regions                      []string
+	ReportPath                   string
+	GenerateGraph                bool
+	Resume                       bool
+	Incremental                  bool
+	NamingStrategy               string
+	Layout                       ExportLayoutEnum
+	ResolveExternalReferences    bool
+	GroupResources               bool
+	Snapshot                     bool   // set by the snapshot command, the discovered resources are written to a snapshot instead of the configuration
+	FromSnapshot                 string // path of a snapshot to export the resources from instead of discovering them
 	TFVersion                    *TfHclVersion
 	RetryTimeout                 *string
 	ExcludeServices              []string
 	IsExportWithRelatedResources bool
 	Parallelism                  int
+	RateLimit                    float64 // maximum requests per second to each service, the rate limiter is not used if it is 0
 	VarsExportResourceLevel      []string
 	VarExportGlobalLevel         []string
 	Filters                      []ResourceFilter
@@ -196,7 +224,7 @@ var GetHclStringFromGenericMap = func(builder *strings.Builder, ociRes *OCIResou
 		return err
 	}
 	utils.Debugf("getHCLStringFromMap for resource %s", ociRes.TerraformName)
-	if err := GetHCLStringFromMap(builder, ociRes.SourceAttributes, resourceSchema, interpolationMap, ociRes, ""); err != nil {
+	if err := getValidatedHCLStringFromMap(builder, ociRes, resourceSchema, interpolationMap); err != nil {
 		return err
 	}
 
@@ -231,12 +259,14 @@ var CompartmentResourceGraphs = make(map[string]TerraformResourceGraph)
 var TenancyResourceGraphs = map[string]TerraformResourceGraph{}
 var RefMapLock sync.Mutex
 var ResourceNameCountLock sync.Mutex
+var SensitiveVarsLock sync.Mutex // the configuration of the steps is written in parallel
 var ReferenceMap map[string]string                              //	stores references to replace the ocids in config
 var LoadBalancerCertificateNameMap map[string]map[string]string // helper map to generate references for certificate names, stores certificate name to certificate name interpolation
 var ResourceNameCount map[string]int
 
 var DatasourcesMap map[string]*schema.Resource
 var Vars map[string]string
+var SensitiveVars map[string]string // sensitive attributes exported as variables, to the value written to the secrets file or empty if it is not known
 var ExportRelatedResourcesGraph TerraformResourceGraph = make(map[string][]TerraformResourceAssociation)
 
 var availabilityDomainResourceGraph TerraformResourceGraph = make(map[string][]TerraformResourceAssociation)
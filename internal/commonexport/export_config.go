@@ -0,0 +1,139 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultExportRateLimit is the rate_limit of an export which does not set it, the requests per second to each service are kept low enough to not be throttled
+const DefaultExportRateLimit = 10.0
+
+// ExportConfig is the file representation of the export command arguments
+// It can be written either in YAML or JSON, e.g.
+//
+//	compartment_id: ocid1.compartment.oc1..xxx
+//	output_path: /tmp/export
+//	services: [core, identity]
+//	filters:
+//	  - Type=oci_core_vcn
+//	parallelism: 4
+type ExportConfig struct {
+	CompartmentId           string   `yaml:"compartment_id" json:"compartment_id"`
+	CompartmentName         string   `yaml:"compartment_name" json:"compartment_name"`
+	OutputPath              string   `yaml:"output_path" json:"output_path"`
+	Services                []string `yaml:"services" json:"services"`
+	ExcludeServices         []string `yaml:"exclude_services" json:"exclude_services"`
+	IDs                     []string `yaml:"ids" json:"ids"`
+	Filters                 []string `yaml:"filters" json:"filters"`
+	VariablesResourceLevel  []string `yaml:"variables_resource_level" json:"variables_resource_level"`
+	VariablesGlobalLevel    []string `yaml:"variables_global_level" json:"variables_global_level"`
+	Parallelism             int      `yaml:"parallelism" json:"parallelism"`
+	RetryTimeout            string   `yaml:"retry_timeout" json:"retry_timeout"`
+	RateLimit               *float64 `yaml:"rate_limit" json:"rate_limit"` // nil if it is not set, as 0 does not limit the requests
+	GenerateState           bool     `yaml:"generate_state" json:"generate_state"`
+	NativeState             bool     `yaml:"native_state" json:"native_state"`
+	GenerateImportBlocks    bool     `yaml:"generate_imports" json:"generate_imports"`
+	Recursive               bool     `yaml:"recursive" json:"recursive"`
+	Regions                 []string `yaml:"regions" json:"regions"`
+	ReportPath              string   `yaml:"report_path" json:"report_path"`
+	GenerateGraph           bool     `yaml:"generate_graph" json:"generate_graph"`
+	Resume                  bool     `yaml:"resume" json:"resume"`
+	Incremental             bool     `yaml:"incremental" json:"incremental"`
+	NamingStrategy          string   `yaml:"naming_strategy" json:"naming_strategy"`
+	Layout                  string   `yaml:"layout" json:"layout"`
+	ResolveExternalRefs     bool     `yaml:"resolve_external_references" json:"resolve_external_references"`
+	GroupResources          bool     `yaml:"group_resources" json:"group_resources"`
+	FromSnapshot            string   `yaml:"from_snapshot" json:"from_snapshot"`
+	IncludeRelatedResources bool     `yaml:"include_related_resources" json:"include_related_resources"`
+	TfVersion               string   `yaml:"tf_version" json:"tf_version"`
+}
+
+// LoadExportConfig reads the export configuration file at the given path
+// Files with a `.json` extension are parsed as JSON, anything else is parsed as YAML
+func LoadExportConfig(path string) (*ExportConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to read export config file %s: %s", path, err.Error())
+	}
+
+	config := &ExportConfig{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		// Unknown keys are rejected like in YAML, so that a misspelled option is not silently ignored
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(config)
+	} else {
+		err = yaml.UnmarshalStrict(content, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to parse export config file %s: %s", path, err.Error())
+	}
+	return config, nil
+}
+
+// ToExportCommandArgs converts the config file into the export command arguments
+// The arguments are validated using ExportCommandArgs.Validate
+func (config *ExportConfig) ToExportCommandArgs(tfVersion *TfHclVersion) (*ExportCommandArgs, error) {
+	compartmentId := config.CompartmentId
+	compartmentName := config.CompartmentName
+	outputPath := config.OutputPath
+	retryTimeout := config.RetryTimeout
+
+	args := &ExportCommandArgs{
+		CompartmentId:                &compartmentId,
+		CompartmentName:              &compartmentName,
+		OutputDir:                    &outputPath,
+		GenerateState:                config.GenerateState,
+		NativeState:                  config.NativeState,
+		GenerateImportBlocks:         config.GenerateImportBlocks,
+		Recursive:                    config.Recursive,
+		Regions:                      config.Regions,
+		ReportPath:                   config.ReportPath,
+		GenerateGraph:                config.GenerateGraph,
+		Resume:                       config.Resume,
+		Incremental:                  config.Incremental,
+		NamingStrategy:               config.NamingStrategy,
+		Layout:                       ExportLayoutEnum(config.Layout),
+		ResolveExternalReferences:    config.ResolveExternalRefs,
+		GroupResources:               config.GroupResources,
+		FromSnapshot:                 config.FromSnapshot,
+		TFVersion:                    tfVersion,
+		RetryTimeout:                 &retryTimeout,
+		IsExportWithRelatedResources: config.IncludeRelatedResources,
+		Parallelism:                  config.Parallelism,
+		RateLimit:                    DefaultExportRateLimit,
+		Services:                     config.Services,
+		ExcludeServices:              config.ExcludeServices,
+		IDs:                          config.IDs,
+		VarsExportResourceLevel:      config.VariablesResourceLevel,
+		VarExportGlobalLevel:         config.VariablesGlobalLevel,
+	}
+
+	if config.RateLimit != nil {
+		args.RateLimit = *config.RateLimit
+	}
+
+	var filters Filter
+	for _, rawFilter := range config.Filters {
+		if err := filters.Set(rawFilter); err != nil {
+			return nil, err
+		}
+	}
+	if len(filters) > 0 {
+		args.Filters = filters
+	}
+
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUnitLoadExportConfig(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "export-config")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	expected := &ExportConfig{
+		CompartmentId:          "ocid1.compartment.oc1..a",
+		OutputPath:             outputDir,
+		Services:               []string{"core", "identity"},
+		ExcludeServices:        []string{"object_storage"},
+		IDs:                    []string{"oci_core_vcn:ocid1.vcn.oc1..a"},
+		Filters:                []string{"Type=oci_core_vcn"},
+		VariablesResourceLevel: []string{"oci_core_instance.display_name"},
+		VariablesGlobalLevel:   []string{"availability_domain"},
+		Parallelism:            4,
+		RetryTimeout:           "30s",
+	}
+
+	tests := []struct {
+		testName string
+		fileName string
+		content  string
+		err      bool
+	}{
+		{
+			"ValidYaml",
+			"export.yaml",
+			`compartment_id: ocid1.compartment.oc1..a
+output_path: ` + outputDir + `
+services: [core, identity]
+exclude_services: [object_storage]
+ids:
+  - oci_core_vcn:ocid1.vcn.oc1..a
+filters:
+  - Type=oci_core_vcn
+variables_resource_level: [oci_core_instance.display_name]
+variables_global_level: [availability_domain]
+parallelism: 4
+retry_timeout: 30s
+`,
+			false,
+		},
+		{
+			"ValidJson",
+			"export.json",
+			`{"compartment_id": "ocid1.compartment.oc1..a", "output_path": "` + outputDir + `",
+"services": ["core", "identity"], "exclude_services": ["object_storage"],
+"ids": ["oci_core_vcn:ocid1.vcn.oc1..a"], "filters": ["Type=oci_core_vcn"],
+"variables_resource_level": ["oci_core_instance.display_name"], "variables_global_level": ["availability_domain"],
+"parallelism": 4, "retry_timeout": "30s"}`,
+			false,
+		},
+		{
+			"UnknownYamlField",
+			"unknown.yaml",
+			"compartment: ocid1.compartment.oc1..a\n",
+			true,
+		},
+		{
+			"UnknownJsonField",
+			"unknown.json",
+			`{"compartment": "ocid1.compartment.oc1..a"}`,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			path := filepath.Join(outputDir, tt.fileName)
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("unable to write config file: %v", err)
+			}
+
+			config, err := LoadExportConfig(path)
+			if (err != nil) != tt.err {
+				t.Fatalf("LoadExportConfig() error = %v, wantErr %v", err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(config, expected) {
+				t.Errorf("got %+v, want %+v", config, expected)
+			}
+		})
+	}
+
+	if _, err := LoadExportConfig(filepath.Join(outputDir, "missing.yaml")); err == nil {
+		t.Errorf("LoadExportConfig() expected error for missing file")
+	}
+}
+
+func TestUnitExportConfigToExportCommandArgs(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "export-config")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	var tfVersion TfHclVersion = &TfHclVersion12{Value: TfVersion12}
+	rateLimit := func(value float64) *float64 { return &value }
+
+	tests := []struct {
+		testName string
+		config   *ExportConfig
+		err      bool
+	}{
+		{
+			"ValidConfig",
+			&ExportConfig{
+				CompartmentId: "ocid1.compartment.oc1..a",
+				OutputPath:    outputDir,
+				Services:      []string{"core"},
+				Filters:       []string{"Type=oci_core_vcn", "AttrName=display_name;Value=vcn1"},
+				Parallelism:   2,
+				RateLimit:     rateLimit(5),
+				Layout:        "modules",
+			},
+			false,
+		},
+		{
+			"DefaultRateLimit",
+			&ExportConfig{
+				OutputPath:  outputDir,
+				Parallelism: 1,
+			},
+			false,
+		},
+		{
+			"RateLimitDisabled",
+			&ExportConfig{
+				OutputPath:  outputDir,
+				Parallelism: 1,
+				RateLimit:   rateLimit(0),
+			},
+			false,
+		},
+		{
+			"InvalidFilter",
+			&ExportConfig{
+				OutputPath:  outputDir,
+				Filters:     []string{"Type=>oci_core_vcn"},
+				Parallelism: 1,
+			},
+			true,
+		},
+		{
+			"MissingOutputPath",
+			&ExportConfig{
+				Parallelism: 1,
+			},
+			true,
+		},
+		{
+			"InvalidParallelism",
+			&ExportConfig{
+				OutputPath: outputDir,
+			},
+			true,
+		},
+		{
+			"InvalidRateLimit",
+			&ExportConfig{
+				OutputPath:  outputDir,
+				Parallelism: 1,
+				RateLimit:   rateLimit(-1),
+			},
+			true,
+		},
+		{
+			"InvalidLayout",
+			&ExportConfig{
+				OutputPath:  outputDir,
+				Parallelism: 1,
+				Layout:      "nested",
+			},
+			true,
+		},
+		{
+			"LayoutModulesWithRecursive",
+			&ExportConfig{
+				OutputPath:  outputDir,
+				Parallelism: 1,
+				Layout:      "modules",
+				Recursive:   true,
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			args, err := tt.config.ToExportCommandArgs(&tfVersion)
+			if (err != nil) != tt.err {
+				t.Fatalf("ToExportCommandArgs() error = %v, wantErr %v", err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+			if *args.CompartmentId != tt.config.CompartmentId || *args.OutputDir != tt.config.OutputPath {
+				t.Errorf("unexpected compartment or output path in %+v", args)
+			}
+			if len(args.Filters) != len(tt.config.Filters) {
+				t.Errorf("got %d filters, want %d", len(args.Filters), len(tt.config.Filters))
+			}
+			expectedRateLimit := DefaultExportRateLimit
+			if tt.config.RateLimit != nil {
+				expectedRateLimit = *tt.config.RateLimit
+			}
+			if args.Parallelism != tt.config.Parallelism || args.RateLimit != expectedRateLimit || string(args.Layout) != tt.config.Layout || args.TFVersion != &tfVersion {
+				t.Errorf("unexpected parallelism, rate limit, layout or tf version in %+v", args)
+			}
+		})
+	}
+}
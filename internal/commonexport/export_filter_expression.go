@@ -0,0 +1,462 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter expression grammar, keywords are case insensitive
+//
+//	expression = and_expr { ("OR" | "||") and_expr }
+//	and_expr   = not_expr { ("AND" | "&&") not_expr }
+//	not_expr   = ("NOT" | "!") not_expr | "(" expression ")" | comparison
+//	comparison = path operator value
+//	operator   = "=" | "==" | "!=" | "~" | "!~" | "<" | "<=" | ">" | ">="
+//	value      = quoted string | bare word e.g. ACTIVE, 10, 2023-01-01T00:00:00Z
+//
+// path is a dot separated path in the resource attributes e.g. defined_tags.example-namespace.example-key
+// `type` (case insensitive, as in the Type filter) refers to the terraform resource type and `lifecycle_state` to the `state` attribute of the resource
+// `~` matches the value as a regular expression, `<`, `<=`, `>` and `>=` compare numbers or times e.g. time_created > 2023-01-01
+// A comparison is true if any value found at the path satisfies it, `!=` and `!~` are the negation of `=` and `~`
+//
+// Examples
+// type = oci_core_vcn AND display_name ~ "^prod-"
+// (lifecycle_state = ACTIVE OR lifecycle_state = AVAILABLE) AND NOT freeform_tags.env = dev
+// type = oci_core_instance AND time_created >= "2023-01-01" AND shape_config.ocpus > 2
+
+const (
+	filterTypeAttribute           = "type"
+	filterLifecycleStateAttribute = "lifecycle_state"
+)
+
+// layouts accepted for times in filter expressions and resource attributes
+var filterTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST", // format of time attributes in the resource data
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ExpressionFilter is a ResourceFilter compiled from a boolean filter expression
+type ExpressionFilter struct {
+	Expression string
+	root       filterExpression
+}
+
+type filterExpression interface {
+	evaluate(resource *OCIResource) bool
+}
+
+type andFilterExpression struct {
+	left, right filterExpression
+}
+
+type orFilterExpression struct {
+	left, right filterExpression
+}
+
+type notFilterExpression struct {
+	expression filterExpression
+}
+
+type comparisonFilterExpression struct {
+	path     string
+	operator string
+	value    string
+	regex    *regexp.Regexp
+	number   *float64
+	time     *time.Time
+}
+
+// Filter returns true if the resource satisfies the expression
+func (ef *ExpressionFilter) Filter(resource *OCIResource) bool {
+	if resource == nil || ef.root == nil {
+		return false
+	}
+	return ef.root.evaluate(resource)
+}
+
+func (e *andFilterExpression) evaluate(resource *OCIResource) bool {
+	return e.left.evaluate(resource) && e.right.evaluate(resource)
+}
+
+func (e *orFilterExpression) evaluate(resource *OCIResource) bool {
+	return e.left.evaluate(resource) || e.right.evaluate(resource)
+}
+
+func (e *notFilterExpression) evaluate(resource *OCIResource) bool {
+	return !e.expression.evaluate(resource)
+}
+
+func (e *comparisonFilterExpression) evaluate(resource *OCIResource) bool {
+	switch e.operator {
+	case "!=":
+		return !e.matchAny(resource, "=")
+	case "!~":
+		return !e.matchAny(resource, "~")
+	}
+	return e.matchAny(resource, e.operator)
+}
+
+// matchAny returns true if any of the values of the resource at the path satisfies the operator
+func (e *comparisonFilterExpression) matchAny(resource *OCIResource, operator string) bool {
+	for _, value := range getFilterPathValues(resource, e.path) {
+		if e.match(value, operator) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *comparisonFilterExpression) match(value string, operator string) bool {
+	switch operator {
+	case "=":
+		return value == e.value
+	case "~":
+		return e.regex.MatchString(value)
+	}
+
+	var compared int
+	if e.number != nil {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		compared = compareFloats(number, *e.number)
+	} else {
+		t, ok := parseFilterTime(value)
+		if !ok {
+			return false
+		}
+		compared = compareTimes(t, *e.time)
+	}
+
+	switch operator {
+	case "<":
+		return compared < 0
+	case "<=":
+		return compared <= 0
+	case ">":
+		return compared > 0
+	case ">=":
+		return compared >= 0
+	}
+	return false
+}
+
+// ParseFilterExpression compiles a boolean filter expression to a ResourceFilter
+func ParseFilterExpression(expression string) (*ExpressionFilter, error) {
+	tokens, err := tokenizeFilterExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+
+	parser := &filterExpressionParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token != nil {
+		return nil, fmt.Errorf("unexpected '%s' at position %d in filter expression", token.value, token.position)
+	}
+
+	return &ExpressionFilter{Expression: expression, root: root}, nil
+}
+
+type filterTokenKind int
+
+const (
+	filterTokenWord filterTokenKind = iota
+	filterTokenString
+	filterTokenOperator
+	filterTokenAnd
+	filterTokenOr
+	filterTokenNot
+	filterTokenLeftParen
+	filterTokenRightParen
+)
+
+type filterToken struct {
+	kind     filterTokenKind
+	value    string
+	position int
+}
+
+// characters which end a bare word
+const filterOperatorCharacters = "=!~<>()\"&|"
+
+func tokenizeFilterExpression(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{filterTokenLeftParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{filterTokenRightParen, ")", i})
+			i++
+		case r == '"':
+			// only \" and \\ are unescaped, other backslashes are kept as written so that regex escapes like \d can be used as is
+			value := strings.Builder{}
+			end := i + 1
+			for ; end < len(runes) && runes[end] != '"'; end++ {
+				if runes[end] == '\\' && end+1 < len(runes) && (runes[end+1] == '"' || runes[end+1] == '\\') {
+					end++
+				}
+				value.WriteRune(runes[end])
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d in filter expression", i)
+			}
+			tokens = append(tokens, filterToken{filterTokenString, value.String(), i})
+			i = end + 1
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("unexpected '%c' at position %d in filter expression", r, i)
+			}
+			kind := filterTokenAnd
+			if r == '|' {
+				kind = filterTokenOr
+			}
+			tokens = append(tokens, filterToken{kind, string(runes[i : i+2]), i})
+			i += 2
+		case strings.ContainsRune("=!~<>", r):
+			operator := string(r)
+			if i+1 < len(runes) && ((r != '~' && runes[i+1] == '=') || (r == '!' && runes[i+1] == '~')) {
+				operator = string(runes[i : i+2])
+			}
+			kind := filterTokenOperator
+			if operator == "!" {
+				kind = filterTokenNot
+			}
+			tokens = append(tokens, filterToken{kind, operator, i})
+			i += len(operator)
+		default:
+			start := i
+			for ; i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(filterOperatorCharacters, runes[i]); i++ {
+			}
+			word := string(runes[start:i])
+			kind := filterTokenWord
+			switch strings.ToUpper(word) {
+			case "AND":
+				kind = filterTokenAnd
+			case "OR":
+				kind = filterTokenOr
+			case "NOT":
+				kind = filterTokenNot
+			}
+			tokens = append(tokens, filterToken{kind, word, start})
+		}
+	}
+	return tokens, nil
+}
+
+type filterExpressionParser struct {
+	tokens   []filterToken
+	position int
+}
+
+func (p *filterExpressionParser) peek() *filterToken {
+	if p.position >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.position]
+}
+
+func (p *filterExpressionParser) next(expected string) (*filterToken, error) {
+	token := p.peek()
+	if token == nil {
+		return nil, fmt.Errorf("unexpected end of filter expression, expected %s", expected)
+	}
+	p.position++
+	return token, nil
+}
+
+func (p *filterExpressionParser) parseOr() (filterExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for token := p.peek(); token != nil && token.kind == filterTokenOr; token = p.peek() {
+		p.position++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orFilterExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterExpressionParser) parseAnd() (filterExpression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for token := p.peek(); token != nil && token.kind == filterTokenAnd; token = p.peek() {
+		p.position++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andFilterExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterExpressionParser) parseNot() (filterExpression, error) {
+	token, err := p.next("a comparison")
+	if err != nil {
+		return nil, err
+	}
+
+	switch token.kind {
+	case filterTokenNot:
+		expression, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notFilterExpression{expression: expression}, nil
+	case filterTokenLeftParen:
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, err := p.next("')'")
+		if err != nil {
+			return nil, err
+		}
+		if closing.kind != filterTokenRightParen {
+			return nil, fmt.Errorf("expected ')' at position %d in filter expression, found '%s'", closing.position, closing.value)
+		}
+		return expression, nil
+	case filterTokenWord:
+		return p.parseComparison(token)
+	}
+	return nil, fmt.Errorf("unexpected '%s' at position %d in filter expression", token.value, token.position)
+}
+
+func (p *filterExpressionParser) parseComparison(path *filterToken) (filterExpression, error) {
+	operator, err := p.next("an operator")
+	if err != nil {
+		return nil, err
+	}
+	if operator.kind != filterTokenOperator {
+		return nil, fmt.Errorf("expected an operator after '%s' at position %d in filter expression, found '%s'", path.value, operator.position, operator.value)
+	}
+	value, err := p.next("a value")
+	if err != nil {
+		return nil, err
+	}
+	if value.kind != filterTokenWord && value.kind != filterTokenString {
+		return nil, fmt.Errorf("expected a value after '%s' at position %d in filter expression, found '%s'", operator.value, value.position, value.value)
+	}
+
+	comparison := &comparisonFilterExpression{path: path.value, operator: operator.value, value: value.value}
+	switch comparison.operator {
+	case "==":
+		comparison.operator = "="
+	case "~", "!~":
+		if comparison.regex, err = regexp.Compile(value.value); err != nil {
+			return nil, fmt.Errorf("invalid regular expression '%s' in filter expression: %s", value.value, err.Error())
+		}
+	case "<", "<=", ">", ">=":
+		if number, err := strconv.ParseFloat(value.value, 64); err == nil {
+			comparison.number = &number
+		} else if t, ok := parseFilterTime(value.value); ok {
+			comparison.time = &t
+		} else {
+			return nil, fmt.Errorf("'%s' at position %d in filter expression is neither a number nor a time, %s can only compare numbers and times", value.value, value.position, operator.value)
+		}
+	}
+	return comparison, nil
+}
+
+// getFilterPathValues returns the values of the resource at the path of a filter expression
+func getFilterPathValues(resource *OCIResource, path string) []string {
+	switch strings.ToLower(path) {
+	case filterTypeAttribute:
+		return []string{resource.TerraformClass}
+	case filterLifecycleStateAttribute:
+		path = "state"
+	}
+
+	var values []string
+	collectFilterPathValues(path, resource.SourceAttributes, &values)
+	return values
+}
+
+// collectFilterPathValues walks the nested attributes along the path, lists are walked element by element
+// map keys may contain dots e.g. defined tags are keyed by `namespace.key`
+func collectFilterPathValues(path string, data interface{}, values *[]string) {
+	if data == nil {
+		return
+	}
+
+	val := reflect.ValueOf(data)
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			collectFilterPathValues(path, val.Index(i).Interface(), values)
+		}
+	case reflect.Map:
+		if path == "" {
+			return
+		}
+		for _, key := range val.MapKeys() {
+			keyString := fmt.Sprint(key.Interface())
+			if keyString == path {
+				collectFilterPathValues("", val.MapIndex(key).Interface(), values)
+			} else if strings.HasPrefix(path, keyString+".") {
+				collectFilterPathValues(strings.TrimPrefix(path, keyString+"."), val.MapIndex(key).Interface(), values)
+			}
+		}
+	default:
+		if path == "" {
+			*values = append(*values, fmt.Sprint(data))
+		}
+	}
+}
+
+func parseFilterTime(value string) (time.Time, bool) {
+	for _, layout := range filterTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func compareFloats(a float64, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func compareTimes(a time.Time, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	} else if a.After(b) {
+		return 1
+	}
+	return 0
+}
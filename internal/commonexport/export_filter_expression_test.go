@@ -0,0 +1,145 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"testing"
+)
+
+func TestUnitExpressionFilter(t *testing.T) {
+	vcn := &OCIResource{
+		TerraformResource: TerraformResource{Id: "ocid1.vcn.1", TerraformClass: "oci_core_vcn", TerraformName: "export_vcn1"},
+		SourceAttributes: map[string]interface{}{
+			"display_name": "prod-vcn",
+			"state":        "AVAILABLE",
+			"time_created": "2023-03-01 10:00:00 +0000 UTC",
+			"defined_tags": map[string]interface{}{"example-namespace.example-key": "example-value"},
+			"cidr_blocks":  []interface{}{"10.0.0.0/16", "10.1.0.0/16"},
+		},
+	}
+	instance := &OCIResource{
+		TerraformResource: TerraformResource{Id: "ocid1.instance.1", TerraformClass: "oci_core_instance", TerraformName: "export_instance1"},
+		SourceAttributes: map[string]interface{}{
+			"display_name":  "dev-instance",
+			"state":         "RUNNING",
+			"time_created":  "2022-12-31T23:59:59Z",
+			"shape_config":  []interface{}{map[string]interface{}{"ocpus": 4.0}},
+			"freeform_tags": map[string]interface{}{"env": "dev"},
+		},
+	}
+
+	tests := []struct {
+		testName   string
+		expression string
+		vcn        bool
+		instance   bool
+	}{
+		{"Type", "type = oci_core_vcn", true, false},
+		{"TypeCaseInsensitive", "Type = oci_core_vcn", true, false},
+		{"DoubleEqual", "type == oci_core_instance", false, true},
+		{"NotEqual", "type != oci_core_vcn", false, true},
+		{"Regex", "display_name ~ \"^prod-\"", true, false},
+		{"NotRegex", "display_name !~ \"^prod-\"", false, true},
+		{"RegexEscapes", `cidr_blocks ~ "^10\.1\.0\.0/\d+$"`, true, false},
+		{"RegexEscapedBackslash", `display_name ~ "^dev\\-\w+"`, false, true},
+		{"LifecycleState", "lifecycle_state = AVAILABLE OR lifecycle_state = RUNNING", true, true},
+		{"NestedDefinedTag", "defined_tags.example-namespace.example-key = example-value", true, false},
+		{"NestedList", "shape_config.ocpus > 2", false, true},
+		{"NestedListNoMatch", "shape_config.ocpus >= 8", false, false},
+		{"AnyListValue", "cidr_blocks = \"10.1.0.0/16\"", true, false},
+		{"TimeAfter", "time_created > 2023-01-01", true, false},
+		{"TimeBefore", "time_created <= \"2023-01-01T00:00:00Z\"", false, true},
+		{"MissingAttribute", "freeform_tags.env = dev", false, true},
+		{"NotMissingAttribute", "NOT freeform_tags.env = dev", true, false},
+		{"AndBindsTighterThanOr", "type = oci_core_vcn OR type = oci_core_instance AND display_name ~ prod", true, false},
+		{"Parentheses", "(type = oci_core_vcn OR type = oci_core_instance) AND display_name ~ dev", false, true},
+		{"SymbolOperators", "!(type = oci_core_vcn) && (state = RUNNING || state = STOPPED)", false, true},
+		{"CaseInsensitiveKeywords", "type = oci_core_vcn and not state = TERMINATED", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			filter, err := ParseFilterExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("unexpected error parsing %s: %v", tt.expression, err)
+			}
+			if got := filter.Filter(vcn); got != tt.vcn {
+				t.Errorf("vcn: got %v, want %v", got, tt.vcn)
+			}
+			if got := filter.Filter(instance); got != tt.instance {
+				t.Errorf("instance: got %v, want %v", got, tt.instance)
+			}
+		})
+	}
+}
+
+func TestUnitParseFilterExpressionErrors(t *testing.T) {
+	tests := []struct {
+		testName   string
+		expression string
+	}{
+		{"Empty", "  "},
+		{"MissingOperator", "display_name prod"},
+		{"MissingValue", "display_name ="},
+		{"UnbalancedParentheses", "(type = oci_core_vcn"},
+		{"UnexpectedParenthesis", "type = oci_core_vcn)"},
+		{"DanglingAnd", "type = oci_core_vcn AND"},
+		{"UnterminatedString", "display_name = \"prod"},
+		{"InvalidRegex", "display_name ~ \"(\""},
+		{"InvalidComparison", "time_created > yesterday"},
+		{"SingleAmpersand", "type = oci_core_vcn & state = AVAILABLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			if _, err := ParseFilterExpression(tt.expression); err == nil {
+				t.Errorf("expected error parsing %s", tt.expression)
+			}
+		})
+	}
+}
+
+func TestUnitTokenizeFilterExpressionString(t *testing.T) {
+	tests := []struct {
+		testName   string
+		expression string
+		value      string
+	}{
+		{"Plain", `"prod-vcn"`, `prod-vcn`},
+		{"EscapedQuote", `"prod\"vcn"`, `prod"vcn`},
+		{"EscapedBackslash", `"prod\\vcn"`, `prod\vcn`},
+		{"RegexEscapes", `"^prod-\d+\.vcn$"`, `^prod-\d+\.vcn$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			tokens, err := tokenizeFilterExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tokens) != 1 || tokens[0].kind != filterTokenString || tokens[0].value != tt.value {
+				t.Errorf("expected string token %s, got %v", tt.value, tokens)
+			}
+		})
+	}
+}
+
+func TestUnitExpressionFilterDeepCopy(t *testing.T) {
+	filter, err := ParseFilterExpression("type = oci_core_vcn")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	filtersCopy, err := GetFiltersDeepCopy([]ResourceFilter{filter})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expressionCopy, ok := filtersCopy[0].(*ExpressionFilter)
+	if !ok || expressionCopy == filter || expressionCopy.Expression != filter.Expression {
+		t.Errorf("got %+v, want a copy of %+v", filtersCopy[0], filter)
+	}
+	if !expressionCopy.Filter(&OCIResource{TerraformResource: TerraformResource{TerraformClass: "oci_core_vcn"}}) {
+		t.Errorf("copied filter should match the resource type")
+	}
+}
@@ -57,6 +57,7 @@ func (f *Filter) Set(value string) error {
 // if one type, one attribute and array values is passed, it will be one filter
 // AttrName will not accept a list of names
 // Type will accept one value when used with AttrName and values
+// Any other filter is parsed as a boolean filter expression, see ParseFilterExpression
 func (f *Filter) ParseFilter(rawFilter string) (ResourceFilter, error) {
 	if resourceTypeFilterPattern.MatchString(rawFilter) {
 		// matches resource type filter
@@ -102,7 +103,13 @@ func (f *Filter) ParseFilter(rawFilter string) (ResourceFilter, error) {
 			Values:               fieldValues,
 		}, nil
 	}
-	return nil, fmt.Errorf("invalid value %s provided. Unable to parse filter. Please refer to filter documentation", rawFilter)
+
+	// any other filter is parsed as a boolean filter expression e.g. type = oci_core_vcn AND display_name ~ "^prod-"
+	expressionFilter, err := ParseFilterExpression(rawFilter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s provided. Unable to parse filter: %s. Please refer to filter documentation", rawFilter, err.Error())
+	}
+	return expressionFilter, nil
 }
 
 // convert a delimited string of values into a slice
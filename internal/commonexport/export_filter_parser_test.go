@@ -114,6 +114,12 @@ func TestUnitParseFilter(t *testing.T) {
 			reflect.TypeOf(&FieldValueFilter{}),
 			nil,
 		},
+		{
+			"ValidExpressionFilter",
+			"type = oci_core_vcn AND (display_name ~ \"^prod-\" OR NOT lifecycle_state = TERMINATED)",
+			reflect.TypeOf(&ExpressionFilter{}),
+			nil,
+		},
 	}
 
 	for _, tt := range tests {
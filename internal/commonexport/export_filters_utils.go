@@ -150,6 +150,13 @@ func getFilterDeepCopy(filter ResourceFilter) (ResourceFilter, error) {
 			resourceFieldValueFilter.FieldPath,
 			values,
 		}, nil
+	case *ExpressionFilter:
+		expressionFilter, ok := filter.(*ExpressionFilter)
+		if !ok {
+			return nil, fmt.Errorf("unable to convert filter %+v to expression filter", filter)
+		}
+
+		return ParseFilterExpression(expressionFilter.Expression)
 	}
 	return nil, fmt.Errorf("unable to convert filter %+v", filter)
 }
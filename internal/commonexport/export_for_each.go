@@ -0,0 +1,222 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+/*
+forEachShape is the union of the attributes and nested blocks set in the instances of a resource using for_each
+Every instance is written with all the attributes of the shape, the attributes which are not set in an instance are null
+and the nested blocks which are not set are empty lists, so that the resource can refer to any of them
+*/
+type forEachShape struct {
+	names  []string                 // attributes and nested blocks in the order they are written
+	blocks map[string]*forEachShape // shape of the nested blocks
+}
+
+func newForEachShape(resourceSchema *schema.Resource, items []map[string]interface{}) *forEachShape {
+	shape := &forEachShape{blocks: map[string]*forEachShape{}}
+	for _, attribute := range getSortedSchemaKeys(resourceSchema.Schema) {
+		tfSchema := resourceSchema.Schema[attribute]
+		if tfSchema.Deprecated != "" || (!tfSchema.Required && !tfSchema.Optional) {
+			continue
+		}
+
+		elem, isBlock := tfSchema.Elem.(*schema.Resource)
+		isBlock = isBlock && (tfSchema.Type == schema.TypeList || tfSchema.Type == schema.TypeSet)
+		var nestedItems []map[string]interface{}
+		found := false
+		for _, item := range items {
+			value, exists := item[attribute]
+			if !exists || value == nil {
+				continue
+			}
+			found = true
+			if isBlock {
+				nestedItems = append(nestedItems, getForEachBlockItems(value)...)
+			}
+		}
+		if !found {
+			continue
+		}
+
+		shape.names = append(shape.names, attribute)
+		if isBlock {
+			shape.blocks[attribute] = newForEachShape(elem, nestedItems)
+		}
+	}
+	return shape
+}
+
+func getForEachBlockItems(value interface{}) []map[string]interface{} {
+	var result []map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result = append(result, v)
+	case []interface{}:
+		for _, item := range v {
+			if itemMap, ok := item.(map[string]interface{}); ok && itemMap != nil {
+				result = append(result, itemMap)
+			}
+		}
+	}
+	return result
+}
+
+/*
+GetForEachHclString writes the resources grouped in ociRes.ForEachResources as a single resource using for_each
+The attributes of the resources are written to a local map keyed by their Terraform name, e.g. for a group named nsg1_security_rules:
+
+	resource oci_core_network_security_group_security_rule nsg1_security_rules {
+	  for_each  = local.nsg1_security_rules
+	  direction = each.value.direction
+	  dynamic "tcp_options" {
+	    for_each = each.value.tcp_options
+	    content {
+	      ...
+	    }
+	  }
+	}
+
+	locals {
+	  nsg1_security_rules = {
+	    "export_rule1" = { direction = "INGRESS", tcp_options = [...] }
+	  }
+	}
+*/
+func GetForEachHclString(builder *strings.Builder, ociRes *OCIResource, interpolationMap map[string]string) error {
+	resourceSchema, exists := ResourcesMap[ociRes.TerraformClass]
+	if !exists {
+		return fmt.Errorf("[ERROR] no resource schema found for '%s'", ociRes.TerraformClass)
+	}
+
+	items := make([]map[string]interface{}, len(ociRes.ForEachResources))
+	for i, resource := range ociRes.ForEachResources {
+		items[i] = resource.SourceAttributes
+	}
+	shape := newForEachShape(resourceSchema, items)
+
+	builder.WriteString(fmt.Sprintf("resource %s %s {\n", ociRes.TerraformClass, ociRes.TerraformName))
+	builder.WriteString(fmt.Sprintf("for_each = %s\n", TfHclVersionvar.GetSingleExpHclString(fmt.Sprintf("local.%s", ociRes.TerraformName))))
+	writeForEachArguments(builder, shape, "each.value")
+	builder.WriteString("}\n\n")
+
+	builder.WriteString(fmt.Sprintf("locals {\n%s = {\n", ociRes.TerraformName))
+	for _, resource := range ociRes.ForEachResources {
+		builder.WriteString(fmt.Sprintf("%q = ", resource.TerraformName))
+		if err := writeForEachObject(builder, resource.SourceAttributes, resourceSchema, shape, interpolationMap); err != nil {
+			return fmt.Errorf("[ERROR] unable to write '%s' of '%s': %s", resource.TerraformName, ociRes.GetTerraformReference(), err.Error())
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("}\n}\n\n")
+	return nil
+}
+
+// writeForEachArguments writes the arguments of the resource or nested block referring to the attributes of the instance
+func writeForEachArguments(builder *strings.Builder, shape *forEachShape, prefix string) {
+	for _, name := range shape.names {
+		nestedShape, isBlock := shape.blocks[name]
+		if !isBlock {
+			builder.WriteString(fmt.Sprintf("%s = %s\n", name, TfHclVersionvar.GetDoubleExpHclString(prefix, name)))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("dynamic %q {\n", name))
+		builder.WriteString(fmt.Sprintf("for_each = %s\n", TfHclVersionvar.GetDoubleExpHclString(prefix, name)))
+		builder.WriteString("content {\n")
+		writeForEachArguments(builder, nestedShape, fmt.Sprintf("%s.value", name))
+		builder.WriteString("}\n}\n")
+	}
+}
+
+// writeForEachObject writes the attributes of an instance as an object with all the attributes of the shape
+func writeForEachObject(builder *strings.Builder, sourceAttributes map[string]interface{}, resourceSchema *schema.Resource, shape *forEachShape, interpolationMap map[string]string) error {
+	builder.WriteString("{\n")
+	for _, name := range shape.names {
+		value := sourceAttributes[name]
+		if nestedShape, isBlock := shape.blocks[name]; isBlock {
+			items := getForEachBlockItems(value)
+			if len(items) == 0 {
+				builder.WriteString(fmt.Sprintf("%s = []\n", name))
+				continue
+			}
+			builder.WriteString(fmt.Sprintf("%s = [\n", name))
+			for _, item := range items {
+				if err := writeForEachObject(builder, item, resourceSchema.Schema[name].Elem.(*schema.Resource), nestedShape, interpolationMap); err != nil {
+					return err
+				}
+				builder.WriteString(",\n")
+			}
+			builder.WriteString("]\n")
+			continue
+		}
+
+		hclValue, err := getForEachHclValue(value, interpolationMap)
+		if err != nil {
+			return fmt.Errorf("attribute '%s': %s", name, err.Error())
+		}
+		builder.WriteString(fmt.Sprintf("%s = %s\n", name, hclValue))
+	}
+	builder.WriteString("}")
+	return nil
+}
+
+func getForEachHclValue(value interface{}, interpolationMap map[string]string) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case InterpolationString:
+		if ok := FailedResourceReferenceSet[v.ResourceReference]; ok {
+			return fmt.Sprintf("%q", v.Value), nil
+		}
+		return v.Interpolation, nil
+	case string:
+		if varOverride, exists := interpolationMap[v]; exists {
+			return varOverride, nil
+		}
+		return fmt.Sprintf("%q", escapeTFStrings(v)), nil
+	case int, bool, float64:
+		return fmt.Sprintf("\"%v\"", v), nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			hclValue, err := getForEachHclValue(item, interpolationMap)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, hclValue)
+		}
+		return fmt.Sprintf("[%s]", strings.Join(values, ", ")), nil
+	case map[string]interface{}:
+		builder := &strings.Builder{}
+		builder.WriteString("{\n")
+		for _, key := range utils.GetSortedKeys(v) {
+			hclValue, err := getForEachHclValue(v[key], interpolationMap)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(fmt.Sprintf("%q = %s\n", key, hclValue))
+		}
+		builder.WriteString("}")
+		return builder.String(), nil
+	}
+	return "", fmt.Errorf("unsupported value type %T", value)
+}
+
+func getSortedSchemaKeys(source map[string]*schema.Schema) []string {
+	keys := make([]string, 0, len(source))
+	for key := range source {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const forEachTestConfig = `resource oci_core_network_security_group_security_rule nsg1_rule {
+  for_each                  = local.nsg1_rule
+  description               = each.value.description
+  direction                 = each.value.direction
+  network_security_group_id = each.value.network_security_group_id
+  dynamic "tcp_options" {
+    for_each = each.value.tcp_options
+    content {
+      dynamic "destination_port_range" {
+        for_each = tcp_options.value.destination_port_range
+        content {
+          max = destination_port_range.value.max
+          min = destination_port_range.value.min
+        }
+      }
+    }
+  }
+}
+
+locals {
+  nsg1_rule = {
+    "rule1" = {
+      description               = null
+      direction                 = "INGRESS"
+      network_security_group_id = oci_core_network_security_group.nsg1.id
+      tcp_options = [
+        {
+          destination_port_range = [
+            {
+              max = "443"
+              min = "443"
+            },
+          ]
+        },
+      ]
+    }
+    "rule2" = {
+      description               = "allow $${var}"
+      direction                 = "EGRESS"
+      network_security_group_id = oci_core_network_security_group.nsg1.id
+      tcp_options               = []
+    }
+  }
+}
+
+`
+
+func TestUnitGetForEachHclString(t *testing.T) {
+	defer func(tfVersion TfHclVersion, resourcesMap map[string]*schema.Resource) {
+		TfHclVersionvar = tfVersion
+		ResourcesMap = resourcesMap
+	}(TfHclVersionvar, ResourcesMap)
+	TfHclVersionvar = &TfHclVersion12{Value: TfVersion12}
+
+	portRange := &schema.Resource{Schema: map[string]*schema.Schema{
+		"max": {Type: schema.TypeInt, Required: true},
+		"min": {Type: schema.TypeInt, Required: true},
+	}}
+	ResourcesMap = map[string]*schema.Resource{
+		"oci_core_network_security_group_security_rule": {Schema: map[string]*schema.Schema{
+			"description":               {Type: schema.TypeString, Optional: true},
+			"direction":                 {Type: schema.TypeString, Required: true},
+			"id":                        {Type: schema.TypeString, Computed: true},
+			"network_security_group_id": {Type: schema.TypeString, Required: true},
+			"source":                    {Type: schema.TypeString, Optional: true},
+			"tcp_options": {Type: schema.TypeList, Optional: true, MaxItems: 1, Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"destination_port_range": {Type: schema.TypeList, Optional: true, MaxItems: 1, Elem: portRange},
+			}}},
+		}},
+	}
+
+	newRule := func(name string, attributes map[string]interface{}) *OCIResource {
+		attributes["network_security_group_id"] = "ocid1.networksecuritygroup.1"
+		attributes["id"] = name
+		return &OCIResource{
+			TerraformResource: TerraformResource{TerraformClass: "oci_core_network_security_group_security_rule", TerraformName: name},
+			SourceAttributes:  attributes,
+		}
+	}
+	group := &OCIResource{
+		TerraformResource: TerraformResource{TerraformClass: "oci_core_network_security_group_security_rule", TerraformName: "nsg1_rule"},
+		ForEachResources: []*OCIResource{
+			newRule("rule1", map[string]interface{}{
+				"direction":   "INGRESS",
+				"tcp_options": []interface{}{map[string]interface{}{"destination_port_range": []interface{}{map[string]interface{}{"max": 443, "min": 443}}}},
+			}),
+			newRule("rule2", map[string]interface{}{"direction": "EGRESS", "description": "allow ${var}", "source": nil}),
+		},
+	}
+
+	builder := &strings.Builder{}
+	if err := GetForEachHclString(builder, group, map[string]string{"ocid1.networksecuritygroup.1": "oci_core_network_security_group.nsg1.id"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	config, err := FormatConfiguration([]byte(builder.String()))
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if string(config) != forEachTestConfig {
+		t.Errorf("expected configuration:\n%s\nbut got:\n%s", forEachTestConfig, string(config))
+	}
+
+	// the grouped resources can be written in JSON syntax
+	TfHclVersionvar = &TfHclVersionJson{Value: TfVersionJson}
+	builder = &strings.Builder{}
+	if err := GetForEachHclString(builder, group, map[string]string{"ocid1.networksecuritygroup.1": "\"${oci_core_network_security_group.nsg1.id}\""}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	config, err = FormatConfiguration([]byte(builder.String()))
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, expected := range []string{`"for_each": "${local.nsg1_rule}"`, `"for_each": "${tcp_options.value.destination_port_range}"`, `"description": null`} {
+		if !strings.Contains(string(config), expected) {
+			t.Errorf("expected %s in JSON configuration:\n%s", expected, string(config))
+		}
+	}
+}
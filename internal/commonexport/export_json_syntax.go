@@ -0,0 +1,190 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Blocks and attributes whose references are written as plain strings instead of "${...}" templates in JSON syntax
+var jsonBareReferenceBlocks = map[string]bool{"lifecycle": true, "import": true}
+var jsonBareReferenceAttributes = map[string]bool{"depends_on": true, "provider": true}
+
+// IsJsonSyntax returns true if the configurations are generated in Terraform JSON syntax (.tf.json)
+func IsJsonSyntax() bool {
+	_, isJson := TfHclVersionvar.(*TfHclVersionJson)
+	return isJson
+}
+
+// GetConfigFileName returns the configuration file name for the syntax being generated e.g. core.tf or core.tf.json
+func GetConfigFileName(name string) string {
+	if IsJsonSyntax() {
+		return fmt.Sprintf("%s.json", name)
+	}
+	return name
+}
+
+// FormatConfiguration formats the generated configuration for the syntax being generated
+// For JSON syntax the generated HCL is converted to its JSON representation
+func FormatConfiguration(config []byte) ([]byte, error) {
+	if IsJsonSyntax() {
+		return ConvertHclToJson(config)
+	}
+	return hclwrite.Format(config), nil
+}
+
+/*
+ConvertHclToJson converts native HCL configuration to Terraform JSON configuration syntax
+The HCL is expected to be generated with TfHclVersionJson so that all the interpolations are
+already written as "${...}" templates, which have the same meaning in JSON syntax
+*/
+func ConvertHclToJson(config []byte) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(config, "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("[ERROR] unable to parse generated configuration: %s", diags.Error())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("[ERROR] unexpected body type %T in generated configuration", file.Body)
+	}
+
+	result := map[string]interface{}{}
+	for _, block := range body.Blocks {
+		blockJson, err := convertHclBodyToJson(block.Body, config, jsonBareReferenceBlocks[block.Type])
+		if err != nil {
+			return nil, err
+		}
+		addJsonBlock(result, block.Type, block.Labels, blockJson)
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// addJsonBlock nests the block under its type and labels, blocks without labels or repeated blocks become a list
+func addJsonBlock(parent map[string]interface{}, blockType string, labels []string, blockJson map[string]interface{}) {
+	if len(labels) == 0 {
+		existing, _ := parent[blockType].([]interface{})
+		parent[blockType] = append(existing, blockJson)
+		return
+	}
+
+	child, exists := parent[blockType].(map[string]interface{})
+	if !exists {
+		child = map[string]interface{}{}
+		parent[blockType] = child
+	}
+
+	if len(labels) > 1 {
+		addJsonBlock(child, labels[0], labels[1:], blockJson)
+		return
+	}
+
+	switch existing := child[labels[0]].(type) {
+	case nil:
+		child[labels[0]] = blockJson
+	case []interface{}:
+		child[labels[0]] = append(existing, blockJson)
+	default:
+		child[labels[0]] = []interface{}{existing, blockJson}
+	}
+}
+
+func convertHclBodyToJson(body *hclsyntax.Body, config []byte, bareReferences bool) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for name, attribute := range body.Attributes {
+		value, err := convertHclExpressionToJson(attribute.Expr, config, bareReferences || jsonBareReferenceAttributes[name])
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] unable to convert attribute '%s' to JSON: %s", name, err.Error())
+		}
+		result[name] = value
+	}
+
+	for _, block := range body.Blocks {
+		blockJson, err := convertHclBodyToJson(block.Body, config, bareReferences || jsonBareReferenceBlocks[block.Type])
+		if err != nil {
+			return nil, err
+		}
+		addJsonBlock(result, block.Type, block.Labels, blockJson)
+	}
+	return result, nil
+}
+
+func convertHclExpressionToJson(expr hclsyntax.Expression, config []byte, bareReferences bool) (interface{}, error) {
+	source := string(expr.Range().SliceBytes(config))
+
+	switch v := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		return convertCtyValueToJson(v.Val)
+	case *hclsyntax.TemplateExpr, *hclsyntax.TemplateWrapExpr:
+		if strings.HasPrefix(source, "\"") {
+			// Generated strings are quoted with %q so they can be unquoted the same way
+			// Escaped template sequences like $${ are kept as is, since they have the same meaning in JSON strings
+			return strconv.Unquote(source)
+		}
+	case *hclsyntax.TupleConsExpr:
+		result := make([]interface{}, 0, len(v.Exprs))
+		for _, item := range v.Exprs {
+			value, err := convertHclExpressionToJson(item, config, bareReferences)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	case *hclsyntax.ObjectConsExpr:
+		result := map[string]interface{}{}
+		for _, item := range v.Items {
+			key, err := convertHclExpressionToJson(item.KeyExpr, config, true)
+			if err != nil {
+				return nil, err
+			}
+			value, err := convertHclExpressionToJson(item.ValueExpr, config, bareReferences)
+			if err != nil {
+				return nil, err
+			}
+			result[fmt.Sprintf("%v", key)] = value
+		}
+		return result, nil
+	case *hclsyntax.ObjectConsKeyExpr:
+		return convertHclExpressionToJson(v.Wrapped, config, true)
+	}
+
+	if bareReferences {
+		return source, nil
+	}
+	return fmt.Sprintf("${%s}", source), nil
+}
+
+func convertCtyValueToJson(value cty.Value) (interface{}, error) {
+	if value.IsNull() {
+		return nil, nil
+	}
+
+	switch value.Type() {
+	case cty.Bool:
+		return value.True(), nil
+	case cty.Number:
+		return json.Number(value.AsBigFloat().Text('f', -1)), nil
+	case cty.String:
+		return value.AsString(), nil
+	}
+	return nil, fmt.Errorf("unsupported literal type %s", value.Type().FriendlyName())
+}
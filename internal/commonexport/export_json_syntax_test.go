@@ -0,0 +1,123 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnitConvertHclToJson(t *testing.T) {
+	config := `## This configuration was generated by terraform-provider-oci
+
+resource oci_core_vcn export_vcn1 {
+cidr_blocks = [
+"10.0.0.0/16",
+]
+compartment_id = "${var.compartment_ocid}"
+display_name = "vcn $${escaped} \"quoted\""
+freeform_tags = {
+"Department" = "Finance"
+}
+is_ipv6enabled = "false"
+#byoipv6cidr_details = <<Optional value not found in discovery>>
+}
+
+resource oci_core_subnet export_subnet1 {
+vcn_id = "${oci_core_vcn.export_vcn1.id}"
+admin_password = "<placeholder for missing required attribute>"	#Required attribute not found in discovery, placeholder value set to avoid plan failure
+route_rules {
+network_entity_id = "${oci_core_internet_gateway.export_igw.id}"
+}
+route_rules {
+destination = "0.0.0.0/0"
+}
+lifecycle {
+ignore_changes = ["admin_password",]
+}
+}
+
+data oci_identity_availability_domain export_ad1 {
+ad_number = "1"
+}
+
+provider oci {
+region = "${var.region}"
+}
+
+import {
+to = oci_core_vcn.export_vcn1
+id = "ocid1.vcn.oc1..a"
+}
+`
+	expected := map[string]interface{}{
+		"resource": map[string]interface{}{
+			"oci_core_vcn": map[string]interface{}{
+				"export_vcn1": map[string]interface{}{
+					"cidr_blocks":    []interface{}{"10.0.0.0/16"},
+					"compartment_id": "${var.compartment_ocid}",
+					"display_name":   "vcn $${escaped} \"quoted\"",
+					"freeform_tags":  map[string]interface{}{"Department": "Finance"},
+					"is_ipv6enabled": "false",
+				},
+			},
+			"oci_core_subnet": map[string]interface{}{
+				"export_subnet1": map[string]interface{}{
+					"vcn_id":         "${oci_core_vcn.export_vcn1.id}",
+					"admin_password": "<placeholder for missing required attribute>",
+					"route_rules": []interface{}{
+						map[string]interface{}{"network_entity_id": "${oci_core_internet_gateway.export_igw.id}"},
+						map[string]interface{}{"destination": "0.0.0.0/0"},
+					},
+					"lifecycle": []interface{}{
+						map[string]interface{}{"ignore_changes": []interface{}{"admin_password"}},
+					},
+				},
+			},
+		},
+		"data": map[string]interface{}{
+			"oci_identity_availability_domain": map[string]interface{}{
+				"export_ad1": map[string]interface{}{"ad_number": "1"},
+			},
+		},
+		"provider": map[string]interface{}{
+			"oci": map[string]interface{}{"region": "${var.region}"},
+		},
+		"import": []interface{}{
+			map[string]interface{}{"to": "oci_core_vcn.export_vcn1", "id": "ocid1.vcn.oc1..a"},
+		},
+	}
+
+	result, err := ConvertHclToJson([]byte(config))
+	if err != nil {
+		t.Fatalf("ConvertHclToJson() error = %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(result, &got); err != nil {
+		t.Fatalf("ConvertHclToJson() returned invalid JSON: %v\n%s", err, result)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ConvertHclToJson() got %s", result)
+	}
+
+	if _, err := ConvertHclToJson([]byte("resource oci_core_vcn {")); err == nil {
+		t.Errorf("ConvertHclToJson() expected error for invalid configuration")
+	}
+}
+
+func TestUnitGetConfigFileName(t *testing.T) {
+	defer func(tfVersion TfHclVersion) { TfHclVersionvar = tfVersion }(TfHclVersionvar)
+
+	TfHclVersionvar = &TfHclVersion12{Value: TfVersion12}
+	if got := GetConfigFileName("core.tf"); got != "core.tf" {
+		t.Errorf("GetConfigFileName() = %v, want core.tf", got)
+	}
+
+	TfHclVersionvar = &TfHclVersionJson{Value: TfVersionJson}
+	if got := GetConfigFileName("core.tf"); got != "core.tf.json" {
+		t.Errorf("GetConfigFileName() = %v, want core.tf.json", got)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Prefixes of the naming_strategy argument
+const (
+	NamingStrategyDefault  = "default"
+	NamingStrategyHash     = "hash"
+	NamingStrategyTag      = "tag:"
+	NamingStrategyTemplate = "template:"
+
+	// number of hex characters of the OCID hash used in the names
+	namingStrategyHashLength = 10
+)
+
+/*
+ResourceNamingStrategy generates the Terraform names of the exported resources
+A strategy that cannot name a resource e.g. the resource does not have the tag, returns false and the resource is named from its display name
+*/
+type ResourceNamingStrategy interface {
+	GetTerraformName(resource *OCIResource) (string, bool)
+}
+
+// ResourceNamingStrategyVar is the strategy set by the naming_strategy argument, the resources are named from their display name if it is nil
+var ResourceNamingStrategyVar ResourceNamingStrategy
+
+var invalidTerraformNameRegex = regexp.MustCompile(`[^a-zA-Z0-9\-\_]+`)
+var validTerraformNameStartRegex = regexp.MustCompile(`^[a-zA-Z_]`)
+
+// tagNamingStrategy names the resources from the value of a freeform tag or a defined tag e.g. `tf-name` or `Operations.tf-name`
+type tagNamingStrategy struct {
+	tagKey string
+}
+
+func (strategy *tagNamingStrategy) GetTerraformName(resource *OCIResource) (string, bool) {
+	for _, tagsAttribute := range []string{"freeform_tags", "defined_tags"} {
+		tags, ok := resource.SourceAttributes[tagsAttribute].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, ok := tags[strategy.tagKey].(string); ok && strings.TrimSpace(value) != "" {
+			return getValidTerraformName(value), true
+		}
+	}
+	return "", false
+}
+
+// templateNamingStrategy names the resources by executing a Go template with their attributes e.g. `{{.display_name}}_{{.availability_domain | short}}`
+type templateNamingStrategy struct {
+	template *template.Template
+}
+
+func (strategy *templateNamingStrategy) GetTerraformName(resource *OCIResource) (string, bool) {
+	builder := &strings.Builder{}
+	if err := strategy.template.Execute(builder, resource.SourceAttributes); err != nil {
+		return "", false
+	}
+	name := strings.TrimSpace(builder.String())
+	if name == "" {
+		return "", false
+	}
+	return getValidTerraformName(name), true
+}
+
+// hashNamingStrategy names the resources from their abbreviation and a hash of their OCID, which does not change between exports
+type hashNamingStrategy struct{}
+
+func (strategy *hashNamingStrategy) GetTerraformName(resource *OCIResource) (string, bool) {
+	if resource.Id == "" {
+		return "", false
+	}
+	abbreviation := strings.TrimPrefix(resource.TerraformClass, "oci_")
+	if resource.TerraformTypeInfo != nil && resource.TerraformTypeInfo.ResourceAbbreviation != "" {
+		abbreviation = resource.TerraformTypeInfo.ResourceAbbreviation
+	}
+	hash := sha256.Sum256([]byte(resource.Id))
+	return getValidTerraformName(fmt.Sprintf("%s_%s", abbreviation, hex.EncodeToString(hash[:])[:namingStrategyHashLength])), true
+}
+
+/*
+ParseResourceNamingStrategy parses the naming_strategy argument
+- `default` or an empty value names the resources from their display name
+- `hash` names the resources from a hash of their OCID
+- `tag:<tag key>` names the resources from the value of the freeform or defined tag, a defined tag key includes its namespace
+- `template:<Go template>` names the resources from a template of their attributes, the `short` and `lower` functions can be used in the template
+*/
+func ParseResourceNamingStrategy(value string) (ResourceNamingStrategy, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "" || value == NamingStrategyDefault:
+		return nil, nil
+	case value == NamingStrategyHash:
+		return &hashNamingStrategy{}, nil
+	case strings.HasPrefix(value, NamingStrategyTag):
+		tagKey := strings.TrimSpace(strings.TrimPrefix(value, NamingStrategyTag))
+		if tagKey == "" {
+			return nil, fmt.Errorf("[ERROR] no tag key specified in naming_strategy %s", value)
+		}
+		return &tagNamingStrategy{tagKey: tagKey}, nil
+	case strings.HasPrefix(value, NamingStrategyTemplate):
+		nameTemplate, err := template.New("naming_strategy").
+			Funcs(template.FuncMap{"short": shortNameValue, "lower": lowerNameValue}).
+			Option("missingkey=error").
+			Parse(strings.TrimPrefix(value, NamingStrategyTemplate))
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] invalid template in naming_strategy %s: %s", value, err.Error())
+		}
+		return &templateNamingStrategy{template: nameTemplate}, nil
+	}
+	return nil, fmt.Errorf("[ERROR] invalid value %s for naming_strategy, supported values: %s, %s, %s<tag key>, %s<Go template>", value, NamingStrategyDefault, NamingStrategyHash, NamingStrategyTag, NamingStrategyTemplate)
+}
+
+/*
+GenerateTerraformNameForResource generates the Terraform name of the resource using the naming strategy
+The name is generated from the display name of the resource using GenerateTerraformNameFromResource if no strategy is set or the strategy cannot name the resource
+*/
+func GenerateTerraformNameForResource(resource *OCIResource, resourceSchema map[string]*schema.Schema) (string, error) {
+	if ResourceNamingStrategyVar != nil {
+		if terraformName, ok := ResourceNamingStrategyVar.GetTerraformName(resource); ok {
+			return CheckDuplicateResourceName(terraformName), nil
+		}
+	}
+	return GenerateTerraformNameFromResource(resource.SourceAttributes, resourceSchema)
+}
+
+// getValidTerraformName replaces the characters which are not allowed in a Terraform name, the name is prefixed if it does not start with a letter or underscore
+func getValidTerraformName(source string) string {
+	result := invalidTerraformNameRegex.ReplaceAllString(source, "-")
+	if !validTerraformNameStartRegex.MatchString(result) {
+		result = fmt.Sprintf("export_%s", result)
+	}
+	return result
+}
+
+// shortNameValue shortens a value for the template naming strategy, e.g. `PHX-AD-1` for `Uocm:PHX-AD-1` and the last 6 characters of an OCID
+func shortNameValue(value interface{}) string {
+	result := fmt.Sprintf("%v", value)
+	if strings.HasPrefix(result, "ocid1.") {
+		if len(result) > 6 {
+			return result[len(result)-6:]
+		}
+		return result
+	}
+	if index := strings.LastIndex(result, ":"); index >= 0 {
+		return result[index+1:]
+	}
+	return result
+}
+
+func lowerNameValue(value interface{}) string {
+	return strings.ToLower(fmt.Sprintf("%v", value))
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestUnitGenerateTerraformNameForResource(t *testing.T) {
+	defer func(strategy ResourceNamingStrategy, resourceNameCount map[string]int) {
+		ResourceNamingStrategyVar = strategy
+		ResourceNameCount = resourceNameCount
+	}(ResourceNamingStrategyVar, ResourceNameCount)
+
+	resourceSchema := map[string]*schema.Schema{"display_name": {Type: schema.TypeString}}
+	newInstance := func() *OCIResource {
+		return &OCIResource{
+			TerraformResource: TerraformResource{
+				Id:                "ocid1.instance.oc1.phx.abcdefghij123456",
+				TerraformClass:    "oci_core_instance",
+				TerraformTypeInfo: &TerraformResourceHints{ResourceClass: "oci_core_instance", ResourceAbbreviation: "instance"},
+			},
+			SourceAttributes: map[string]interface{}{
+				"display_name":        "instance20230301",
+				"availability_domain": "Uocm:PHX-AD-1",
+				"subnet_id":           "ocid1.subnet.oc1.phx.subnet654321",
+				"freeform_tags":       map[string]interface{}{"tf-name": "web server"},
+				"defined_tags":        map[string]interface{}{"Operations.tf-name": "1st-web"},
+			},
+		}
+	}
+
+	tests := []struct {
+		testName string
+		strategy string
+		expected []string // names of two resources with the same attributes
+	}{
+		{"Default", "", []string{"export_instance20230301", "export_instance20230301_1"}},
+		{"DefaultValue", "default", []string{"export_instance20230301", "export_instance20230301_1"}},
+		{"FreeformTag", "tag:tf-name", []string{"web-server", "web-server_1"}},
+		{"DefinedTag", "tag:Operations.tf-name", []string{"export_1st-web", "export_1st-web_1"}},
+		{"MissingTag", "tag:Other.tf-name", []string{"export_instance20230301", "export_instance20230301_1"}},
+		{"Template", "template:{{.display_name}}_{{.availability_domain | short}}", []string{"instance20230301_PHX-AD-1", "instance20230301_PHX-AD-1_1"}},
+		{"TemplateOcid", "template:{{.display_name | lower}}_{{.subnet_id | short}}", []string{"instance20230301_654321", "instance20230301_654321_1"}},
+		{"TemplateMissingAttribute", "template:{{.hostname_label}}", []string{"export_instance20230301", "export_instance20230301_1"}},
+		{"Hash", "hash", []string{"instance_5a4e1bdfb5", "instance_5a4e1bdfb5_1"}},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running test: %s", test.testName)
+		strategy, err := ParseResourceNamingStrategy(test.strategy)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.testName, err)
+			continue
+		}
+		ResourceNamingStrategyVar = strategy
+		ResourceNameCount = map[string]int{}
+
+		for _, expected := range test.expected {
+			name, err := GenerateTerraformNameForResource(newInstance(), resourceSchema)
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.testName, err)
+			}
+			if name != expected {
+				t.Errorf("%s: expected name %s but got %s", test.testName, expected, name)
+			}
+		}
+	}
+}
+
+func TestUnitParseResourceNamingStrategyErrors(t *testing.T) {
+	for _, strategy := range []string{"tag:", "template:{{.display_name", "display_name", "hash:sha1"} {
+		if _, err := ParseResourceNamingStrategy(strategy); err == nil {
+			t.Errorf("expected an error for naming strategy %s", strategy)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+// SchemaViolation is a problem found by validating the configuration generated for a resource against the schema of the resource
+type SchemaViolation struct {
+	Attribute string // path of the attribute in the resource e.g. source_details[0].source_id
+	Message   string
+	Fixed     bool // the attribute was removed from the configuration to fix the violation
+
+	sourcePath []string // path of the attribute to remove from the source attributes to fix the violation
+}
+
+func (v *SchemaViolation) String() string {
+	if v.Fixed {
+		return fmt.Sprintf("'%s' %s, removed from the configuration", v.Attribute, v.Message)
+	}
+	return fmt.Sprintf("'%s' %s", v.Attribute, v.Message)
+}
+
+/*
+getValidatedHCLStringFromMap writes the configuration of the resource after validating it against the resource schema
+- the blocks are checked for Required, ConflictsWith, ExactlyOneOf, ValidateFunc, MaxItems and Deprecated
+- the violations fixed unambiguously by removing an attribute, like a deprecated attribute, are fixed by writing the configuration again without the attribute
+- the other violations are kept in the SchemaViolations of the resource to be listed in the summary
+*/
+func getValidatedHCLStringFromMap(builder *strings.Builder, ociRes *OCIResource, resourceSchema *schema.Resource, interpolationMap map[string]string) error {
+	resourceBuilder := &strings.Builder{}
+	if err := GetHCLStringFromMap(resourceBuilder, ociRes.SourceAttributes, resourceSchema, interpolationMap, ociRes, ""); err != nil {
+		return err
+	}
+
+	violations := validateConfiguration(resourceBuilder.String(), resourceSchema, ociRes.GetTerraformReference())
+	sourceAttributes := ociRes.SourceAttributes
+	isFixed := false
+	for _, violation := range violations {
+		if violation.Fixed {
+			sourceAttributes = removeSourceAttribute(sourceAttributes, violation.sourcePath).(map[string]interface{})
+			isFixed = true
+		}
+	}
+	if len(violations) > 0 {
+		utils.Logf("[WARN] configuration of '%s' does not match the resource schema: %d violations", ociRes.GetTerraformReference(), len(violations))
+	}
+	ociRes.SchemaViolations = violations
+
+	if isFixed {
+		resourceBuilder.Reset()
+		if err := GetHCLStringFromMap(resourceBuilder, sourceAttributes, resourceSchema, interpolationMap, ociRes, ""); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(resourceBuilder.String())
+	return nil
+}
+
+// validateConfiguration parses the attributes and blocks written for a resource and validates them against the resource schema
+func validateConfiguration(config string, resourceSchema *schema.Resource, reference string) []*SchemaViolation {
+	file, diags := hclsyntax.ParseConfig([]byte(config), reference, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		utils.Debugf("[DEBUG] unable to parse the configuration of '%s' for validation: %s", reference, diags.Error())
+		return nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	validation := &blockValidation{body: body, resourceSchema: resourceSchema, removed: map[string]bool{}}
+	validation.validate()
+	return validation.violations
+}
+
+// blockValidation validates a block of the configuration against the schema of the block
+type blockValidation struct {
+	body            *hclsyntax.Body
+	resourceSchema  *schema.Resource
+	attributePrefix string   // path of the block in the configuration e.g. source_details[0]
+	schemaPrefix    string   // path of the block in the schema keys used by ConflictsWith and ExactlyOneOf e.g. source_details.0
+	sourcePath      []string // path of the block in the source attributes
+	removed         map[string]bool
+	violations      []*SchemaViolation
+}
+
+func (b *blockValidation) validate() {
+	names := make([]string, 0, len(b.resourceSchema.Schema))
+	for name := range b.resourceSchema.Schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.validateAttribute(name, b.resourceSchema.Schema[name])
+	}
+	for _, name := range names {
+		b.validateConflicts(name, b.resourceSchema.Schema[name])
+	}
+	checkedGroups := map[string]bool{}
+	for _, name := range names {
+		b.validateExactlyOneOf(name, b.resourceSchema.Schema[name], checkedGroups)
+	}
+}
+
+func (b *blockValidation) validateAttribute(name string, tfSchema *schema.Schema) {
+	attribute, isAttribute := b.body.Attributes[name]
+	blocks := b.getBlocks(name)
+	_, isNestedBlock := tfSchema.Elem.(*schema.Resource)
+
+	if isAttribute && isPlaceholderValue(attribute.Expr) {
+		// the missing required attributes are listed by the summary, but a placeholder is not a valid value for a block
+		if isNestedBlock {
+			b.report(name, "is a required block that was not found in discovery, a placeholder value was written instead of the block")
+		}
+		return
+	}
+	if !isAttribute && len(blocks) == 0 {
+		if tfSchema.Required {
+			b.report(name, "is required but was not found in discovery")
+		}
+		return
+	}
+
+	if tfSchema.Deprecated != "" {
+		if tfSchema.Required {
+			b.report(name, fmt.Sprintf("is deprecated: %s", tfSchema.Deprecated))
+		} else {
+			b.remove(name, fmt.Sprintf("is deprecated: %s", tfSchema.Deprecated))
+		}
+		return
+	}
+
+	if tfSchema.MaxItems > 0 {
+		items := len(blocks)
+		if isAttribute {
+			if tuple, isTuple := attribute.Expr.(*hclsyntax.TupleConsExpr); isTuple {
+				items = len(tuple.Exprs)
+			}
+		}
+		if items > tfSchema.MaxItems {
+			b.report(name, fmt.Sprintf("has %d items but at most %d are allowed", items, tfSchema.MaxItems))
+		}
+	}
+
+	if isAttribute && tfSchema.ValidateFunc != nil {
+		if value, isLiteral := getLiteralValue(attribute.Expr, tfSchema.Type); isLiteral {
+			if _, errs := tfSchema.ValidateFunc(value, b.getPath(name)); len(errs) > 0 {
+				if value == "" && !tfSchema.Required {
+					b.remove(name, fmt.Sprintf("has an invalid empty value: %s", errs[0].Error()))
+				} else {
+					b.report(name, fmt.Sprintf("has an invalid value: %s", errs[0].Error()))
+				}
+			}
+		}
+	}
+
+	if nestedResource, ok := tfSchema.Elem.(*schema.Resource); ok {
+		for i, block := range blocks {
+			nested := &blockValidation{
+				body:            block.Body,
+				resourceSchema:  nestedResource,
+				attributePrefix: fmt.Sprintf("%s[%d]", b.getPath(name), i),
+				schemaPrefix:    fmt.Sprintf("%s.%d", b.getSchemaKey(name), i),
+				sourcePath:      append(b.getSourcePath(name), strconv.Itoa(i)),
+				removed:         map[string]bool{},
+			}
+			nested.validate()
+			b.violations = append(b.violations, nested.violations...)
+		}
+	}
+}
+
+// validateConflicts checks the attribute is not set with any of its ConflictsWith attributes, the conflict is fixed if one of them is set to its default value
+func (b *blockValidation) validateConflicts(name string, tfSchema *schema.Schema) {
+	if !b.isSet(name) {
+		return
+	}
+	for _, conflictKey := range tfSchema.ConflictsWith {
+		conflict, isSibling := b.getSiblingName(conflictKey)
+		if !isSibling || conflict == name || !b.isSet(conflict) {
+			continue
+		}
+
+		message := fmt.Sprintf("conflicts with '%s'", b.getPath(conflict))
+		if b.isDefaultValue(conflict) {
+			b.remove(conflict, fmt.Sprintf("is set to its default value and conflicts with '%s'", b.getPath(name)))
+		} else if b.isDefaultValue(name) {
+			b.remove(name, fmt.Sprintf("is set to its default value and %s", message))
+			return
+		} else if name < conflict || !b.hasConflict(conflict, name) {
+			// the conflicts are usually declared on both attributes, the pair is reported once
+			b.report(name, message)
+		}
+	}
+}
+
+// validateExactlyOneOf checks exactly one attribute of the ExactlyOneOf group of the attribute is set, the extra attributes set to their default value are removed
+func (b *blockValidation) validateExactlyOneOf(name string, tfSchema *schema.Schema, checkedGroups map[string]bool) {
+	if len(tfSchema.ExactlyOneOf) == 0 {
+		return
+	}
+	var group, setAttributes []string
+	for _, key := range tfSchema.ExactlyOneOf {
+		if sibling, isSibling := b.getSiblingName(key); isSibling {
+			group = append(group, sibling)
+		}
+	}
+	sort.Strings(group)
+	groupKey := strings.Join(group, ",")
+	if len(group) == 0 || checkedGroups[groupKey] {
+		return
+	}
+	checkedGroups[groupKey] = true
+
+	for _, attribute := range group {
+		if b.isSet(attribute) {
+			setAttributes = append(setAttributes, attribute)
+		}
+	}
+	for i := 0; i < len(setAttributes) && len(setAttributes) > 1; {
+		if b.isDefaultValue(setAttributes[i]) {
+			b.remove(setAttributes[i], fmt.Sprintf("is set to its default value and only one of %s can be set", groupKey))
+			setAttributes = append(setAttributes[:i], setAttributes[i+1:]...)
+			continue
+		}
+		i++
+	}
+
+	if len(setAttributes) == 0 {
+		b.report(group[0], fmt.Sprintf("is not set, exactly one of %s must be set but none was found in discovery", groupKey))
+	} else if len(setAttributes) > 1 {
+		b.report(setAttributes[0], fmt.Sprintf("is set with %s, exactly one of %s must be set", strings.Join(setAttributes[1:], ","), groupKey))
+	}
+}
+
+func (b *blockValidation) getBlocks(name string) []*hclsyntax.Block {
+	var blocks []*hclsyntax.Block
+	for _, block := range b.body.Blocks {
+		if block.Type == name {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// isSet checks if the attribute is written with a value other than a placeholder and was not removed by a fix
+func (b *blockValidation) isSet(name string) bool {
+	if b.removed[name] {
+		return false
+	}
+	if attribute, exists := b.body.Attributes[name]; exists {
+		return !isPlaceholderValue(attribute.Expr)
+	}
+	return len(b.getBlocks(name)) > 0
+}
+
+func (b *blockValidation) isDefaultValue(name string) bool {
+	tfSchema, exists := b.resourceSchema.Schema[name]
+	attribute, isAttribute := b.body.Attributes[name]
+	if !exists || !isAttribute || tfSchema.Default == nil || tfSchema.Required {
+		return false
+	}
+	value, isLiteral := getLiteralValue(attribute.Expr, tfSchema.Type)
+	return isLiteral && fmt.Sprintf("%v", value) == fmt.Sprintf("%v", tfSchema.Default)
+}
+
+func (b *blockValidation) hasConflict(name string, conflict string) bool {
+	tfSchema, exists := b.resourceSchema.Schema[name]
+	if !exists {
+		return false
+	}
+	for _, conflictKey := range tfSchema.ConflictsWith {
+		if sibling, isSibling := b.getSiblingName(conflictKey); isSibling && sibling == conflict {
+			return true
+		}
+	}
+	return false
+}
+
+// getSiblingName returns the name of the attribute referred by a schema key e.g. source_details.0.source_id, if the attribute is in the same block
+func (b *blockValidation) getSiblingName(schemaKey string) (string, bool) {
+	name := schemaKey
+	if b.schemaPrefix != "" {
+		if !strings.HasPrefix(schemaKey, b.schemaPrefix+".") {
+			return "", false
+		}
+		name = strings.TrimPrefix(schemaKey, b.schemaPrefix+".")
+	}
+	_, exists := b.resourceSchema.Schema[name]
+	return name, exists && !strings.Contains(name, ".")
+}
+
+func (b *blockValidation) getPath(name string) string {
+	if b.attributePrefix == "" {
+		return name
+	}
+	return b.attributePrefix + "." + name
+}
+
+func (b *blockValidation) getSchemaKey(name string) string {
+	if b.schemaPrefix == "" {
+		return name
+	}
+	return b.schemaPrefix + "." + name
+}
+
+func (b *blockValidation) getSourcePath(name string) []string {
+	sourcePath := make([]string, len(b.sourcePath), len(b.sourcePath)+2)
+	copy(sourcePath, b.sourcePath)
+	return append(sourcePath, name)
+}
+
+func (b *blockValidation) report(name string, message string) {
+	b.violations = append(b.violations, &SchemaViolation{Attribute: b.getPath(name), Message: message})
+}
+
+func (b *blockValidation) remove(name string, message string) {
+	b.removed[name] = true
+	b.violations = append(b.violations, &SchemaViolation{Attribute: b.getPath(name), Message: message, Fixed: true, sourcePath: b.getSourcePath(name)})
+}
+
+func isPlaceholderValue(expr hclsyntax.Expression) bool {
+	value, diags := expr.Value(nil)
+	return !diags.HasErrors() && value.IsKnown() && !value.IsNull() && value.Type() == cty.String && value.AsString() == globalvar.PlaceholderValueForMissingAttribute
+}
+
+// getLiteralValue returns the value of an attribute written as a literal, converted to the type of the attribute in the schema, references and variables are not literals
+func getLiteralValue(expr hclsyntax.Expression, valueType schema.ValueType) (interface{}, bool) {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsKnown() || value.IsNull() || !value.Type().IsPrimitiveType() {
+		return nil, false
+	}
+
+	var literal string
+	switch value.Type() {
+	case cty.String:
+		literal = value.AsString()
+	case cty.Number:
+		literal = value.AsBigFloat().Text('f', -1)
+	case cty.Bool:
+		literal = strconv.FormatBool(value.True())
+	}
+
+	// the numbers and booleans are written as strings
+	switch valueType {
+	case schema.TypeString:
+		return literal, true
+	case schema.TypeInt:
+		if result, err := strconv.Atoi(literal); err == nil {
+			return result, true
+		}
+	case schema.TypeFloat:
+		if result, err := strconv.ParseFloat(literal, 64); err == nil {
+			return result, true
+		}
+	case schema.TypeBool:
+		if result, err := strconv.ParseBool(literal); err == nil {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// removeSourceAttribute returns a copy of the source attributes without the attribute at the path, the source attributes are not modified
+func removeSourceAttribute(value interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, err := strconv.Atoi(path[0]); err == nil {
+			// a nested block with a single item may be discovered as a map instead of a list
+			if _, exists := v[path[0]]; !exists {
+				return removeSourceAttribute(v, path[1:])
+			}
+		}
+		child, exists := v[path[0]]
+		if !exists {
+			return v
+		}
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = item
+		}
+		if len(path) == 1 {
+			delete(result, path[0])
+		} else {
+			result[path[0]] = removeSourceAttribute(child, path[1:])
+		}
+		return result
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index >= len(v) {
+			return v
+		}
+		result := make([]interface{}, len(v))
+		copy(result, v)
+		result[index] = removeSourceAttribute(v[index], path[1:])
+		return result
+	}
+	return value
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package commonexport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSchemaValidationResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name":        {Type: schema.TypeString, Required: true},
+			"old_name":    {Type: schema.TypeString, Optional: true, Deprecated: "use name instead"},
+			"cidr_block":  {Type: schema.TypeString, Optional: true, ConflictsWith: []string{"cidr_blocks"}},
+			"cidr_blocks": {Type: schema.TypeList, Optional: true, MaxItems: 2, Elem: &schema.Schema{Type: schema.TypeString}, ConflictsWith: []string{"cidr_block"}},
+			"is_ipv6":     {Type: schema.TypeBool, Optional: true, Default: false, ConflictsWith: []string{"ipv6_cidr"}},
+			"ipv6_cidr":   {Type: schema.TypeString, Optional: true, ConflictsWith: []string{"is_ipv6"}},
+			"shape":       {Type: schema.TypeString, Optional: true, ValidateFunc: validation.StringInSlice([]string{"small", "large"}, false)},
+			"source_details": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"source_id":  {Type: schema.TypeString, Optional: true, ExactlyOneOf: []string{"source_details.0.source_id", "source_details.0.source_url"}},
+						"source_url": {Type: schema.TypeString, Optional: true, ExactlyOneOf: []string{"source_details.0.source_id", "source_details.0.source_url"}},
+					},
+				},
+			},
+			"config": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem:     &schema.Resource{Schema: map[string]*schema.Schema{"value": {Type: schema.TypeString, Optional: true}}},
+			},
+		},
+	}
+}
+
+func TestUnitGetValidatedHCLStringFromMap(t *testing.T) {
+	TfHclVersionvar = &TfHclVersion12{Value: TfVersion12}
+	sourceAttributes := map[string]interface{}{
+		"name":        "vcn1",
+		"cidr_block":  "10.0.0.0/16",
+		"cidr_blocks": []interface{}{"10.0.0.0/16", "10.1.0.0/16", "10.2.0.0/16"},
+		"is_ipv6":     false,
+		"ipv6_cidr":   "fd00::/64",
+		"shape":       "",
+		"source_details": []interface{}{
+			map[string]interface{}{"source_id": "ocid1.image.1", "source_url": "https://objectstorage/image"},
+		},
+	}
+	ociRes := &OCIResource{
+		TerraformResource: TerraformResource{TerraformClass: "oci_test_vcn", TerraformName: "vcn1"},
+		SourceAttributes:  sourceAttributes,
+	}
+
+	builder := &strings.Builder{}
+	assert.NoError(t, getValidatedHCLStringFromMap(builder, ociRes, testSchemaValidationResource(), map[string]string{}))
+	config := builder.String()
+
+	// the unambiguous violations are fixed by removing the attribute from the configuration, but not from the resource
+	assert.NotContains(t, config, "\nis_ipv6 =")
+	assert.NotContains(t, config, "\nshape =")
+	assert.Contains(t, config, "ipv6_cidr = \"fd00::/64\"")
+	assert.Contains(t, config, "cidr_block = \"10.0.0.0/16\"")
+	assert.Equal(t, false, ociRes.SourceAttributes["is_ipv6"])
+	assert.Equal(t, "", ociRes.SourceAttributes["shape"])
+
+	violations := make([]string, 0, len(ociRes.SchemaViolations))
+	for _, violation := range ociRes.SchemaViolations {
+		violations = append(violations, violation.String())
+	}
+	assert.Len(t, violations, 6)
+	assert.Equal(t, "'cidr_blocks' has 3 items but at most 2 are allowed", violations[0])
+	assert.Equal(t, "'config' is a required block that was not found in discovery, a placeholder value was written instead of the block", violations[1])
+	assert.True(t, strings.HasPrefix(violations[2], "'shape' has an invalid empty value: expected shape to be one of"), violations[2])
+	assert.True(t, strings.HasSuffix(violations[2], "removed from the configuration"), violations[2])
+	assert.Equal(t, "'source_details[0].source_id' is set with source_url, exactly one of source_id,source_url must be set", violations[3])
+	assert.Equal(t, "'cidr_block' conflicts with 'cidr_blocks'", violations[4])
+	assert.Equal(t, "'is_ipv6' is set to its default value and conflicts with 'ipv6_cidr', removed from the configuration", violations[5])
+}
+
+func TestUnitValidateConfiguration(t *testing.T) {
+	config := "name = \"vcn1\"\nold_name = \"vcn\"\nconfig {\n}\nsource_details {\nsource_url = \"https://objectstorage/image\"\n}\n"
+	violations := validateConfiguration(config, testSchemaValidationResource(), "oci_test_vcn.vcn1")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "'old_name' is deprecated: use name instead, removed from the configuration", violations[0].String())
+	assert.Equal(t, []string{"old_name"}, violations[0].sourcePath)
+
+	violations = validateConfiguration("name = \"vcn1\"\nconfig {\n}\nsource_details {\n}\n", testSchemaValidationResource(), "oci_test_vcn.vcn1")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "'source_details[0].source_id' is not set, exactly one of source_id,source_url must be set but none was found in discovery", violations[0].String())
+
+	// references are not validated
+	violations = validateConfiguration("name = \"vcn1\"\nshape = var.shape\nconfig {\n}\n", testSchemaValidationResource(), "oci_test_vcn.vcn1")
+	assert.Empty(t, violations)
+}
+
+func TestUnitRemoveSourceAttribute(t *testing.T) {
+	sourceAttributes := map[string]interface{}{
+		"name":           "vcn1",
+		"source_details": []interface{}{map[string]interface{}{"source_id": "ocid1.image.1", "source_url": "https://objectstorage/image"}},
+		"config":         map[string]interface{}{"value": "a"},
+	}
+
+	result := removeSourceAttribute(sourceAttributes, []string{"source_details", "0", "source_url"}).(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{"source_id": "ocid1.image.1"}}, result["source_details"])
+	assert.Len(t, sourceAttributes["source_details"].([]interface{})[0], 2, "the source attributes should not be modified")
+
+	// a nested block discovered as a map
+	result = removeSourceAttribute(sourceAttributes, []string{"config", "0", "value"}).(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{}, result["config"])
+	assert.Equal(t, "vcn1", result["name"])
+
+	result = removeSourceAttribute(sourceAttributes, []string{"missing", "0", "value"}).(map[string]interface{})
+	assert.Equal(t, sourceAttributes, result)
+}
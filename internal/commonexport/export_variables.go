@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
 	"github.com/oracle/terraform-provider-oci/internal/globalvar"
 	"github.com/oracle/terraform-provider-oci/internal/utils"
 )
@@ -122,3 +124,37 @@ func exportAttributeForResourceAndGlobalLevel(sourceAttributes map[string]interf
 func exportAttributeFromDefaultList(defaultList []string, sourceAttributes map[string]interface{}, resourceName string, interpolationMap map[string]string) error {
 	return exportAttributeForGlobalLevel(sourceAttributes, resourceName, defaultList, interpolationMap)
 }
+
+/* Functions for handling sensitive attributes */
+
+// isSensitiveVariableAttribute returns true if the attribute is marked Sensitive in the schema and its value can be set from a variable
+func isSensitiveVariableAttribute(tfSchema *schema.Schema) bool {
+	if !tfSchema.Sensitive {
+		return false
+	}
+	switch tfSchema.Type {
+	case schema.TypeString, schema.TypeInt, schema.TypeBool, schema.TypeFloat:
+		return true
+	}
+	return false
+}
+
+// exportSensitiveAttributeAsVariable adds a sensitive variable for the attribute of a resource and returns the reference to it
+// The value is not written to the configuration but to the secrets file, an empty value is written if it is not known
+func exportSensitiveAttributeAsVariable(ociRes *OCIResource, attributePrefix string, tfAttribute string, value string) string {
+	attribute := tfAttribute
+	if attributePrefix != "" {
+		// nested attributes are prefixed with the path of their block e.g. connection_details[0].password
+		attribute = strings.NewReplacer("[", "-", "]", "").Replace(attributePrefix + globalvar.DotDelimiter + tfAttribute)
+	}
+	variableName := utils.GetVarNameFromAttributeOfResources(attribute, ociRes.TerraformClass, ociRes.TerraformName)
+	utils.Debugf("[DEBUG] Exporting sensitive attribute %s of resource %s as variable %s", attribute, ociRes.TerraformName, variableName)
+
+	SensitiveVarsLock.Lock()
+	if SensitiveVars == nil {
+		SensitiveVars = map[string]string{}
+	}
+	SensitiveVars[variableName] = value
+	SensitiveVarsLock.Unlock()
+	return TfHclVersionvar.GetVarHclString(variableName)
+}
@@ -10,6 +10,8 @@ const (
 	AuthInstancePrincipalWithCertsSetting = "InstancePrincipalWithCerts"
 	AuthSecurityToken                     = "SecurityToken"
 	ResourcePrincipal                     = "ResourcePrincipal"
+	AuthCredentialProcess                 = "CredentialProcess"
+	AuthOkeWorkloadIdentity               = "OkeWorkloadIdentity"
 	RequestHeaderOpcOboToken              = "opc-obo-token"
 	RequestHeaderOpcHostSerial            = "opc-host-serial"
 	DefaultRequestTimeout                 = 0
@@ -41,7 +43,11 @@ const (
 	OboTokenAttrName             = "obo_token"
 	OboTokenPath                 = "obo_token_path"
 	ConfigFileProfileAttrName    = "config_file_profile"
+	CredentialProcessAttrName    = "credential_process"
+	SessionTokenRefreshAttrName  = "session_token_refresh"
 	DefinedTagsToIgnore          = "ignore_defined_tags"
+	FreeformTagsToIgnore         = "ignore_freeform_tags"
+	DefaultTagsAttrName          = "default_tags"
 
 	DefaultConfigFileName    = "config"
 	DefaultConfigDirName     = ".oci"
@@ -55,6 +61,17 @@ const (
 	DefaultStateFilename            = "terraform.tfstate"
 	VarsFile                        = "vars.tf"
 	ProviderFile                    = "provider.tf"
+	ImportsFile                     = "imports.tf"
+	OutputsFile                     = "outputs.tf"
+	ModulesFile                     = "main.tf"
+	DependencyGraphDotFile          = "resource_graph.dot"
+	DependencyGraphJsonFile         = "resource_graph.json"
+	CheckpointDir                   = ".checkpoint"
+	CheckpointFile                  = "checkpoint.json"
+	ExternalReferencesFile          = "external_references.tf"
+	SnapshotFile                    = "snapshot.json"
+	SecretsFile                     = "secrets.auto.tfvars"
+	GitIgnoreFile                   = ".gitignore"
 	MissingRequiredAttributeWarning = `
 
 Warning: There are one or more 'Required' attributes for which a value could not be discovered.
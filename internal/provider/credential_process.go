@@ -0,0 +1,289 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	oci_common "github.com/oracle/oci-go-sdk/v65/common"
+)
+
+const (
+	// The credentials are refreshed this long before they expire, or halfway through their lifetime if it is shorter
+	credentialProcessRefreshWindow = 5 * time.Minute
+	credentialProcessTimeout       = time.Minute
+	// A failed refresh is retried after this, the current credentials are used meanwhile
+	credentialProcessRefreshBackoff = time.Minute
+)
+
+var runCredentialProcessVar = runCredentialProcess
+
+// credentialProcessOutput is the JSON written to stdout by the command of the CredentialProcess auth
+type credentialProcessOutput struct {
+	TenancyOcid        string     `json:"tenancy_ocid"`
+	UserOcid           string     `json:"user_ocid"`
+	Fingerprint        string     `json:"fingerprint"`
+	PrivateKey         string     `json:"private_key"`
+	PrivateKeyPassword string     `json:"private_key_password"`
+	SecurityToken      string     `json:"security_token"`
+	Expiration         *time.Time `json:"expiration"`
+}
+
+// credentialProcessCredentials is one snapshot of the credentials returned by the command, it is replaced as a whole when the command is run again
+// so that the key id and the private key used to sign a request are always from the same credentials
+type credentialProcessCredentials struct {
+	*credentialProcessOutput
+	privateKey  *rsa.PrivateKey
+	refreshTime time.Time
+}
+
+func (c *credentialProcessCredentials) isExpired() bool {
+	return c.Expiration != nil && !time.Now().Before(*c.Expiration)
+}
+
+func (c *credentialProcessCredentials) isRefreshDue() bool {
+	return !c.refreshTime.IsZero() && !time.Now().Before(c.refreshTime)
+}
+
+func (c *credentialProcessCredentials) KeyID() (string, error) {
+	if c.SecurityToken != "" {
+		return "ST$" + c.SecurityToken, nil
+	}
+	return fmt.Sprintf("%s/%s/%s", c.TenancyOcid, c.UserOcid, c.Fingerprint), nil
+}
+
+func (c *credentialProcessCredentials) PrivateRSAKey() (*rsa.PrivateKey, error) {
+	return c.privateKey, nil
+}
+
+/*
+credentialProcessConfigProvider provides the credentials returned by an external command, the command is run again before the credentials expire
+The command is not run while holding the lock, the other requests keep using the current credentials until the new ones are returned
+*/
+type credentialProcessConfigProvider struct {
+	command            string
+	credentials        atomic.Value // *credentialProcessCredentials
+	mutex              sync.Mutex
+	refreshing         bool
+	lastRefreshFailure time.Time
+	commandMutex       sync.Mutex // serializes the runs of the command when there are no valid credentials to use meanwhile
+}
+
+func newCredentialProcessConfigProvider(command string) *credentialProcessConfigProvider {
+	return &credentialProcessConfigProvider{command: command}
+}
+
+func runCredentialProcess(command string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), credentialProcessTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// getCredentials returns the cached credentials, or runs the command if they are about to expire
+func (p *credentialProcessConfigProvider) getCredentials() (*credentialProcessCredentials, error) {
+	credentials, refresh := p.getCachedCredentials()
+	if !refresh {
+		return credentials, nil
+	}
+	return p.refresh(credentials)
+}
+
+// getCachedCredentials returns the current credentials, and whether the caller should run the command before using them
+func (p *credentialProcessConfigProvider) getCachedCredentials() (*credentialProcessCredentials, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	credentials, _ := p.credentials.Load().(*credentialProcessCredentials)
+	if credentials == nil || credentials.isExpired() {
+		return credentials, true
+	}
+	if p.refreshing || !credentials.isRefreshDue() || time.Since(p.lastRefreshFailure) < credentialProcessRefreshBackoff {
+		return credentials, false
+	}
+	p.refreshing = true
+	return credentials, true
+}
+
+func (p *credentialProcessConfigProvider) refresh(current *credentialProcessCredentials) (*credentialProcessCredentials, error) {
+	p.commandMutex.Lock()
+	defer p.commandMutex.Unlock()
+
+	// The credentials may have been returned by the command run for another request meanwhile
+	if latest, _ := p.credentials.Load().(*credentialProcessCredentials); latest != nil && latest != current && !latest.isExpired() {
+		return latest, nil
+	}
+
+	credentials, err := p.runCommand()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.refreshing = false
+	if err != nil {
+		// The credentials which have not expired yet are used until the command succeeds
+		if current != nil && !current.isExpired() {
+			p.lastRefreshFailure = time.Now()
+			log.Printf("[WARN] unable to refresh the credentials, they expire at %s, retrying in %s: %v", current.Expiration.Format(time.RFC3339), credentialProcessRefreshBackoff, err)
+			return current, nil
+		}
+		return nil, err
+	}
+	p.credentials.Store(credentials)
+	p.lastRefreshFailure = time.Time{}
+	return credentials, nil
+}
+
+func (p *credentialProcessConfigProvider) runCommand() (*credentialProcessCredentials, error) {
+	output, err := runCredentialProcessVar(p.command)
+	if err != nil {
+		return nil, fmt.Errorf("credential process failed: %v", err)
+	}
+
+	credentials := &credentialProcessOutput{}
+	if err := json.Unmarshal(output, credentials); err != nil {
+		return nil, fmt.Errorf("unable to parse the output of the credential process: %v", err)
+	}
+	if credentials.PrivateKey == "" {
+		return nil, fmt.Errorf("the output of the credential process must contain a private_key")
+	}
+	if credentials.SecurityToken == "" && (credentials.TenancyOcid == "" || credentials.UserOcid == "" || credentials.Fingerprint == "") {
+		return nil, fmt.Errorf("the output of the credential process must contain a security_token, or a tenancy_ocid, user_ocid and fingerprint")
+	}
+
+	var password *string
+	if credentials.PrivateKeyPassword != "" {
+		password = &credentials.PrivateKeyPassword
+	}
+	privateKey, err := oci_common.PrivateKeyFromBytes([]byte(credentials.PrivateKey), password)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the private_key returned by the credential process: %v", err)
+	}
+
+	result := &credentialProcessCredentials{credentialProcessOutput: credentials, privateKey: privateKey}
+	if credentials.Expiration != nil {
+		refreshWindow := credentialProcessRefreshWindow
+		if lifetime := time.Until(*credentials.Expiration); lifetime/2 < refreshWindow {
+			refreshWindow = lifetime / 2
+		}
+		result.refreshTime = credentials.Expiration.Add(-refreshWindow)
+		log.Printf("[DEBUG] credential process returned credentials expiring at %s", credentials.Expiration.Format(time.RFC3339))
+	}
+	return result, nil
+}
+
+func (p *credentialProcessConfigProvider) AuthType() (oci_common.AuthConfig, error) {
+	return oci_common.AuthConfig{
+			AuthType:         oci_common.UnknownAuthenticationType,
+			IsFromConfigFile: false,
+			OboToken:         nil,
+		},
+		fmt.Errorf("unsupported, keep the interface")
+}
+
+// The values which are not returned by the command are read from the Terraform configuration by the next config provider
+func (p *credentialProcessConfigProvider) TenancyOCID() (string, error) {
+	credentials, err := p.getCredentials()
+	if err != nil {
+		return "", err
+	}
+	if credentials.TenancyOcid == "" {
+		return "", fmt.Errorf("the credential process did not return a tenancy_ocid")
+	}
+	return credentials.TenancyOcid, nil
+}
+
+func (p *credentialProcessConfigProvider) UserOCID() (string, error) {
+	credentials, err := p.getCredentials()
+	if err != nil {
+		return "", err
+	}
+	// The user is not needed with a security token
+	if credentials.UserOcid == "" && credentials.SecurityToken == "" {
+		return "", fmt.Errorf("the credential process did not return a user_ocid")
+	}
+	return credentials.UserOcid, nil
+}
+
+func (p *credentialProcessConfigProvider) KeyFingerprint() (string, error) {
+	credentials, err := p.getCredentials()
+	if err != nil {
+		return "", err
+	}
+	if credentials.Fingerprint == "" && credentials.SecurityToken == "" {
+		return "", fmt.Errorf("the credential process did not return a fingerprint")
+	}
+	return credentials.Fingerprint, nil
+}
+
+func (p *credentialProcessConfigProvider) Region() (string, error) {
+	return "", fmt.Errorf("the region is not returned by the credential process")
+}
+
+func (p *credentialProcessConfigProvider) KeyID() (string, error) {
+	credentials, err := p.getCredentials()
+	if err != nil {
+		return "", err
+	}
+	return credentials.KeyID()
+}
+
+func (p *credentialProcessConfigProvider) PrivateRSAKey() (*rsa.PrivateKey, error) {
+	credentials, err := p.getCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.PrivateRSAKey()
+}
+
+// credentialProcessComposingConfigProvider is the composed config provider of the CredentialProcess auth, it keeps the credential process to sign the requests with
+type credentialProcessComposingConfigProvider struct {
+	oci_common.ConfigurationProvider
+	credentialProcess *credentialProcessConfigProvider
+}
+
+// credentialProcessRequestSigner signs each request with the key id and the private key of one snapshot of the credentials
+type credentialProcessRequestSigner struct {
+	credentialProcess *credentialProcessConfigProvider
+	genericHeaders    []string
+}
+
+func (s credentialProcessRequestSigner) Sign(request *http.Request) error {
+	credentials, err := s.credentialProcess.getCredentials()
+	if err != nil {
+		return err
+	}
+	return oci_common.RequestSigner(credentials, s.genericHeaders, oci_common.DefaultBodyHeaders()).Sign(request)
+}
+
+// buildRequestSigner returns the signer of the requests, the KeyID and PrivateRSAKey of the config provider are read separately by the default signer
+func buildRequestSigner(configProvider oci_common.ConfigurationProvider, genericHeaders []string) oci_common.HTTPRequestSigner {
+	if provider, ok := configProvider.(credentialProcessComposingConfigProvider); ok {
+		return credentialProcessRequestSigner{credentialProcess: provider.credentialProcess, genericHeaders: genericHeaders}
+	}
+	return oci_common.RequestSigner(configProvider, genericHeaders, oci_common.DefaultBodyHeaders())
+}
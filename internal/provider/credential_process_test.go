@@ -0,0 +1,253 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package provider
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	oci_common "github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/stretchr/testify/assert"
+
+	tf_client "github.com/oracle/terraform-provider-oci/internal/client"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+func getTestCredentialProcessOutput(t *testing.T, expiration *time.Time) ([]byte, *rsa.PrivateKey) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate private key: %v", err)
+	}
+	privateKeyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	output, err := json.Marshal(credentialProcessOutput{
+		TenancyOcid: "ocid1.tenancy.oc1..tenancy",
+		UserOcid:    "ocid1.user.oc1..user",
+		Fingerprint: "fingerprint",
+		PrivateKey:  string(privateKeyPem),
+		Expiration:  expiration,
+	})
+	if err != nil {
+		t.Fatalf("unable to marshal credentials: %v", err)
+	}
+	return output, privateKey
+}
+
+func TestUnitCredentialProcessConfigProvider(t *testing.T) {
+	defer func() { runCredentialProcessVar = runCredentialProcess }()
+
+	invocations := 0
+	expiration := time.Now().Add(time.Hour)
+	output, privateKey := getTestCredentialProcessOutput(t, &expiration)
+	runCredentialProcessVar = func(command string) ([]byte, error) {
+		assert.Equal(t, "broker credentials", command)
+		invocations++
+		return output, nil
+	}
+
+	provider := newCredentialProcessConfigProvider("broker credentials")
+	keyId, err := provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ocid1.tenancy.oc1..tenancy/ocid1.user.oc1..user/fingerprint", keyId)
+	key, err := provider.PrivateRSAKey()
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey.D, key.D)
+	_, err = provider.Region()
+	assert.Error(t, err, "the region should be read from the Terraform configuration")
+	assert.Equal(t, 1, invocations, "the credentials should be cached until they are about to expire")
+
+	// the command is run again when the credentials are about to expire
+	expiration = time.Now().Add(credentialProcessRefreshWindow)
+	output, _ = getTestCredentialProcessOutput(t, &expiration)
+	setTestCredentialsRefreshTime(provider, time.Now())
+	_, err = provider.TenancyOCID()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, invocations)
+	assert.True(t, getTestCredentials(provider).refreshTime.Before(expiration.Add(-credentialProcessRefreshWindow/2).Add(time.Second)), "short lived credentials should be refreshed halfway through their lifetime")
+
+	// the current credentials are used until they expire when the command fails
+	runCredentialProcessVar = func(command string) ([]byte, error) {
+		invocations++
+		return nil, fmt.Errorf("broker unavailable")
+	}
+	setTestCredentialsRefreshTime(provider, time.Now())
+	_, err = provider.UserOCID()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, invocations)
+	_, err = provider.UserOCID()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, invocations, "a failed refresh should not be retried before the backoff")
+	expired := time.Now().Add(-time.Minute)
+	credentials := *getTestCredentials(provider)
+	expiredOutput := *credentials.credentialProcessOutput
+	expiredOutput.Expiration = &expired
+	credentials.credentialProcessOutput = &expiredOutput
+	provider.credentials.Store(&credentials)
+	_, err = provider.UserOCID()
+	assert.Error(t, err)
+}
+
+func getTestCredentials(provider *credentialProcessConfigProvider) *credentialProcessCredentials {
+	return provider.credentials.Load().(*credentialProcessCredentials)
+}
+
+func setTestCredentialsRefreshTime(provider *credentialProcessConfigProvider, refreshTime time.Time) {
+	credentials := *getTestCredentials(provider)
+	credentials.refreshTime = refreshTime
+	provider.credentials.Store(&credentials)
+}
+
+func TestUnitCredentialProcessConfigProvider_refreshWithoutBlocking(t *testing.T) {
+	defer func() { runCredentialProcessVar = runCredentialProcess }()
+
+	expiration := time.Now().Add(time.Hour)
+	output, privateKey := getTestCredentialProcessOutput(t, &expiration)
+	runCredentialProcessVar = func(command string) ([]byte, error) {
+		return output, nil
+	}
+	provider := newCredentialProcessConfigProvider("broker credentials")
+	_, err := provider.KeyID()
+	assert.NoError(t, err)
+
+	// the other requests use the current credentials while the command is run again
+	started := make(chan bool)
+	release := make(chan bool)
+	refreshedOutput, refreshedKey := getTestCredentialProcessOutput(t, &expiration)
+	runCredentialProcessVar = func(command string) ([]byte, error) {
+		started <- true
+		<-release
+		return refreshedOutput, nil
+	}
+	setTestCredentialsRefreshTime(provider, time.Now())
+	refreshed := make(chan *rsa.PrivateKey)
+	go func() {
+		key, _ := provider.PrivateRSAKey()
+		refreshed <- key
+	}()
+	<-started
+	key, err := provider.PrivateRSAKey()
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey.D, key.D)
+	close(release)
+	assert.Equal(t, refreshedKey.D, (<-refreshed).D)
+	key, err = provider.PrivateRSAKey()
+	assert.NoError(t, err)
+	assert.Equal(t, refreshedKey.D, key.D)
+}
+
+func TestUnitCredentialProcessRequestSigner(t *testing.T) {
+	defer func() { runCredentialProcessVar = runCredentialProcess }()
+
+	output, privateKey := getTestCredentialProcessOutput(t, nil)
+	runCredentialProcessVar = func(command string) ([]byte, error) {
+		return output, nil
+	}
+	provider := newCredentialProcessConfigProvider("broker credentials")
+	signer := buildRequestSigner(credentialProcessComposingConfigProvider{ConfigurationProvider: provider, credentialProcess: provider}, oci_common.DefaultGenericHeaders())
+	assert.IsType(t, credentialProcessRequestSigner{}, signer)
+
+	request, err := http.NewRequest(http.MethodGet, "https://iaas.us-phoenix-1.oraclecloud.com/20160918/vcns", nil)
+	assert.NoError(t, err)
+	request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	assert.NoError(t, signer.Sign(request))
+	assert.Contains(t, request.Header.Get("Authorization"), `keyId="ocid1.tenancy.oc1..tenancy/ocid1.user.oc1..user/fingerprint"`)
+
+	// the signature is computed with the private key of the same credentials as the key id
+	expected, err := http.NewRequest(http.MethodGet, request.URL.String(), nil)
+	assert.NoError(t, err)
+	expected.Header.Set("Date", request.Header.Get("Date"))
+	assert.NoError(t, oci_common.DefaultRequestSigner(&credentialProcessCredentials{credentialProcessOutput: getTestCredentials(provider).credentialProcessOutput, privateKey: privateKey}).Sign(expected))
+	assert.Equal(t, expected.Header.Get("Authorization"), request.Header.Get("Authorization"))
+
+	assert.IsType(t, oci_common.RequestSigner(provider, oci_common.DefaultGenericHeaders(), oci_common.DefaultBodyHeaders()), buildRequestSigner(provider, oci_common.DefaultGenericHeaders()))
+}
+
+func TestUnitCredentialProcessConfigProvider_securityToken(t *testing.T) {
+	defer func() { runCredentialProcessVar = runCredentialProcess }()
+
+	output, _ := getTestCredentialProcessOutput(t, nil)
+	credentials := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(output, &credentials))
+	delete(credentials, "user_ocid")
+	delete(credentials, "fingerprint")
+	credentials["security_token"] = "token"
+	runCredentialProcessVar = func(command string) ([]byte, error) {
+		return json.Marshal(credentials)
+	}
+
+	provider := newCredentialProcessConfigProvider("broker session")
+	keyId, err := provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$token", keyId)
+	userOcid, err := provider.UserOCID()
+	assert.NoError(t, err)
+	assert.Empty(t, userOcid)
+	assert.True(t, getTestCredentials(provider).refreshTime.IsZero(), "credentials without expiration should not be refreshed")
+
+	delete(credentials, "security_token")
+	provider = newCredentialProcessConfigProvider("broker session")
+	_, err = provider.KeyID()
+	assert.Error(t, err)
+}
+
+func TestUnitCredentialProcess_basic(t *testing.T) {
+	defer func() { runCredentialProcessVar = runCredentialProcess }()
+	output, privateKey := getTestCredentialProcessOutput(t, nil)
+	runCredentialProcessVar = func(command string) ([]byte, error) {
+		return output, nil
+	}
+
+	r := &schema.Resource{
+		Schema: SchemaMap(),
+	}
+	d := r.Data(nil)
+	d.Set("auth", globalvar.AuthCredentialProcess)
+	d.Set(globalvar.CredentialProcessAttrName, "broker credentials")
+	d.Set(globalvar.RegionAttrName, "us-phoenix-1")
+	clients := &tf_client.OracleClients{
+		SdkClientMap:  make(map[string]interface{}, len(tf_client.OracleClientRegistrationsVar.RegisteredClients)),
+		Configuration: make(map[string]string),
+	}
+	sdkConfigProvider, err := GetSdkConfigProvider(d, clients)
+	assert.NoError(t, err)
+	assert.IsType(t, credentialProcessRequestSigner{}, buildRequestSigner(sdkConfigProvider, oci_common.DefaultGenericHeaders()), "the requests should be signed with one snapshot of the credentials")
+	keyId, err := sdkConfigProvider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ocid1.tenancy.oc1..tenancy/ocid1.user.oc1..user/fingerprint", keyId)
+	key, err := sdkConfigProvider.PrivateRSAKey()
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey.D, key.D)
+	region, err := sdkConfigProvider.Region()
+	assert.NoError(t, err)
+	assert.Equal(t, "us-phoenix-1", region)
+
+	// the errors of the command are returned when the provider is configured
+	runCredentialProcessVar = func(command string) ([]byte, error) {
+		return nil, fmt.Errorf("broker unavailable")
+	}
+	_, err = GetSdkConfigProvider(d, clients)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "broker unavailable")
+}
+
+func TestUnitRunCredentialProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test commands require sh")
+	}
+	output, err := runCredentialProcess(`echo '{"security_token": "token"}'`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"security_token": "token"}`, string(output))
+
+	_, err = runCredentialProcess("echo 'broker unavailable' >&2; exit 1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "broker unavailable")
+}
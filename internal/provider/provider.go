@@ -90,7 +90,7 @@ func ociVarName(attrName string) string {
 
 func init() {
 	descriptions = map[string]string{
-		globalvar.AuthAttrName:        fmt.Sprintf("(Optional) The type of auth to use. Options are '%s', '%s' and '%s' and '%s'. By default, '%s' will be used.", globalvar.AuthAPIKeySetting, globalvar.AuthSecurityToken, globalvar.AuthInstancePrincipalSetting, globalvar.ResourcePrincipal, globalvar.AuthAPIKeySetting),
+		globalvar.AuthAttrName:        fmt.Sprintf("(Optional) The type of auth to use. Options are '%s', '%s' and '%s' and '%s' and '%s' and '%s'. By default, '%s' will be used.", globalvar.AuthAPIKeySetting, globalvar.AuthSecurityToken, globalvar.AuthInstancePrincipalSetting, globalvar.ResourcePrincipal, globalvar.AuthCredentialProcess, globalvar.AuthOkeWorkloadIdentity, globalvar.AuthAPIKeySetting),
 		globalvar.TenancyOcidAttrName: fmt.Sprintf("(Optional) The tenancy OCID for a user. The tenancy OCID can be found at the bottom of user settings in the Oracle Cloud Infrastructure console. Required if auth is set to '%s', ignored otherwise.", globalvar.AuthAPIKeySetting),
 		globalvar.UserOcidAttrName:    fmt.Sprintf("(Optional) The user OCID. This can be found in user settings in the Oracle Cloud Infrastructure console. Required if auth is set to '%s', ignored otherwise.", globalvar.AuthAPIKeySetting),
 		globalvar.FingerprintAttrName: fmt.Sprintf("(Optional) The fingerprint for the user's RSA key. This can be found in user settings in the Oracle Cloud Infrastructure console. Required if auth is set to '%s', ignored otherwise.", globalvar.AuthAPIKeySetting),
@@ -105,7 +105,14 @@ func init() {
 		globalvar.RetryDurationSecondsAttrName: "(Optional) The minimum duration (in seconds) to retry a resource operation in response to an error.\n" +
 			"The actual retry duration may be longer due to jittering of retry operations. This value is ignored if the `disable_auto_retries` field is set to true.",
 		globalvar.ConfigFileProfileAttrName: "(Optional) The profile name to be used from config file, if not set it will be DEFAULT.",
-		globalvar.DefinedTagsToIgnore:       "(Optional) List of defined tags keys that Terraform should ignore when planning creates and updates to the associated remote object",
+		globalvar.CredentialProcessAttrName: fmt.Sprintf("(Optional) The command that returns the credentials as JSON, with the tenancy_ocid, user_ocid, fingerprint and private_key, or the security_token and private_key, and their expiration.\n"+
+			"The command is run again before the credentials expire. Required if auth is set to '%s', ignored otherwise.", globalvar.AuthCredentialProcess),
+		globalvar.SessionTokenRefreshAttrName: fmt.Sprintf("(Optional) Refresh the session token through the auth service before it expires, so that operations running longer than the session do not fail.\n"+
+			"The refreshed token is kept in memory, the token file of the profile is not updated. Ignored if auth is not set to '%s'.", globalvar.AuthSecurityToken),
+		globalvar.DefinedTagsToIgnore:       "(Optional) List of defined tags keys that Terraform should ignore when planning creates and updates to the associated remote object, the keys may be glob patterns like 'Oracle-Tags.*'",
+		globalvar.FreeformTagsToIgnore:      "(Optional) List of freeform tags keys that Terraform should ignore when planning creates and updates to the associated remote object, the keys may be glob patterns like 'CreatedBy*'",
+		globalvar.DefaultTagsAttrName: "(Optional) The freeform_tags and defined_tags that are merged into the tags of every resource that has them when it is created or updated.\n" +
+			"The tags set on the resource take precedence over the default tags, and the default tags are not shown as a diff of the resource.",
 	}
 }
 
@@ -126,7 +133,7 @@ func SchemaMap() map[string]*schema.Schema {
 			Optional:     true,
 			Description:  descriptions[globalvar.AuthAttrName],
 			DefaultFunc:  schema.MultiEnvDefaultFunc([]string{tfVarName(globalvar.AuthAttrName), ociVarName(globalvar.AuthAttrName)}, globalvar.AuthAPIKeySetting),
-			ValidateFunc: validation.StringInSlice([]string{globalvar.AuthAPIKeySetting, globalvar.AuthInstancePrincipalSetting, globalvar.AuthInstancePrincipalWithCertsSetting, globalvar.AuthSecurityToken, globalvar.ResourcePrincipal}, true),
+			ValidateFunc: validation.StringInSlice([]string{globalvar.AuthAPIKeySetting, globalvar.AuthInstancePrincipalSetting, globalvar.AuthInstancePrincipalWithCertsSetting, globalvar.AuthSecurityToken, globalvar.ResourcePrincipal, globalvar.AuthCredentialProcess, globalvar.AuthOkeWorkloadIdentity}, true),
 		},
 		globalvar.TenancyOcidAttrName: {
 			Type:        schema.TypeString,
@@ -192,6 +199,18 @@ func SchemaMap() map[string]*schema.Schema {
 			Description: descriptions[globalvar.ConfigFileProfileAttrName],
 			DefaultFunc: schema.MultiEnvDefaultFunc([]string{tfVarName(globalvar.ConfigFileProfileAttrName), ociVarName(globalvar.ConfigFileProfileAttrName)}, nil),
 		},
+		globalvar.CredentialProcessAttrName: {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: descriptions[globalvar.CredentialProcessAttrName],
+			DefaultFunc: schema.MultiEnvDefaultFunc([]string{tfVarName(globalvar.CredentialProcessAttrName), ociVarName(globalvar.CredentialProcessAttrName)}, nil),
+		},
+		globalvar.SessionTokenRefreshAttrName: {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Description: descriptions[globalvar.SessionTokenRefreshAttrName],
+			DefaultFunc: schema.MultiEnvDefaultFunc([]string{tfVarName(globalvar.SessionTokenRefreshAttrName), ociVarName(globalvar.SessionTokenRefreshAttrName)}, false),
+		},
 		globalvar.DefinedTagsToIgnore: {
 			Type:        schema.TypeList,
 			Optional:    true,
@@ -199,6 +218,33 @@ func SchemaMap() map[string]*schema.Schema {
 			Description: descriptions[globalvar.DefinedTagsToIgnore],
 			MaxItems:    100,
 		},
+		globalvar.FreeformTagsToIgnore: {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: descriptions[globalvar.FreeformTagsToIgnore],
+			MaxItems:    100,
+		},
+		globalvar.DefaultTagsAttrName: {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: descriptions[globalvar.DefaultTagsAttrName],
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"freeform_tags": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"defined_tags": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -227,9 +273,17 @@ func ResourcesMap() map[string]*schema.Resource {
 
 func ProviderConfig(d *schema.ResourceData) (interface{}, error) {
 	tf_resource.DefinedTagsToSuppress = IgnoreDefinedTags(d)
+	defaultFreeformTags, defaultDefinedTags, err := DefaultTags(d)
+	if err != nil {
+		return nil, err
+	}
 	clients := &tf_client.OracleClients{
-		SdkClientMap:  make(map[string]interface{}, len(tf_client.OracleClientRegistrationsVar.RegisteredClients)),
-		Configuration: make(map[string]string),
+		SdkClientMap:        make(map[string]interface{}, len(tf_client.OracleClientRegistrationsVar.RegisteredClients)),
+		Configuration:       make(map[string]string),
+		DefaultFreeformTags: defaultFreeformTags,
+		DefaultDefinedTags:  defaultDefinedTags,
+		IgnoreFreeformTags:  IgnoreFreeformTags(d),
+		IgnoreDefinedTags:   IgnoreDefinedTags(d),
 	}
 
 	if d.Get(globalvar.DisableAutoRetriesAttrName).(bool) {
@@ -301,6 +355,11 @@ func GetSdkConfigProvider(d *schema.ResourceData, clients *tf_client.OracleClien
 	if err != nil {
 		return nil, err
 	}
+	for _, configProvider := range configProviders {
+		if credentialProcess, ok := configProvider.(*credentialProcessConfigProvider); ok {
+			sdkConfigProvider = credentialProcessComposingConfigProvider{ConfigurationProvider: sdkConfigProvider, credentialProcess: credentialProcess}
+		}
+	}
 
 	return sdkConfigProvider, nil
 }
@@ -422,20 +481,72 @@ func getConfigProviders(d *schema.ResourceData, auth string) ([]oci_common.Confi
 		if err != nil || !strings.HasPrefix(keyId, "ST$") {
 			return nil, fmt.Errorf("Security token is invalid ")
 		}
-		configProviders = append(configProviders, securityTokenBasedAuthConfigProvider)
+		if refresh, ok := d.GetOk(globalvar.SessionTokenRefreshAttrName); ok && refresh.(bool) {
+			configProviders = append(configProviders, newSessionTokenRefreshConfigProvider(securityTokenBasedAuthConfigProvider, region.(string), BuildHttpClient()))
+		} else {
+			configProviders = append(configProviders, securityTokenBasedAuthConfigProvider)
+		}
 	case strings.ToLower(globalvar.ResourcePrincipal):
 		resourcePrincipalAuthConfigProvider, err := oci_common_auth.ResourcePrincipalConfigurationProvider()
 		if err != nil {
 			return nil, err
 		}
 		configProviders = append(configProviders, resourcePrincipalAuthConfigProvider)
+	case strings.ToLower(globalvar.AuthCredentialProcess):
+		_, ok := utils.CheckIncompatibleAttrsForApiKeyAuth(d, ApiKeyConfigAttributes)
+		if !ok {
+			log.Printf("[DEBUG] Ignoring all user credentials for %v authentication", auth)
+		}
+
+		command, ok := d.GetOk(globalvar.CredentialProcessAttrName)
+		if !ok {
+			return nil, fmt.Errorf("can not get %s from Terraform configuration (CredentialProcess)", globalvar.CredentialProcessAttrName)
+		}
+		credentialProcessConfigProvider := newCredentialProcessConfigProvider(command.(string))
+		// Run the command now, so that its errors are not swallowed by the ComposingConfigurationProvider
+		if _, err := credentialProcessConfigProvider.getCredentials(); err != nil {
+			return nil, err
+		}
+		configProviders = append(configProviders, credentialProcessConfigProvider)
+	case strings.ToLower(globalvar.AuthOkeWorkloadIdentity):
+		_, ok := utils.CheckIncompatibleAttrsForApiKeyAuth(d, ApiKeyConfigAttributes)
+		if !ok {
+			log.Printf("[DEBUG] Ignoring all user credentials for %v authentication", auth)
+		}
+
+		// The service account token of the pod is exchanged for a resource principal session token by the proxymux endpoint of the cluster
+		okeWorkloadIdentityConfigProvider, err := newOkeWorkloadIdentityConfigProvider()
+		if err != nil {
+			return nil, err
+		}
+		configProviders = append(configProviders, okeWorkloadIdentityConfigProvider)
 	default:
-		return nil, fmt.Errorf("auth must be one of '%s' or '%s' or '%s' or '%s' or '%s'", globalvar.AuthAPIKeySetting, globalvar.AuthInstancePrincipalSetting, globalvar.AuthInstancePrincipalWithCertsSetting, globalvar.AuthSecurityToken, globalvar.ResourcePrincipal)
+		return nil, fmt.Errorf("auth must be one of '%s' or '%s' or '%s' or '%s' or '%s' or '%s' or '%s'", globalvar.AuthAPIKeySetting, globalvar.AuthInstancePrincipalSetting, globalvar.AuthInstancePrincipalWithCertsSetting, globalvar.AuthSecurityToken, globalvar.ResourcePrincipal, globalvar.AuthCredentialProcess, globalvar.AuthOkeWorkloadIdentity)
 	}
 
 	return configProviders, nil
 }
 
+var okeWorkloadIdentityConfigurationProviderVar = oci_common_auth.OkeWorkloadIdentityConfigurationProvider
+
+/*
+newOkeWorkloadIdentityConfigProvider creates the OKE workload identity config provider, the SDK reads the version and the region of the resource principal from the environment
+They are not defaulted by the provider, as changing the environment of the process would also change it for the other provider configurations running concurrently
+*/
+func newOkeWorkloadIdentityConfigProvider() (oci_common.ConfigurationProvider, error) {
+	var missingEnv []string
+	for _, name := range []string{oci_common_auth.ResourcePrincipalVersionEnvVar, oci_common_auth.ResourcePrincipalRegionEnvVar} {
+		if _, ok := os.LookupEnv(name); !ok {
+			missingEnv = append(missingEnv, name)
+		}
+	}
+	if len(missingEnv) > 0 {
+		return nil, fmt.Errorf("%s must be set in the environment of the pod for auth '%s', e.g. %s=%s and %s=<region of the cluster>", strings.Join(missingEnv, " and "), globalvar.AuthOkeWorkloadIdentity,
+			oci_common_auth.ResourcePrincipalVersionEnvVar, oci_common_auth.ResourcePrincipalVersion2_2, oci_common_auth.ResourcePrincipalRegionEnvVar)
+	}
+	return okeWorkloadIdentityConfigurationProviderVar()
+}
+
 type ResourceDataConfigProvider struct {
 	D *schema.ResourceData
 }
@@ -485,7 +596,15 @@ func (p ResourceDataConfigProvider) Region() (string, error) {
 }
 
 func IgnoreDefinedTags(d schemaResourceData) []string {
-	if ignoreTags, ok := d.GetOkExists(globalvar.DefinedTagsToIgnore); ok {
+	return getIgnoredTags(d, globalvar.DefinedTagsToIgnore)
+}
+
+func IgnoreFreeformTags(d schemaResourceData) []string {
+	return getIgnoredTags(d, globalvar.FreeformTagsToIgnore)
+}
+
+func getIgnoredTags(d schemaResourceData, attrName string) []string {
+	if ignoreTags, ok := d.GetOkExists(attrName); ok {
 		var tags []string
 		for _, item := range ignoreTags.([]interface{}) {
 			tags = append(tags, item.(string))
@@ -495,6 +614,25 @@ func IgnoreDefinedTags(d schemaResourceData) []string {
 	return nil
 }
 
+// DefaultTags returns the freeform tags and the defined tags of the default_tags block
+func DefaultTags(d schemaResourceData) (map[string]interface{}, map[string]interface{}, error) {
+	defaultTags, ok := d.GetOkExists(globalvar.DefaultTagsAttrName)
+	if !ok {
+		return nil, nil, nil
+	}
+	tagsList, ok := defaultTags.([]interface{})
+	if !ok || len(tagsList) == 0 || tagsList[0] == nil {
+		return nil, nil, nil
+	}
+	tags := tagsList[0].(map[string]interface{})
+	freeformTags, _ := tags["freeform_tags"].(map[string]interface{})
+	definedTags, _ := tags["defined_tags"].(map[string]interface{})
+	if _, err := tf_resource.MapToDefinedTags(definedTags); err != nil {
+		return nil, nil, fmt.Errorf("invalid defined_tags in %s, the keys must be <namespace>.<key>: %v", globalvar.DefaultTagsAttrName, err)
+	}
+	return freeformTags, definedTags, nil
+}
+
 func (p ResourceDataConfigProvider) KeyID() (string, error) {
 	tenancy, err := p.TenancyOCID()
 	if err != nil {
@@ -566,13 +704,13 @@ func BuildConfigureClientFn(configProvider oci_common.ConfigurationProvider, htt
 
 	simulateDbForDbSystemUpgrade, _ := strconv.ParseBool(utils.GetEnvSettingWithDefault("simulate_db_db_system_upgrade", "false"))
 
-	requestSigner := oci_common.DefaultRequestSigner(configProvider)
+	requestSigner := buildRequestSigner(configProvider, oci_common.DefaultGenericHeaders())
 	var oboTokenProvider OboTokenProvider
 	oboTokenProvider = emptyOboTokenProvider{}
 	if useOboToken {
 		// Add Obo token to the default list and Update the signer
 		httpHeadersToSign := append(oci_common.DefaultGenericHeaders(), globalvar.RequestHeaderOpcOboToken)
-		requestSigner = oci_common.RequestSigner(configProvider, httpHeadersToSign, oci_common.DefaultBodyHeaders())
+		requestSigner = buildRequestSigner(configProvider, httpHeadersToSign)
 		oboTokenProvider = oboTokenProviderFromEnv{}
 	}
 
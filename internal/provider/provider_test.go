@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	oci_common "github.com/oracle/oci-go-sdk/v65/common"
+	oci_common_auth "github.com/oracle/oci-go-sdk/v65/common/auth"
 	oci_identity "github.com/oracle/oci-go-sdk/v65/identity"
 	"github.com/oracle/terraform-provider-oci/httpreplay"
 	tf_client "github.com/oracle/terraform-provider-oci/internal/client"
@@ -677,6 +679,63 @@ func TestUnitResourcePrincipal_basic(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUnitOkeWorkloadIdentity_basic(t *testing.T) {
+	t.Setenv(oci_common_auth.ResourcePrincipalVersionEnvVar, "")
+	os.Unsetenv(oci_common_auth.ResourcePrincipalVersionEnvVar)
+	t.Setenv(oci_common_auth.ResourcePrincipalRegionEnvVar, "")
+	os.Unsetenv(oci_common_auth.ResourcePrincipalRegionEnvVar)
+	t.Setenv(oci_common_auth.KubernetesServiceHostEnvVar, "10.96.0.1")
+	defer func() {
+		okeWorkloadIdentityConfigurationProviderVar = oci_common_auth.OkeWorkloadIdentityConfigurationProvider
+	}()
+
+	invocations := 0
+	okeWorkloadIdentityConfigurationProviderVar = func() (oci_common_auth.ConfigurationProviderWithClaimAccess, error) {
+		invocations++
+		return nil, fmt.Errorf("not in a pod")
+	}
+
+	r := &schema.Resource{
+		Schema: SchemaMap(),
+	}
+	d := r.Data(nil)
+	d.Set("auth", globalvar.AuthOkeWorkloadIdentity)
+	d.Set(globalvar.RegionAttrName, "us-phoenix-1")
+	clients := &tf_client.OracleClients{
+		SdkClientMap:  make(map[string]interface{}, len(tf_client.OracleClientRegistrationsVar.RegisteredClients)),
+		Configuration: make(map[string]string),
+	}
+
+	// the version and the region of the resource principal are not defaulted in the environment of the provider process
+	_, err := GetSdkConfigProvider(d, clients)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), oci_common_auth.ResourcePrincipalVersionEnvVar+" and "+oci_common_auth.ResourcePrincipalRegionEnvVar+" must be set")
+	assert.Equal(t, 0, invocations)
+	_, ok := os.LookupEnv(oci_common_auth.ResourcePrincipalVersionEnvVar)
+	assert.False(t, ok, "the environment of the provider process should not be changed")
+	_, ok = os.LookupEnv(oci_common_auth.ResourcePrincipalRegionEnvVar)
+	assert.False(t, ok, "the environment of the provider process should not be changed")
+
+	os.Setenv(oci_common_auth.ResourcePrincipalVersionEnvVar, oci_common_auth.ResourcePrincipalVersion2_2)
+	_, err = GetSdkConfigProvider(d, clients)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), oci_common_auth.ResourcePrincipalRegionEnvVar+" must be set")
+	assert.Equal(t, 0, invocations)
+
+	os.Setenv(oci_common_auth.ResourcePrincipalRegionEnvVar, "us-ashburn-1")
+	_, err = GetSdkConfigProvider(d, clients)
+	assert.EqualError(t, err, "not in a pod")
+	assert.Equal(t, 1, invocations)
+
+	okeWorkloadIdentityConfigurationProviderVar = oci_common_auth.OkeWorkloadIdentityConfigurationProvider
+	if _, err := os.Stat(oci_common_auth.KubernetesServiceAccountTokenPath); err == nil {
+		t.Skip("Run outside of a Kubernetes pod")
+	}
+	_, err = GetSdkConfigProvider(d, clients)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Kubernetes Service Account Token")
+}
+
 type mockResourceData struct {
 	state string
 }
@@ -720,6 +779,41 @@ func TestUnitIgnoreTags(t *testing.T) {
 
 }
 
+func TestUnitIgnoreFreeformTags(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, SchemaMap(), map[string]interface{}{
+		globalvar.FreeformTagsToIgnore: []interface{}{"CreatedBy*", "Department"},
+	})
+	assert.Equal(t, []string{"CreatedBy*", "Department"}, IgnoreFreeformTags(d))
+	assert.Nil(t, IgnoreDefinedTags(d))
+}
+
+func TestUnitDefaultTags(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, SchemaMap(), map[string]interface{}{
+		globalvar.DefaultTagsAttrName: []interface{}{map[string]interface{}{
+			"freeform_tags": map[string]interface{}{"CostCenter": "42"},
+			"defined_tags":  map[string]interface{}{"Operations.CostCenter": "42"},
+		}},
+	})
+	freeformTags, definedTags, err := DefaultTags(d)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"CostCenter": "42"}, freeformTags)
+	assert.Equal(t, map[string]interface{}{"Operations.CostCenter": "42"}, definedTags)
+
+	d = schema.TestResourceDataRaw(t, SchemaMap(), map[string]interface{}{})
+	freeformTags, definedTags, err = DefaultTags(d)
+	assert.NoError(t, err)
+	assert.Nil(t, freeformTags)
+	assert.Nil(t, definedTags)
+
+	d = schema.TestResourceDataRaw(t, SchemaMap(), map[string]interface{}{
+		globalvar.DefaultTagsAttrName: []interface{}{map[string]interface{}{
+			"defined_tags": map[string]interface{}{"CostCenter": "42"},
+		}},
+	})
+	_, _, err = DefaultTags(d)
+	assert.Error(t, err)
+}
+
 func TestUnit_RegisterResourceMap(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,191 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package provider
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	oci_common "github.com/oracle/oci-go-sdk/v65/common"
+)
+
+const (
+	// The session token is refreshed when it expires in less than this, session tokens are valid for an hour by default
+	sessionTokenRefreshWindow = 10 * time.Minute
+	// A failed refresh is retried after this, the current token is used meanwhile
+	sessionTokenRefreshBackoff = time.Minute
+	sessionTokenKeyIdPrefix    = "ST$"
+)
+
+/*
+sessionTokenRefreshConfigProvider refreshes the session token of a SecurityToken profile through the auth service before it expires,
+so that operations running longer than the lifetime of the session do not fail. The refreshed token is kept in memory, the token file of the profile is not updated.
+The token file is read again on every request, so that a token refreshed outside of the provider e.g. with `oci session refresh` is used if it expires later.
+*/
+type sessionTokenRefreshConfigProvider struct {
+	oci_common.ConfigurationProvider
+	refreshEndpoint    string
+	httpClient         *http.Client
+	mutex              sync.Mutex
+	fileToken          string // last token read from the token file
+	token              string
+	expiration         time.Time
+	refreshing         bool
+	lastRefreshFailure time.Time
+}
+
+func newSessionTokenRefreshConfigProvider(provider oci_common.ConfigurationProvider, region string, httpClient *http.Client) *sessionTokenRefreshConfigProvider {
+	return &sessionTokenRefreshConfigProvider{
+		ConfigurationProvider: provider,
+		refreshEndpoint:       fmt.Sprintf("https://%s/v1/authentication/refresh", oci_common.StringToRegion(region).Endpoint("auth")),
+		httpClient:            httpClient,
+	}
+}
+
+// sessionTokenKeyProvider signs the refresh request with the current session token and the session key
+type sessionTokenKeyProvider struct {
+	token    string
+	provider oci_common.KeyProvider
+}
+
+func (p sessionTokenKeyProvider) KeyID() (string, error) {
+	return sessionTokenKeyIdPrefix + p.token, nil
+}
+
+func (p sessionTokenKeyProvider) PrivateRSAKey() (*rsa.PrivateKey, error) {
+	return p.provider.PrivateRSAKey()
+}
+
+func (p *sessionTokenRefreshConfigProvider) KeyID() (string, error) {
+	token, refresh, err := p.getToken()
+	if err != nil || !refresh {
+		return token, err
+	}
+
+	// The token is refreshed without holding the lock, the other requests keep using the current token meanwhile
+	newToken, expiration, err := p.refresh(strings.TrimPrefix(token, sessionTokenKeyIdPrefix))
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.refreshing = false
+	if err != nil {
+		p.lastRefreshFailure = time.Now()
+		log.Printf("[WARN] unable to refresh the session token, it expires at %s, retrying in %s: %v", p.expiration.Format(time.RFC3339), sessionTokenRefreshBackoff, err)
+		return sessionTokenKeyIdPrefix + p.token, nil
+	}
+	// The token file may have a token expiring later by now
+	if expiration.After(p.expiration) {
+		p.token = newToken
+		p.expiration = expiration
+	}
+	p.lastRefreshFailure = time.Time{}
+	log.Printf("[DEBUG] refreshed the session token, it expires at %s", expiration.Format(time.RFC3339))
+	return sessionTokenKeyIdPrefix + p.token, nil
+}
+
+// getToken returns the key id with the current token, and whether the caller should refresh the token before using it
+func (p *sessionTokenRefreshConfigProvider) getToken() (string, bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	keyId, err := p.ConfigurationProvider.KeyID()
+	if err != nil {
+		if p.token == "" {
+			return "", false, err
+		}
+		log.Printf("[DEBUG] unable to read the session token file, using the current token: %v", err)
+	} else if fileToken := strings.TrimPrefix(keyId, sessionTokenKeyIdPrefix); fileToken != p.fileToken {
+		p.fileToken = fileToken
+		expiration, err := getSessionTokenExpiration(fileToken)
+		if err != nil {
+			log.Printf("[DEBUG] the session token will not be refreshed: %v", err)
+		} else if p.token == "" || expiration.After(p.expiration) {
+			p.token = fileToken
+			p.expiration = expiration
+			p.lastRefreshFailure = time.Time{}
+		}
+	}
+
+	if p.token == "" {
+		return sessionTokenKeyIdPrefix + p.fileToken, false, nil
+	}
+	if p.refreshing || time.Until(p.expiration) >= sessionTokenRefreshWindow || time.Since(p.lastRefreshFailure) < sessionTokenRefreshBackoff {
+		return sessionTokenKeyIdPrefix + p.token, false, nil
+	}
+	p.refreshing = true
+	return sessionTokenKeyIdPrefix + p.token, true, nil
+}
+
+// refresh exchanges the token for a new one through the auth service, the request is signed with the token and the session key
+func (p *sessionTokenRefreshConfigProvider) refresh(token string) (string, time.Time, error) {
+	body, err := json.Marshal(map[string]string{"currentToken": token})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	request, err := http.NewRequest(http.MethodPost, p.refreshEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	if err := oci_common.DefaultRequestSigner(sessionTokenKeyProvider{token: token, provider: p.ConfigurationProvider}).Sign(request); err != nil {
+		return "", time.Time{}, err
+	}
+
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer response.Body.Close()
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("the auth service returned %s: %s", response.Status, string(responseBody))
+	}
+
+	var refreshResponse struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(responseBody, &refreshResponse); err != nil {
+		return "", time.Time{}, fmt.Errorf("unable to parse the response of the auth service: %v", err)
+	}
+	expiration, err := getSessionTokenExpiration(refreshResponse.Token)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return refreshResponse.Token, expiration, nil
+}
+
+// getSessionTokenExpiration returns the expiration of the session token, from the exp claim of the JWT
+func getSessionTokenExpiration(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("the session token is not a valid JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to decode the session token: %v", err)
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse the claims of the session token: %v", err)
+	}
+	if claims.Exp == 0 {
+		return time.Time{}, fmt.Errorf("the session token has no expiration")
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package provider
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	oci_common "github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSessionTokenConfigProvider struct {
+	oci_common.ConfigurationProvider
+	token string
+}
+
+func (p *testSessionTokenConfigProvider) KeyID() (string, error) {
+	return sessionTokenKeyIdPrefix + p.token, nil
+}
+
+func getTestSessionToken(expiration time.Time) string {
+	encode := func(value string) string { return base64.RawURLEncoding.EncodeToString([]byte(value)) }
+	return fmt.Sprintf("%s.%s.signature", encode(`{"alg":"RS256"}`), encode(fmt.Sprintf(`{"sub":"user","exp":%d}`, expiration.Unix())))
+}
+
+func getTestSessionTokenRefreshConfigProvider(t *testing.T, token string, endpoint string) *sessionTokenRefreshConfigProvider {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate private key: %v", err)
+	}
+	privateKeyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	base := &testSessionTokenConfigProvider{
+		ConfigurationProvider: oci_common.NewRawConfigurationProvider("", "", "us-phoenix-1", "", string(privateKeyPem), nil),
+		token:                 token,
+	}
+	provider := newSessionTokenRefreshConfigProvider(base, "us-phoenix-1", http.DefaultClient)
+	provider.refreshEndpoint = endpoint
+	return provider
+}
+
+func TestUnitSessionTokenRefreshConfigProvider(t *testing.T) {
+	oldToken := getTestSessionToken(time.Now().Add(sessionTokenRefreshWindow / 2))
+	newToken := getTestSessionToken(time.Now().Add(time.Hour))
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Contains(t, r.Header.Get("Authorization"), fmt.Sprintf(`keyId="ST$%s"`, oldToken))
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, fmt.Sprintf(`{"currentToken": "%s"}`, oldToken), string(body))
+		json.NewEncoder(w).Encode(map[string]string{"token": newToken})
+	}))
+	defer server.Close()
+
+	// the token is refreshed when it is about to expire
+	provider := getTestSessionTokenRefreshConfigProvider(t, oldToken, server.URL)
+	keyId, err := provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+newToken, keyId)
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+newToken, keyId)
+	assert.Equal(t, 1, refreshes, "the refreshed token should be used until it is about to expire")
+
+	// the token is not refreshed when it is far from expiring
+	provider = getTestSessionTokenRefreshConfigProvider(t, newToken, server.URL)
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+newToken, keyId)
+	assert.Equal(t, 1, refreshes)
+}
+
+func TestUnitSessionTokenRefreshConfigProvider_refreshFailure(t *testing.T) {
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		http.Error(w, "NotAuthenticated", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	// the current token is used until it expires when the refresh fails
+	token := getTestSessionToken(time.Now().Add(time.Minute))
+	provider := getTestSessionTokenRefreshConfigProvider(t, token, server.URL)
+	keyId, err := provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+token, keyId)
+	assert.Equal(t, 1, refreshes)
+
+	// the failed refresh is not retried until the backoff has passed
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+token, keyId)
+	assert.Equal(t, 1, refreshes)
+	provider.lastRefreshFailure = time.Now().Add(-sessionTokenRefreshBackoff)
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+token, keyId)
+	assert.Equal(t, 2, refreshes)
+
+	// tokens which are not JWTs are used as is
+	provider = getTestSessionTokenRefreshConfigProvider(t, "token", server.URL)
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$token", keyId)
+}
+
+func TestUnitSessionTokenRefreshConfigProvider_tokenFile(t *testing.T) {
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		http.Error(w, "NotAuthenticated", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	token := getTestSessionToken(time.Now().Add(time.Minute))
+	provider := getTestSessionTokenRefreshConfigProvider(t, token, server.URL)
+	keyId, err := provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+token, keyId)
+	assert.Equal(t, 1, refreshes)
+
+	// a token refreshed in the token file e.g. with `oci session refresh` is used
+	fileToken := getTestSessionToken(time.Now().Add(time.Hour))
+	provider.ConfigurationProvider.(*testSessionTokenConfigProvider).token = fileToken
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+fileToken, keyId)
+	assert.Equal(t, 1, refreshes)
+
+	// an older token in the token file is not used
+	provider.ConfigurationProvider.(*testSessionTokenConfigProvider).token = token
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+fileToken, keyId)
+}
+
+func TestUnitGetSessionTokenExpiration(t *testing.T) {
+	expiration := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+	result, err := getSessionTokenExpiration(getTestSessionToken(expiration))
+	assert.NoError(t, err)
+	assert.Equal(t, expiration, result)
+
+	_, err = getSessionTokenExpiration("token")
+	assert.Error(t, err)
+	_, err = getSessionTokenExpiration("a.b.c")
+	assert.Error(t, err)
+}
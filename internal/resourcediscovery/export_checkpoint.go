@@ -0,0 +1,571 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+// checkpointInterpolationKey marks an interpolation in the attributes of a checkpointed resource
+const checkpointInterpolationKey = "__interpolation__"
+
+/*
+exportCheckpointVar persists the progress of the export command under the checkpoint directory of the output path
+It is only set for a single export, recursive and multi-region exports are not checkpointed
+*/
+var exportCheckpointVar *exportCheckpoint
+
+var resourceNameSuffixRegex = regexp.MustCompile(`^(.*)_(\d+)$`)
+
+/*
+exportCheckpoint records the progress of an export so that an interrupted or partially failed export can be resumed
+- the resources discovered by each step are written to <output_path>/.checkpoint/<step>.json once the step completes without errors
+- the manifest <output_path>/.checkpoint/checkpoint.json records the arguments of the export, the progress of each step and the failed imports
+- the temporary state files are kept with the checkpoint so that the resources already imported are not imported again
+The checkpoint has the attributes of the resources, including the sensitive ones, so it is only readable by the owner and it is added to the .gitignore of the output_path
+The checkpoint is removed once the export completes without errors
+*/
+type exportCheckpoint struct {
+	lock     sync.Mutex
+	dir      string
+	resume   bool
+	manifest *checkpointManifest
+	restored map[string]bool // steps restored from the checkpoint
+}
+
+type checkpointManifest struct {
+	CompartmentId   string                     `json:"compartment_id,omitempty"`
+	Services        []string                   `json:"services,omitempty"`
+	ExcludeServices []string                   `json:"exclude_services,omitempty"`
+	IDs             []string                   `json:"ids,omitempty"`
+	GenerateState   bool                       `json:"generate_state"`
+	Steps           map[string]*checkpointStep `json:"steps"`
+	FailedImports   []string                   `json:"failed_imports,omitempty"`
+}
+
+type checkpointStep struct {
+	Discovered           bool `json:"discovered"`
+	StateGenerated       bool `json:"state_generated"`
+	ConfigurationWritten bool `json:"configuration_written"`
+}
+
+type checkpointStepResources struct {
+	DiscoveredResources []*checkpointResource `json:"discovered_resources"`
+	OmittedResources    []*checkpointResource `json:"omitted_resources"`
+}
+
+type checkpointResource struct {
+	Id                         string                 `json:"id"`
+	ImportId                   string                 `json:"import_id,omitempty"`
+	TerraformClass             string                 `json:"terraform_class"`
+	TerraformName              string                 `json:"terraform_name"`
+	TerraformReferenceIdString string                 `json:"terraform_reference_id_string,omitempty"`
+	CompartmentId              string                 `json:"compartment_id,omitempty"`
+	OmitFromExport             bool                   `json:"omit_from_export,omitempty"`
+	Provider                   string                 `json:"provider,omitempty"`
+	SourceAttributes           map[string]interface{} `json:"source_attributes,omitempty"`
+	Parent                     *checkpointResource    `json:"parent,omitempty"` // only the identity of the parent is kept
+}
+
+/*
+openExportCheckpoint creates the checkpoint of the export in the output directory
+If resume is set, the checkpoint of the previous export is loaded and it is validated to be for the same arguments
+Otherwise any existing checkpoint is discarded, so that a later resume does not restore the progress of an older export
+*/
+func openExportCheckpoint(ctx *tf_export.ResourceDiscoveryContext) (*exportCheckpoint, error) {
+	checkpoint := &exportCheckpoint{
+		dir:      filepath.Join(*ctx.OutputDir, globalvar.CheckpointDir),
+		manifest: newCheckpointManifest(ctx),
+		restored: map[string]bool{},
+	}
+	// the checkpoint and the temporary state files kept with it are not part of the generated configuration
+	for _, dir := range []string{globalvar.CheckpointDir, "tmp"} {
+		if err := addToGitIgnore(*ctx.OutputDir, dir+"/"); err != nil {
+			return nil, fmt.Errorf("[ERROR] unable to add checkpoint to %s: %s", globalvar.GitIgnoreFile, err.Error())
+		}
+	}
+
+	if ctx.Resume {
+		content, err := ioutil.ReadFile(checkpoint.manifestFile())
+		if err == nil {
+			previous := &checkpointManifest{}
+			if err := json.Unmarshal(content, previous); err != nil {
+				return nil, fmt.Errorf("[ERROR] unable to read checkpoint %s: %s", checkpoint.manifestFile(), err.Error())
+			}
+			if !previous.isSameExport(checkpoint.manifest) {
+				return nil, fmt.Errorf("[ERROR] the checkpoint under %s was created by an export with different arguments, run the export without resume to start over", checkpoint.dir)
+			}
+			if previous.Steps == nil {
+				previous.Steps = map[string]*checkpointStep{}
+			}
+			checkpoint.manifest = previous
+			checkpoint.resume = true
+			utils.Logf("[INFO] resuming export from checkpoint %s", checkpoint.dir)
+			if len(previous.FailedImports) > 0 {
+				utils.Logf("[INFO] retrying %d resources which failed to import in the previous export", len(previous.FailedImports))
+			}
+			return checkpoint, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("[ERROR] unable to read checkpoint %s: %s", checkpoint.manifestFile(), err.Error())
+		}
+		utils.Logf("[INFO] no checkpoint found under %s, running the entire export", checkpoint.dir)
+	}
+
+	// a new export does not reuse any of the progress of a previous export
+	if err := os.RemoveAll(checkpoint.dir); err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to delete existing checkpoint %s: %s", checkpoint.dir, err.Error())
+	}
+	if err := os.MkdirAll(checkpoint.dir, 0700); err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to create checkpoint directory %s: %s", checkpoint.dir, err.Error())
+	}
+	return checkpoint, checkpoint.writeManifest()
+}
+
+func newCheckpointManifest(ctx *tf_export.ResourceDiscoveryContext) *checkpointManifest {
+	manifest := &checkpointManifest{
+		Services:        ctx.Services,
+		ExcludeServices: ctx.ExcludeServices,
+		IDs:             ctx.IDs,
+		GenerateState:   ctx.GenerateState,
+		Steps:           map[string]*checkpointStep{},
+	}
+	if ctx.CompartmentId != nil {
+		manifest.CompartmentId = *ctx.CompartmentId
+	}
+	return manifest
+}
+
+// isSameExport checks if both the manifests are for an export of the same resources
+func (manifest *checkpointManifest) isSameExport(other *checkpointManifest) bool {
+	return manifest.CompartmentId == other.CompartmentId &&
+		manifest.GenerateState == other.GenerateState &&
+		sameStrings(manifest.Services, other.Services) &&
+		sameStrings(manifest.ExcludeServices, other.ExcludeServices) &&
+		sameStrings(manifest.IDs, other.IDs)
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+func (c *exportCheckpoint) manifestFile() string {
+	return filepath.Join(c.dir, globalvar.CheckpointFile)
+}
+
+func (c *exportCheckpoint) stepFile(stepName string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s.json", stepName))
+}
+
+// isResuming checks if the export is resumed from a checkpoint
+func (c *exportCheckpoint) isResuming() bool {
+	return c != nil && c.resume
+}
+
+func (c *exportCheckpoint) getStep(stepName string) *checkpointStep {
+	if _, exists := c.manifest.Steps[stepName]; !exists {
+		c.manifest.Steps[stepName] = &checkpointStep{}
+	}
+	return c.manifest.Steps[stepName]
+}
+
+// writeManifest writes the manifest of the checkpoint, the caller is expected to hold the lock if the steps are running
+func (c *exportCheckpoint) writeManifest() error {
+	content, err := json.MarshalIndent(c.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := fmt.Sprintf("%s.tmp", c.manifestFile())
+	if err := ioutil.WriteFile(tmpFile, content, 0600); err != nil {
+		return fmt.Errorf("[ERROR] unable to write checkpoint %s: %s", c.manifestFile(), err.Error())
+	}
+	return os.Rename(tmpFile, c.manifestFile())
+}
+
+/*
+restoreSteps restores the resources of the steps which were discovered by the previous export
+The names of the restored resources are registered before any other step is discovered, so that the new resources do not reuse them
+*/
+func (c *exportCheckpoint) restoreSteps(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep) {
+	if !c.isResuming() {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var restoredNames []string
+	for _, step := range steps {
+		baseStep := step.getBaseStep()
+		if stepProgress, exists := c.manifest.Steps[baseStep.name]; !exists || !stepProgress.Discovered {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(c.stepFile(baseStep.name))
+		if err != nil {
+			utils.Logf("[WARN] unable to read checkpoint of step '%s', discovering it again: %s", baseStep.name, err.Error())
+			continue
+		}
+		stepResources := &checkpointStepResources{}
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err := decoder.Decode(stepResources); err != nil {
+			utils.Logf("[WARN] unable to parse checkpoint of step '%s', discovering it again: %s", baseStep.name, err.Error())
+			continue
+		}
+
+		baseStep.discoveredResources = restoreCheckpointResources(ctx, stepResources.DiscoveredResources)
+		baseStep.omittedResources = restoreCheckpointResources(ctx, stepResources.OmittedResources)
+		for _, resource := range baseStep.discoveredResources {
+			tf_export.RefMapLock.Lock()
+			tf_export.ReferenceMap[resource.Id] = resource.GetHclReferenceIdString()
+			tf_export.RefMapLock.Unlock()
+			restoredNames = append(restoredNames, resource.TerraformName)
+		}
+		for _, resource := range baseStep.omittedResources {
+			restoredNames = append(restoredNames, resource.TerraformName)
+		}
+
+		c.restored[baseStep.name] = true
+		utils.Logf("[INFO] restored %d resources of step '%s' from checkpoint", len(baseStep.discoveredResources), baseStep.name)
+	}
+	registerResourceNames(restoredNames)
+}
+
+// isRestored checks if the resources of the step were restored from the checkpoint
+func (c *exportCheckpoint) isRestored(stepName string) bool {
+	if c == nil {
+		return false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.restored[stepName]
+}
+
+// saveStep writes the resources discovered by the step to the checkpoint, steps with discovery errors are discovered again on resume
+func (c *exportCheckpoint) saveStep(step resourceDiscoveryStep) {
+	if c == nil {
+		return
+	}
+	baseStep := step.getBaseStep()
+	if hasStepDiscoveryErrors(step) {
+		utils.Debugf("[DEBUG] step '%s' is not checkpointed as some of its resources could not be discovered", baseStep.name)
+		return
+	}
+
+	stepResources := &checkpointStepResources{
+		DiscoveredResources: newCheckpointResources(step.getDiscoveredResources()),
+		OmittedResources:    newCheckpointResources(step.getOmittedResources()),
+	}
+	content, err := json.Marshal(stepResources)
+	if err != nil {
+		utils.Logf("[WARN] unable to checkpoint step '%s': %s", baseStep.name, err.Error())
+		return
+	}
+	if err := ioutil.WriteFile(c.stepFile(baseStep.name), content, 0600); err != nil {
+		utils.Logf("[WARN] unable to checkpoint step '%s': %s", baseStep.name, err.Error())
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.getStep(baseStep.name).Discovered = true
+	if err := c.writeManifest(); err != nil {
+		utils.Logf("[WARN] unable to checkpoint step '%s': %s", baseStep.name, err.Error())
+	}
+}
+
+// saveImports records the steps whose state was generated and the resources which failed to import
+func (c *exportCheckpoint) saveImports(steps []resourceDiscoveryStep) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.manifest.FailedImports = nil
+	for _, step := range steps {
+		failed := false
+		for _, resource := range step.getDiscoveredResources() {
+			if resource.IsErrorResource {
+				c.manifest.FailedImports = append(c.manifest.FailedImports, resource.GetTerraformReference())
+				failed = true
+			}
+		}
+		c.getStep(step.getBaseStep().name).StateGenerated = !failed
+	}
+	if err := c.writeManifest(); err != nil {
+		utils.Logf("[WARN] unable to checkpoint imports: %s", err.Error())
+	}
+}
+
+// saveConfiguration records the steps whose configuration was written
+func (c *exportCheckpoint) saveConfiguration(steps []resourceDiscoveryStep) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, step := range steps {
+		c.getStep(step.getBaseStep().name).ConfigurationWritten = true
+	}
+	if err := c.writeManifest(); err != nil {
+		utils.Logf("[WARN] unable to checkpoint configuration: %s", err.Error())
+	}
+}
+
+/*
+complete removes the checkpoint and the temporary state files once the export completes without errors
+If there were errors, the checkpoint is kept so that the export can be resumed to retry the failed steps and imports
+*/
+func (c *exportCheckpoint) complete(ctx *tf_export.ResourceDiscoveryContext) {
+	if c == nil {
+		return
+	}
+	if len(ctx.ErrorList.Errors) > 0 {
+		ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Checkpoint saved under '%s', run the export again with resume to retry the resources which could not be discovered or imported", c.dir))
+		return
+	}
+	if err := os.RemoveAll(c.dir); err != nil {
+		utils.Logf("[WARN] unable to delete checkpoint %s: %s", c.dir, err.Error())
+	}
+	cleanupTempStateFiles(ctx)
+}
+
+// hasStepDiscoveryErrors checks if any of the resource types discovered by the step failed to be discovered
+func hasStepDiscoveryErrors(step resourceDiscoveryStep) bool {
+	ctx := step.getBaseStep().ctx
+	ctx.CtxLock.Lock()
+	defer ctx.CtxLock.Unlock()
+
+	graphStep, isGraphStep := step.(*resourceDiscoveryWithGraph)
+	for _, resourceDiscoveryError := range ctx.ErrorList.Errors {
+		// the errors of the other steps can only be told apart by the resource types of the resource graph
+		if !isGraphStep || isResourceTypeInGraph(resourceDiscoveryError.ResourceType, graphStep.resourceGraph) {
+			return true
+		}
+	}
+	return false
+}
+
+func isResourceTypeInGraph(resourceType string, resourceGraph tf_export.TerraformResourceGraph) bool {
+	for parentType, associations := range resourceGraph {
+		if parentType == resourceType {
+			return true
+		}
+		for _, association := range associations {
+			if association.ResourceClass == resourceType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+registerResourceNames registers the names of the restored resources so that CheckDuplicateResourceName does not generate them again
+A name with a numeric suffix e.g. `vcn_1` was generated as a duplicate of `vcn`, so the count of `vcn` is updated as well
+*/
+func registerResourceNames(names []string) {
+	tf_export.ResourceNameCountLock.Lock()
+	defer tf_export.ResourceNameCountLock.Unlock()
+
+	for _, name := range names {
+		if _, exists := tf_export.ResourceNameCount[name]; !exists {
+			tf_export.ResourceNameCount[name] = 1
+		}
+	}
+	for _, name := range names {
+		match := resourceNameSuffixRegex.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		count, exists := tf_export.ResourceNameCount[match[1]]
+		if !exists {
+			continue
+		}
+		if suffix, err := strconv.Atoi(match[2]); err == nil && suffix >= count {
+			tf_export.ResourceNameCount[match[1]] = suffix + 1
+		}
+	}
+}
+
+func newCheckpointResources(resources []*tf_export.OCIResource) []*checkpointResource {
+	result := make([]*checkpointResource, 0, len(resources))
+	for _, resource := range resources {
+		checkpointRes := newCheckpointResource(resource)
+		checkpointRes.SourceAttributes, _ = toCheckpointValue(resource.SourceAttributes).(map[string]interface{})
+		if resource.Parent != nil {
+			checkpointRes.Parent = newCheckpointResource(resource.Parent)
+		}
+		result = append(result, checkpointRes)
+	}
+	return result
+}
+
+func newCheckpointResource(resource *tf_export.OCIResource) *checkpointResource {
+	return &checkpointResource{
+		Id:                         resource.Id,
+		ImportId:                   resource.ImportId,
+		TerraformClass:             resource.TerraformClass,
+		TerraformName:              resource.TerraformName,
+		TerraformReferenceIdString: resource.TerraformReferenceIdString,
+		CompartmentId:              resource.CompartmentId,
+		OmitFromExport:             resource.OmitFromExport,
+		Provider:                   resource.Provider,
+	}
+}
+
+func restoreCheckpointResources(ctx *tf_export.ResourceDiscoveryContext, resources []*checkpointResource) []*tf_export.OCIResource {
+	result := make([]*tf_export.OCIResource, 0, len(resources))
+	for _, checkpointRes := range resources {
+		resource := restoreCheckpointResource(ctx, checkpointRes)
+		resource.SourceAttributes, _ = fromCheckpointValue(checkpointRes.SourceAttributes).(map[string]interface{})
+		if resource.SourceAttributes == nil {
+			resource.SourceAttributes = map[string]interface{}{}
+		}
+		if checkpointRes.Parent != nil {
+			resource.Parent = restoreCheckpointResource(ctx, checkpointRes.Parent)
+		}
+		result = append(result, resource)
+	}
+	return result
+}
+
+func restoreCheckpointResource(ctx *tf_export.ResourceDiscoveryContext, checkpointRes *checkpointResource) *tf_export.OCIResource {
+	resource := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{
+			Id:                         checkpointRes.Id,
+			ImportId:                   checkpointRes.ImportId,
+			TerraformClass:             checkpointRes.TerraformClass,
+			TerraformName:              checkpointRes.TerraformName,
+			TerraformReferenceIdString: checkpointRes.TerraformReferenceIdString,
+			OmitFromExport:             checkpointRes.OmitFromExport,
+		},
+		CompartmentId:  checkpointRes.CompartmentId,
+		Provider:       checkpointRes.Provider,
+		GetHclStringFn: tf_export.GetHclStringFromGenericMap,
+	}
+	if hints, err := ctx.GetResourceHint(checkpointRes.TerraformClass); err == nil {
+		resource.TerraformTypeInfo = hints
+		if hints.GetHCLStringOverrideFn != nil {
+			resource.GetHclStringFn = hints.GetHCLStringOverrideFn
+		}
+	}
+	return resource
+}
+
+// toCheckpointValue converts the interpolations in the attributes to maps so that they are restored as interpolations
+func toCheckpointValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case tf_export.InterpolationString:
+		return map[string]interface{}{checkpointInterpolationKey: v}
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, item := range v {
+			result[key] = toCheckpointValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = toCheckpointValue(item)
+		}
+		return result
+	}
+	return value
+}
+
+// fromCheckpointValue restores the interpolations and the numbers of the attributes read from a checkpoint
+func fromCheckpointValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if intValue, err := strconv.Atoi(v.String()); err == nil {
+			return intValue
+		}
+		floatValue, _ := v.Float64()
+		return floatValue
+	case map[string]interface{}:
+		if interpolation, ok := v[checkpointInterpolationKey].(map[string]interface{}); ok && len(v) == 1 {
+			result := tf_export.InterpolationString{}
+			result.ResourceReference, _ = interpolation["ResourceReference"].(string)
+			result.Interpolation, _ = interpolation["Interpolation"].(string)
+			result.Value, _ = interpolation["Value"].(string)
+			return result
+		}
+		result := map[string]interface{}{}
+		for key, item := range v {
+			result[key] = fromCheckpointValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = fromCheckpointValue(item)
+		}
+		return result
+	}
+	return value
+}
+
+// getImportedResources returns the addresses of the resources in the state files written by terraform import
+func getImportedResources(stateFiles ...string) map[string]bool {
+	imported := map[string]bool{}
+	for _, stateFile := range stateFiles {
+		content, err := ioutil.ReadFile(stateFile)
+		if err != nil {
+			continue
+		}
+		state := struct {
+			Resources []struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"resources"`
+		}{}
+		if err := json.Unmarshal(content, &state); err != nil {
+			utils.Logf("[WARN] unable to read the imported resources from %s: %s", stateFile, err.Error())
+			continue
+		}
+		for _, resource := range state.Resources {
+			imported[fmt.Sprintf("%s.%s", resource.Type, resource.Name)] = true
+		}
+	}
+	return imported
+}
+
+// getImportedResourcesInDir returns the addresses of the resources in the temporary state files of a step
+func getImportedResourcesInDir(tmpStateOutputDir string) map[string]bool {
+	files, err := ioutil.ReadDir(tmpStateOutputDir)
+	if err != nil {
+		return map[string]bool{}
+	}
+	var stateFiles []string
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".backup" {
+			stateFiles = append(stateFiles, filepath.Join(tmpStateOutputDir, file.Name()))
+		}
+	}
+	return getImportedResources(stateFiles...)
+}
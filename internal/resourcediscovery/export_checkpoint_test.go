@@ -0,0 +1,155 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitExportCheckpoint(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "export-checkpoint")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+
+	defer func(referenceMap map[string]string, resourceNameCount map[string]int) {
+		tf_export.ReferenceMap = referenceMap
+		tf_export.ResourceNameCount = resourceNameCount
+	}(tf_export.ReferenceMap, tf_export.ResourceNameCount)
+	tf_export.ReferenceMap = map[string]string{}
+	tf_export.ResourceNameCount = map[string]int{}
+
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	newCtx := func(resume bool) *tf_export.ResourceDiscoveryContext {
+		return &tf_export.ResourceDiscoveryContext{
+			ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, CompartmentId: &compartmentId, Services: []string{"core"}, GenerateState: true, Resume: resume},
+			ResourceHintsLookup: map[string]*tf_export.TerraformResourceHints{
+				"oci_core_vcn": {ResourceClass: "oci_core_vcn"},
+			},
+		}
+	}
+	newStep := func(ctx *tf_export.ResourceDiscoveryContext) *resourceDiscoveryWithGraph {
+		return &resourceDiscoveryWithGraph{
+			resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "core"},
+			resourceGraph:             tf_export.TerraformResourceGraph{"oci_identity_compartment": {{TerraformResourceHints: &tf_export.TerraformResourceHints{ResourceClass: "oci_core_vcn"}}}},
+		}
+	}
+
+	ctx := newCtx(false)
+	checkpoint, err := openExportCheckpoint(ctx)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(outputDir, globalvar.CheckpointDir, globalvar.CheckpointFile))
+	// the checkpoint has the attributes of the resources, including the sensitive ones
+	info, err := os.Stat(filepath.Join(outputDir, globalvar.CheckpointDir, globalvar.CheckpointFile))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	gitIgnore, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.GitIgnoreFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(gitIgnore), globalvar.CheckpointDir+"/\n")
+	assert.Contains(t, string(gitIgnore), "tmp/\n")
+
+	parent := &tf_export.OCIResource{TerraformResource: tf_export.TerraformResource{Id: compartmentId, TerraformClass: "oci_identity_compartment", TerraformName: "export"}}
+	attributes := map[string]interface{}{
+		"cidr_blocks":    []interface{}{"10.0.0.0/16"},
+		"display_name":   "vcn1",
+		"is_enabled":     true,
+		"size_in_gbs":    1000000,
+		"ratio":          0.5,
+		"nested":         []interface{}{map[string]interface{}{"count": 2}},
+		"backend_set_id": tf_export.InterpolationString{ResourceReference: "oci_load_balancer_backend_set.bs1", Interpolation: "${oci_load_balancer_backend_set.bs1.name}", Value: "bs1"},
+	}
+	step := newStep(ctx)
+	step.discoveredResources = []*tf_export.OCIResource{
+		{TerraformResource: tf_export.TerraformResource{Id: "ocid1.vcn.1", TerraformClass: "oci_core_vcn", TerraformName: "vcn1"}, SourceAttributes: attributes, Parent: parent, CompartmentId: compartmentId},
+		{TerraformResource: tf_export.TerraformResource{Id: "ocid1.vcn.2", TerraformClass: "oci_core_vcn", TerraformName: "vcn1_1"}, SourceAttributes: map[string]interface{}{}},
+	}
+	step.omittedResources = []*tf_export.OCIResource{
+		{TerraformResource: tf_export.TerraformResource{Id: "ocid1.vcn.3", TerraformClass: "oci_core_vcn", TerraformName: "vcn3", OmitFromExport: true}},
+	}
+	checkpoint.saveStep(step)
+	assert.True(t, checkpoint.manifest.Steps["core"].Discovered)
+
+	// the step is discovered again if some of its resources failed to be discovered
+	failedStep := newStep(ctx)
+	failedStep.name = "failed"
+	ctx.ErrorList.Errors = []*tf_export.ResourceDiscoveryError{{ResourceType: "oci_core_vcn", Error: errors.New("failed")}}
+	checkpoint.saveStep(failedStep)
+	assert.NotContains(t, checkpoint.manifest.Steps, "failed")
+	ctx.ErrorList.Errors = nil
+
+	// resume with different arguments
+	otherCtx := newCtx(true)
+	otherCtx.Services = []string{"identity"}
+	_, err = openExportCheckpoint(otherCtx)
+	assert.Error(t, err)
+
+	resumeCtx := newCtx(true)
+	resumed, err := openExportCheckpoint(resumeCtx)
+	assert.NoError(t, err)
+	assert.True(t, resumed.isResuming())
+
+	restoredStep := newStep(resumeCtx)
+	failedStep = newStep(resumeCtx)
+	failedStep.name = "failed"
+	resumed.restoreSteps(resumeCtx, []resourceDiscoveryStep{restoredStep, failedStep})
+	assert.True(t, resumed.isRestored("core"))
+	assert.False(t, resumed.isRestored("failed"))
+
+	if assert.Len(t, restoredStep.discoveredResources, 2) {
+		vcn := restoredStep.discoveredResources[0]
+		assert.Equal(t, "oci_core_vcn.vcn1", vcn.GetTerraformReference())
+		assert.Equal(t, attributes, vcn.SourceAttributes)
+		assert.Equal(t, compartmentId, vcn.Parent.Id)
+		assert.NotNil(t, vcn.TerraformTypeInfo)
+		assert.NotNil(t, vcn.GetHclStringFn)
+	}
+	assert.Len(t, restoredStep.omittedResources, 1)
+	assert.Equal(t, "oci_core_vcn.vcn1.id", tf_export.ReferenceMap["ocid1.vcn.1"])
+
+	// the restored names are not generated again
+	assert.Equal(t, "vcn1_2", tf_export.CheckDuplicateResourceName("vcn1"))
+	assert.Equal(t, "vcn3_1", tf_export.CheckDuplicateResourceName("vcn3"))
+
+	// an export run without resume discards the checkpoint, so that a later resume does not restore its progress
+	restarted, err := openExportCheckpoint(newCtx(false))
+	assert.NoError(t, err)
+	assert.False(t, restarted.isResuming())
+	assert.NoFileExists(t, checkpoint.stepFile("core"))
+	resumed, err = openExportCheckpoint(resumeCtx)
+	assert.NoError(t, err)
+	assert.Empty(t, resumed.manifest.Steps)
+
+	resumed.complete(resumeCtx)
+	assert.NoDirExists(t, filepath.Join(outputDir, globalvar.CheckpointDir))
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitGetImportedResourcesInDir(t *testing.T) {
+	tmpStateOutputDir, err := ioutil.TempDir("", "export-checkpoint")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpStateOutputDir)
+
+	state := `{"version": 4, "resources": [{"mode": "managed", "type": "oci_core_vcn", "name": "vcn1"}, {"mode": "data", "type": "oci_identity_availability_domain", "name": "ad1"}]}`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpStateOutputDir, globalvar.DefaultTmpStateFile+"0"), []byte(state), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpStateOutputDir, globalvar.DefaultTmpStateFile+"0.backup"), []byte(`{"resources": [{"type": "oci_core_subnet", "name": "subnet1"}]}`), 0644))
+
+	assert.Equal(t, map[string]bool{"oci_core_vcn.vcn1": true, "oci_identity_availability_domain.ad1": true}, getImportedResourcesInDir(tmpStateOutputDir))
+	assert.Empty(t, getImportedResourcesInDir(filepath.Join(tmpStateOutputDir, "missing")))
+}
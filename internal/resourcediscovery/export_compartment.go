@@ -13,6 +13,7 @@ import (
 	"runtime"
 	"runtime/debug"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -107,6 +108,7 @@ func elapsed(what string, step *resourceDiscoveryBaseStep, stage ResourceDiscove
 func init() {
 	tf_export.ResourceNameCount = map[string]int{}
 	tf_export.Vars = map[string]string{}
+	tf_export.SensitiveVars = map[string]string{}
 	tf_export.ReferenceMap = map[string]string{}
 	tf_export.VarsExportForResourceLevel = map[string][]string{}
 	tf_export.VarsExportForGlobalLevel = []string{}
@@ -161,7 +163,79 @@ func printResourceGraphResources(resourceGraphs map[string]tf_export.TerraformRe
 	return nil
 }
 
-func RunListExportableResourcesCommand() error {
+/*
+ExportResourceHint describes how a resource is discovered, it is written for each association of the resource graphs by list_export_resources
+The same resource class can have multiple entries if it is discovered from different parent resources
+*/
+type ExportResourceHint struct {
+	ResourceClass               string            `json:"resource_class"`
+	ResourceAbbreviation        string            `json:"resource_abbreviation"`
+	Service                     string            `json:"service"`
+	Scope                       string            `json:"scope"`
+	ParentResourceClass         string            `json:"parent_resource_class"`
+	DatasourceClass             string            `json:"datasource_class"`
+	DatasourceQueryParams       map[string]string `json:"datasource_query_params"`
+	DiscoverableLifecycleStates []string          `json:"discoverable_lifecycle_states"` // all lifecycle states are discovered if empty
+	RequireResourceRefresh      bool              `json:"require_resource_refresh"`
+	IsDataSource                bool              `json:"is_data_source"`
+	HasRelatedResources         bool              `json:"has_related_resources"`    // whether related resources are discovered with include_related_resources
+	RelatedResourceClasses      []string          `json:"related_resource_classes"` // the resources discovered with include_related_resources
+}
+
+// getExportResourceHints returns the hints of the associations in the resource graphs of a scope sorted by service, parent and resource class
+func getExportResourceHints(resourceGraphs map[string]tf_export.TerraformResourceGraph, scope string) []*ExportResourceHint {
+	hints := []*ExportResourceHint{}
+	for graphName, resourceGraph := range resourceGraphs {
+		for parentResourceClass, associations := range resourceGraph {
+			for _, association := range associations {
+				if association.TerraformResourceHints == nil {
+					continue
+				}
+				hint := &ExportResourceHint{
+					ResourceClass:               association.ResourceClass,
+					ResourceAbbreviation:        association.ResourceAbbreviation,
+					Service:                     graphName,
+					Scope:                       scope,
+					ParentResourceClass:         parentResourceClass,
+					DatasourceClass:             association.DatasourceClass,
+					DatasourceQueryParams:       association.DatasourceQueryParams,
+					DiscoverableLifecycleStates: association.DiscoverableLifecycleStates,
+					RequireResourceRefresh:      association.RequireResourceRefresh,
+					IsDataSource:                association.IsDataSource,
+					RelatedResourceClasses:      []string{},
+				}
+				if hint.DatasourceQueryParams == nil {
+					hint.DatasourceQueryParams = map[string]string{}
+				}
+				if hint.DiscoverableLifecycleStates == nil {
+					hint.DiscoverableLifecycleStates = []string{}
+				}
+				if relatedAssociations, hasRelatedResources := tf_export.ExportRelatedResourcesGraph[association.ResourceClass]; hasRelatedResources {
+					hint.HasRelatedResources = true
+					for _, relatedAssociation := range relatedAssociations {
+						if relatedAssociation.TerraformResourceHints != nil {
+							hint.RelatedResourceClasses = append(hint.RelatedResourceClasses, relatedAssociation.ResourceClass)
+						}
+					}
+				}
+				hints = append(hints, hint)
+			}
+		}
+	}
+
+	sort.SliceStable(hints, func(i, j int) bool {
+		if hints[i].Service != hints[j].Service {
+			return hints[i].Service < hints[j].Service
+		}
+		if hints[i].ParentResourceClass != hints[j].ParentResourceClass {
+			return hints[i].ParentResourceClass < hints[j].ParentResourceClass
+		}
+		return hints[i].ResourceClass < hints[j].ResourceClass
+	})
+	return hints
+}
+
+func RunListExportableResourcesCommand(listExportResourcesPath string) error {
 	tf_export.ResourcesMap = tf_provider.ResourcesMap()
 	tf_export.DatasourcesMap = tf_provider.DataSourcesMap()
 
@@ -174,6 +248,18 @@ func RunListExportableResourcesCommand() error {
 	if err := printResourceGraphResources(tf_export.CompartmentResourceGraphs, "compartment"); err != nil {
 		return err
 	}
+
+	if listExportResourcesPath != "" {
+		hints := append(getExportResourceHints(tf_export.TenancyResourceGraphs, TenancyScope), getExportResourceHints(tf_export.CompartmentResourceGraphs, CompartmentScope)...)
+		hintsJson, err := json.MarshalIndent(hints, "", "  ")
+		if err != nil {
+			return fmt.Errorf("[ERROR] Error marshalling resources to JSON: %v", err)
+		}
+		if err := ioutil.WriteFile(listExportResourcesPath, hintsJson, 0644); err != nil {
+			return err
+		}
+		utils.Logf("[INFO] Resources written to json file at: %s", listExportResourcesPath)
+	}
 	return nil
 }
 
@@ -223,6 +309,19 @@ func RunListExportableServicesCommand(listExportServicesPath string) error {
 }
 
 func RunExportCommand(args *tf_export.ExportCommandArgs) (err error, status Status) {
+	// deferred before the panic recovery so that the report is written with the recovered status
+	defer func() {
+		if exportReportVar == nil {
+			return
+		}
+		if reportErr := exportReportVar.write(args.ReportPath, status, err); reportErr != nil {
+			utils.Logln(reportErr.Error())
+			if err == nil {
+				err, status = reportErr, StatusFail
+			}
+		}
+		exportReportVar = nil
+	}()
 	defer func() {
 		if r := recover(); r != nil {
 			utils.Logf("[ERROR] panic in RunExportCommand, exiting with status %v", StatusFail)
@@ -238,8 +337,16 @@ func RunExportCommand(args *tf_export.ExportCommandArgs) (err error, status Stat
 		return err, StatusFail
 	}
 
+	if args.ReportPath != "" {
+		exportReportVar = newExportReport()
+	}
+
 	tf_export.TfHclVersionvar = *args.TFVersion
 
+	if args.FromSnapshot != "" {
+		return runExportFromSnapshot(args)
+	}
+
 	r := &schema.Resource{
 		Schema: tf_provider.SchemaMap(),
 	}
@@ -251,6 +358,12 @@ func RunExportCommand(args *tf_export.ExportCommandArgs) (err error, status Stat
 		return err, StatusFail
 	}
 
+	if args.RateLimit > 0 {
+		// the clients are limited up to the most requests the steps can make concurrently
+		exportRateLimiterVar = newExportRateLimiter(args.RateLimit, args.Parallelism*runtime.NumCPU()*4)
+		defer func() { exportRateLimiterVar = nil }()
+	}
+
 	clients, err := getExportConfigVar(d)
 	if err != nil {
 		utils.Logln(err.Error())
@@ -321,6 +434,25 @@ func RunExportCommand(args *tf_export.ExportCommandArgs) (err error, status Stat
 
 	utils.Logf("[INFO] resource discovery retry timeout duration set to %v", tfresource.ShortRetryTime)
 
+	if args.Snapshot {
+		if err := runSnapshotCommand(ctx); err != nil {
+			utils.Logln(err.Error())
+			return err, StatusFail
+		}
+		if len(ctx.ErrorList.Errors) > 0 {
+			return getListOfNotDiscoveredResources(ctx)
+		}
+		return nil, StatusSuccess
+	}
+
+	if args.Recursive {
+		return runRecursiveExportCommand(ctx)
+	}
+
+	if len(args.Regions) > 0 {
+		return runMultiRegionExportCommand(d, ctx)
+	}
+
 	if err := runExportCommand(ctx); err != nil {
 		utils.Logln(err.Error())
 		return err, StatusFail
@@ -381,6 +513,9 @@ func getExportConfig(d *schema.ResourceData) (interface{}, error) {
 			return err
 		}
 		client.UserAgent = userAgentString
+		if exportRateLimiterVar != nil {
+			client.HTTPClient = exportRateLimiterVar.wrap(client.HTTPClient)
+		}
 		return nil
 	}
 	// beware: global variable `configureClient` set here--used elsewhere outside this execution path
@@ -399,11 +534,115 @@ func runExportCommand(ctx *tf_export.ResourceDiscoveryContext) error {
 	defer ctx.PrintSummary()
 	exportStart := time.Now()
 	defer elapsed("entire export command", nil, 0)()
-	steps, err := getDiscoverResourceSteps(ctx)
+
+	var steps []resourceDiscoveryStep
+	var err error
+	if exportSnapshotVar != nil {
+		// the resources are restored from the snapshot instead of being discovered, the export is not checkpointed
+		if steps, err = exportSnapshotVar.restoreSteps(ctx); err != nil {
+			return err
+		}
+	} else {
+		checkpoint, err := openExportCheckpoint(ctx)
+		if err != nil {
+			return err
+		}
+		exportCheckpointVar = checkpoint
+		defer func() { exportCheckpointVar = nil }()
+
+		if steps, err = discoverResources(ctx); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Incremental {
+		if err := applyIncrementalExport(ctx, steps); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Layout == tf_export.LayoutModules {
+		if err := generateServiceModules(ctx, steps); err != nil {
+			return err
+		}
+		addMissingRequiredAttributesSummary(ctx)
+		addSchemaViolationsSummary(ctx)
+		addRateLimiterSummary(ctx)
+		exportCheckpointVar.complete(ctx)
+		ctx.TimeTakenForEntireExport = time.Since(exportStart)
+		ctx.PostValidate()
+		return nil
+	}
+
+	var externalReferences []*externalReference
+	if ctx.ResolveExternalReferences {
+		externalReferences = resolveExternalReferences(ctx, steps, nil)
+	}
+
+	if err := generateConfiguration(ctx, steps); err != nil {
+		return err
+	}
+
+	region, err := getExportRegion()
 	if err != nil {
 		return err
 	}
+	tf_export.Vars["region"] = fmt.Sprintf("\"%s\"", region)
+
+	if err := generateProviderFile(ctx.OutputDir); err != nil {
+		return err
+	}
+
+	if err := generateVarsFile(tf_export.Vars, tf_export.SensitiveVars, ctx.OutputDir); err != nil {
+		return err
+	}
+
+	if err := generateSecretsFile(ctx, tf_export.SensitiveVars); err != nil {
+		return err
+	}
+
+	if err := generateExternalReferencesFile(ctx, externalReferences); err != nil {
+		return err
+	}
+
+	if ctx.GenerateImportBlocks {
+		if err := generateImportsFile(ctx); err != nil {
+			return err
+		}
+	}
+
+	if ctx.GenerateGraph {
+		if err := generateDependencyGraphFiles(ctx); err != nil {
+			return err
+		}
+	}
+
+	addMissingRequiredAttributesSummary(ctx)
+	addSchemaViolationsSummary(ctx)
+	addRateLimiterSummary(ctx)
+	exportCheckpointVar.complete(ctx)
+	ctx.TimeTakenForEntireExport = time.Since(exportStart)
+	ctx.PostValidate()
+	return nil
+}
+
+// getExportRegion returns the region of the provider configuration, or the region of the snapshot if the resources are exported from a snapshot
+func getExportRegion() (string, error) {
+	if exportSnapshotVar != nil {
+		return exportSnapshotVar.Region, nil
+	}
+	return exportConfigProvider.Region()
+}
+
+// discoverResources runs the discovery for all the steps of the export
+func discoverResources(ctx *tf_export.ResourceDiscoveryContext) ([]resourceDiscoveryStep, error) {
+	steps, err := getDiscoverResourceSteps(ctx)
+	if err != nil {
+		return nil, err
+	}
 	discoveryStart := time.Now()
+	// the steps completed by a previous export are restored from the checkpoint instead of being discovered again
+	exportCheckpointVar.restoreSteps(ctx, steps)
 	var discoverWg sync.WaitGroup
 	discoverWg.Add(len(steps))
 	for i, step := range steps {
@@ -424,12 +663,15 @@ func runExportCommand(ctx *tf_export.ResourceDiscoveryContext) error {
 				discoverWg.Done()
 			}()
 
-			err := step.discover()
-			if err != nil {
-				// All errors in discover are added to the ctx.errorList
-				utils.Debugf("[ERROR] error occurred while discovering resources for step %d", i)
-				utils.Logf("[ERROR] error occurred while discovering resources: %s", err.Error())
-				return
+			isRestored := exportCheckpointVar.isRestored(step.getBaseStep().name)
+			if !isRestored {
+				err := step.discover()
+				if err != nil {
+					// All errors in discover are added to the ctx.errorList
+					utils.Debugf("[ERROR] error occurred while discovering resources for step %d", i)
+					utils.Logf("[ERROR] error occurred while discovering resources: %s", err.Error())
+					return
+				}
 			}
 			// Cull any references from the ref map that contain omitted resources
 			// This is to avoid omitted resources from being referenced in generated configs
@@ -444,6 +686,10 @@ func runExportCommand(ctx *tf_export.ResourceDiscoveryContext) error {
 				}
 			}
 
+			if !isRestored {
+				exportCheckpointVar.saveStep(step)
+			}
+
 			utils.Debugf("[DEBUG] discover: Completed step %d", i)
 			utils.Debugf("[DEBUG] discovered %d resources for step %d", len(step.getDiscoveredResources()), i)
 		}(i, step)
@@ -455,12 +701,22 @@ func runExportCommand(ctx *tf_export.ResourceDiscoveryContext) error {
 	totalDiscoveryTime := time.Since(discoveryStart)
 	utils.Debugf("discovering resources for all services took %v\n", totalDiscoveryTime)
 	ctx.TimeTakenToDiscover = totalDiscoveryTime
+	exportReportVar.addDiscovery(ctx, steps)
 	utils.Debug("[DEBUG] ~~~~~~ discover steps completed ~~~~~~")
+	return steps, nil
+}
 
+// generateConfiguration generates the state, if requested, and writes the configuration for the discovered resources
+func generateConfiguration(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep) error {
 	if ctx.GenerateState {
 		stateStart := time.Now()
 		// Run import commands
-		if ctx.Parallelism > 1 {
+		if ctx.NativeState {
+			utils.Debug("[DEBUG] Generating state from the discovered resources")
+			if err := generateNativeState(ctx, steps); err != nil {
+				return err
+			}
+		} else if ctx.Parallelism > 1 {
 			utils.Debug("[DEBUG] Generating state in parallel")
 			if err := generateStateParallel(ctx, steps); err != nil {
 				return err
@@ -480,6 +736,13 @@ func runExportCommand(ctx *tf_export.ResourceDiscoveryContext) error {
 		timeForStateGeneration := time.Since(stateStart)
 		utils.Debugf("[DEBUG] state generation took %v\n", timeForStateGeneration)
 		ctx.TimeTakenToGenerateState = timeForStateGeneration
+		exportCheckpointVar.saveImports(steps)
+	}
+
+	if ctx.GroupResources {
+		if err := groupResources(ctx, steps); err != nil {
+			return err
+		}
 	}
 
 	// Reset discovered resources if already set by writeTmpConfigurationForImport
@@ -542,21 +805,11 @@ func runExportCommand(ctx *tf_export.ResourceDiscoveryContext) error {
 		utils.Logf("[ERROR] error writing final configuration for resources found: %s", errs.Error())
 		return errs
 	}
+	exportCheckpointVar.saveConfiguration(steps)
+	return nil
+}
 
-	region, err := exportConfigProvider.Region()
-	if err != nil {
-		return err
-	}
-	tf_export.Vars["region"] = fmt.Sprintf("\"%s\"", region)
-
-	if err := generateProviderFile(ctx.OutputDir); err != nil {
-		return err
-	}
-
-	if err := generateVarsFile(tf_export.Vars, ctx.OutputDir); err != nil {
-		return err
-	}
-
+func addMissingRequiredAttributesSummary(ctx *tf_export.ResourceDiscoveryContext) {
 	if tf_export.IsMissingRequiredAttributes {
 		ctx.SummaryStatements = append(ctx.SummaryStatements, "")
 		ctx.SummaryStatements = append(ctx.SummaryStatements, globalvar.MissingRequiredAttributeWarning)
@@ -565,9 +818,34 @@ func runExportCommand(ctx *tf_export.ResourceDiscoveryContext) error {
 			ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("%s: %s", key, strings.Join(value, ",")))
 		}
 	}
-	ctx.TimeTakenForEntireExport = time.Since(exportStart)
-	ctx.PostValidate()
-	return nil
+}
+
+// addSchemaViolationsSummary lists the attributes of the exported resources which did not match the resource schema, fixed or to be fixed before running terraform plan
+func addSchemaViolationsSummary(ctx *tf_export.ResourceDiscoveryContext) {
+	var fixedViolations, violations []string
+	for _, resource := range ctx.DiscoveredResources {
+		for _, violation := range resource.SchemaViolations {
+			statement := fmt.Sprintf("%s: %s", resource.GetTerraformReference(), violation.String())
+			if violation.Fixed {
+				fixedViolations = append(fixedViolations, statement)
+			} else {
+				violations = append(violations, statement)
+			}
+		}
+	}
+	sort.Strings(fixedViolations)
+	sort.Strings(violations)
+
+	if len(fixedViolations) > 0 {
+		ctx.SummaryStatements = append(ctx.SummaryStatements, "")
+		ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Fixed %d attributes which did not match the resource schema:", len(fixedViolations)))
+		ctx.SummaryStatements = append(ctx.SummaryStatements, fixedViolations...)
+	}
+	if len(violations) > 0 {
+		ctx.SummaryStatements = append(ctx.SummaryStatements, "")
+		ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Warning: %d attributes do not match the resource schema and may fail terraform plan, update them in the configuration:", len(violations)))
+		ctx.SummaryStatements = append(ctx.SummaryStatements, violations...)
+	}
 }
 
 /*
@@ -582,7 +860,10 @@ func generateStateParallel(ctx *tf_export.ResourceDiscoveryContext, steps []reso
 	utils.Debugf("[DEBUG] Reset isInitDone")
 	isInitDone = false
 	// Cleanup the temporary state files created for each input service
-	defer cleanupTempStateFiles(ctx)
+	// The files are kept with the checkpoint until the export completes, so that the imported resources are not imported again on resume
+	if exportCheckpointVar == nil {
+		defer cleanupTempStateFiles(ctx)
+	}
 	defer elapsed("generating state in parallel", nil, 0)()
 
 	/*
@@ -745,13 +1026,33 @@ func generateState(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDisc
 
 	stateOutputFile := fmt.Sprintf("%s%s%s", *ctx.OutputDir, string(os.PathSeparator), globalvar.DefaultStateFilename)
 	tmpStateOutputFile := fmt.Sprintf("%s%s%s", *ctx.OutputDir, string(os.PathSeparator), globalvar.DefaultTmpStateFile)
-	if err := os.RemoveAll(tmpStateOutputFile); err != nil {
+	importedResources := map[string]bool{}
+	if exportCheckpointVar.isResuming() {
+		// the state of the previous export is imported into again, the resources already in it are not imported again
+		if _, err := os.Stat(tmpStateOutputFile); os.IsNotExist(err) {
+			if _, err := os.Stat(stateOutputFile); err == nil {
+				if err := os.Rename(stateOutputFile, tmpStateOutputFile); err != nil {
+					return err
+				}
+			}
+		}
+		importedResources = getImportedResources(tmpStateOutputFile)
+	} else if err := os.RemoveAll(tmpStateOutputFile); err != nil {
 		utils.Logf("[WARN] unable to delete existing tmp state file %s", tmpStateOutputFile)
 		return err
+	} else if ctx.Incremental {
+		// the new resources are imported to the state of the previous export
+		if err := copyFile(stateOutputFile, tmpStateOutputFile); err != nil {
+			return err
+		}
 	}
 
 	// Run import for all resources
 	for _, resource := range ctx.DiscoveredResources {
+		if importedResources[resource.GetTerraformReference()] {
+			utils.Debugf("[DEBUG] skip importing '%s' since it was imported by the previous export", resource.GetTerraformReference())
+			continue
+		}
 		importResource(ctx, resource, tmpStateOutputFile)
 	}
 
@@ -783,10 +1084,7 @@ func importResource(ctx *tf_export.ResourceDiscoveryContext, resource *tf_export
 		return
 	}
 
-	importId := resource.ImportId
-	if len(importId) == 0 {
-		importId = resource.Id
-	}
+	importId := getImportId(resource)
 
 	importArgs := []tfexec.ImportOption{
 		tfexecConfigVar(*ctx.OutputDir),
@@ -795,33 +1093,35 @@ func importResource(ctx *tf_export.ResourceDiscoveryContext, resource *tf_export
 	if importErr := ctxTerraformImportVar(ctx, context.Background(), resource.GetTerraformReference(), importId, importArgs...); importErr != nil {
 		utils.Logf("[ERROR] terraform import command failed for resource '%s' at id '%s': %s", resource.GetTerraformReference(), importId, importErr.Error())
 
-		// mark resource as errored so that it can be skipped while writing configurations
-		resource.IsErrorResource = true
-
-		ctx.CtxLock.Lock()
-		ctx.IsImportError = true
-		ctx.CtxLock.Unlock()
-
 		err := fmt.Errorf("[ERROR] terraform import command failed for resource '%s' at id '%s': %s Any references to this resource have been replaced with hard coded values in generated configurations", resource.GetTerraformReference(), importId, importErr.Error())
+		addImportError(ctx, resource, err)
+	}
+}
 
-		var rdError *tf_export.ResourceDiscoveryError
-		if ctx.TargetSpecificResources {
-			rdError = &tf_export.ResourceDiscoveryError{
-				ResourceType:   resource.TerraformClass,
-				ParentResource: "",
-				Error:          err,
-				ResourceGraph:  nil}
-		} else {
-			rdError = &tf_export.ResourceDiscoveryError{
-				ResourceType:   resource.TerraformClass,
-				ParentResource: resource.Parent.TerraformName,
-				Error:          err,
-				ResourceGraph:  nil,
-			}
+// addImportError marks a resource which could not be added to the state as errored so that it can be skipped while writing configurations
+func addImportError(ctx *tf_export.ResourceDiscoveryContext, resource *tf_export.OCIResource, err error) {
+	resource.IsErrorResource = true
+
+	ctx.CtxLock.Lock()
+	ctx.IsImportError = true
+	ctx.CtxLock.Unlock()
+
+	var rdError *tf_export.ResourceDiscoveryError
+	if ctx.TargetSpecificResources {
+		rdError = &tf_export.ResourceDiscoveryError{
+			ResourceType:   resource.TerraformClass,
+			ParentResource: "",
+			Error:          err,
+			ResourceGraph:  nil}
+	} else {
+		rdError = &tf_export.ResourceDiscoveryError{
+			ResourceType:   resource.TerraformClass,
+			ParentResource: resource.Parent.TerraformName,
+			Error:          err,
+			ResourceGraph:  nil,
 		}
-		ctx.AddErrorToList(rdError)
-
 	}
+	ctx.AddErrorToList(rdError)
 }
 
 func getDiscoverResourceSteps(ctx *tf_export.ResourceDiscoveryContext) ([]resourceDiscoveryStep, error) {
@@ -1056,21 +1356,43 @@ func findResources(ctx *tf_export.ResourceDiscoveryContext, root *tf_export.OCIR
 	return foundResources, nil
 }
 
-func generateVarsFile(vars map[string]string, outputDir *string) error {
-	varsTmpFile := fmt.Sprintf("%s%s%s.tmp", *outputDir, string(os.PathSeparator), globalvar.VarsFile)
-	varsOutputFile := fmt.Sprintf("%s%s%s", *outputDir, string(os.PathSeparator), globalvar.VarsFile)
+func generateVarsFile(vars map[string]string, sensitiveVars map[string]string, outputDir *string) error {
+	varsOutputFile := fmt.Sprintf("%s%s%s", *outputDir, string(os.PathSeparator), tf_export.GetConfigFileName(globalvar.VarsFile))
+	varsTmpFile := fmt.Sprintf("%s.tmp", varsOutputFile)
 	file, err := os.OpenFile(varsTmpFile, os.O_CREATE|os.O_RDWR, 0666)
 	if err != nil {
 		return err
 	}
 
+	builder := &strings.Builder{}
 	for variable, defaultVal := range vars {
 		if defaultVal != "" {
-			_, _ = file.WriteString(fmt.Sprintf("variable %s { default = %s }\n", variable, defaultVal))
+			builder.WriteString(fmt.Sprintf("variable %s { default = %s }\n", variable, defaultVal))
 		} else {
-			_, _ = file.WriteString(fmt.Sprintf("variable %s {}\n", variable))
+			builder.WriteString(fmt.Sprintf("variable %s {}\n", variable))
 		}
 	}
+	// the values of the sensitive variables are written to the secrets file, Terraform v0.11 does not support sensitive variables
+	isTfVersion11 := tf_export.TfHclVersionvar.ToString() == string(tf_export.TfVersion11)
+	for _, variable := range getSortedStringKeys(sensitiveVars) {
+		if _, exists := vars[variable]; exists {
+			continue
+		}
+		if isTfVersion11 {
+			builder.WriteString(fmt.Sprintf("variable %s {}\n", variable))
+		} else {
+			builder.WriteString(fmt.Sprintf("variable %s { sensitive = true }\n", variable))
+		}
+	}
+
+	varsString := []byte(builder.String())
+	if tf_export.IsJsonSyntax() {
+		if varsString, err = tf_export.ConvertHclToJson(varsString); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+	_, _ = file.Write(varsString)
 
 	if err := file.Close(); err != nil {
 		return err
@@ -1084,14 +1406,22 @@ func generateVarsFile(vars map[string]string, outputDir *string) error {
 }
 
 func generateProviderFile(outputDir *string) error {
-	providerTmpFile := fmt.Sprintf("%s%s%s.tmp", *outputDir, string(os.PathSeparator), globalvar.ProviderFile)
-	providerOutputFile := fmt.Sprintf("%s%s%s", *outputDir, string(os.PathSeparator), globalvar.ProviderFile)
+	providerOutputFile := fmt.Sprintf("%s%s%s", *outputDir, string(os.PathSeparator), tf_export.GetConfigFileName(globalvar.ProviderFile))
+	providerTmpFile := fmt.Sprintf("%s.tmp", providerOutputFile)
 	file, err := os.OpenFile(providerTmpFile, os.O_CREATE|os.O_RDWR, 0666)
 	if err != nil {
 		return err
 	}
 
-	_, err = file.WriteString(fmt.Sprintf("provider oci {\n\tregion = %s\n}\n", tf_export.TfHclVersionvar.GetVarHclString("region")))
+	providerString := []byte(fmt.Sprintf("provider oci {\n\tregion = %s\n}\n", tf_export.TfHclVersionvar.GetVarHclString("region")))
+	if tf_export.IsJsonSyntax() {
+		if providerString, err = tf_export.ConvertHclToJson(providerString); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	_, err = file.Write(providerString)
 	if err != nil {
 		_ = file.Close()
 		return err
@@ -1108,6 +1438,82 @@ func generateProviderFile(outputDir *string) error {
 	return nil
 }
 
+/*
+generateImportsFile writes an `import {}` block for each of the exported resources
+This is an alternative to generate_state for Terraform v1.5 and above, the resources are imported when the configuration is applied
+*/
+func generateImportsFile(ctx *tf_export.ResourceDiscoveryContext) error {
+	importsOutputFile := fmt.Sprintf("%s%s%s", *ctx.OutputDir, string(os.PathSeparator), tf_export.GetConfigFileName(globalvar.ImportsFile))
+	importsTmpFile := fmt.Sprintf("%s.tmp", importsOutputFile)
+
+	importBlocks := map[string]string{}
+	for _, resource := range ctx.DiscoveredResources {
+		if resource.IsErrorResource || (resource.TerraformTypeInfo != nil && resource.TerraformTypeInfo.IsDataSource) {
+			continue
+		}
+
+		resourceDefinition, exists := tf_export.ResourcesMap[resource.TerraformClass]
+		if !exists {
+			utils.Debugf("[DEBUG] skip import block for '%s' since it is not a Terraform OCI resource", resource.GetTerraformReference())
+			continue
+		}
+		if resourceDefinition.Importer == nil {
+			utils.Logf("[WARN] unable to generate import block for '%s' because import is not supported for '%s'", resource.GetTerraformReference(), resource.TerraformClass)
+			continue
+		}
+
+		// the resources grouped using for_each are imported as the instances of the resource
+		for _, instance := range resource.ForEachResources {
+			importBlocks[getForEachInstanceReference(resource, instance)] = getImportId(instance)
+		}
+		if len(resource.ForEachResources) == 0 {
+			importBlocks[resource.GetTerraformReference()] = getImportId(resource)
+		}
+	}
+
+	if len(importBlocks) == 0 {
+		utils.Logf("[INFO] ~~~~~~ no resources to write to %s ~~~~~~", globalvar.ImportsFile)
+		return nil
+	}
+
+	references := make([]string, 0, len(importBlocks))
+	for reference := range importBlocks {
+		references = append(references, reference)
+	}
+	sort.Strings(references)
+
+	builder := &strings.Builder{}
+	builder.WriteString("## This configuration was generated by terraform-provider-oci\n")
+	builder.WriteString("## Import blocks require Terraform v1.5 or above\n\n")
+	for _, reference := range references {
+		builder.WriteString(fmt.Sprintf("import {\nto = %s\nid = %q\n}\n\n", reference, importBlocks[reference]))
+	}
+
+	importsString, err := tf_export.FormatConfiguration([]byte(builder.String()))
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(importsTmpFile, importsString, 0666); err != nil {
+		return err
+	}
+
+	if err := os.Rename(importsTmpFile, importsOutputFile); err != nil {
+		return err
+	}
+
+	ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Generated %d import blocks under '%s'", len(importBlocks), importsOutputFile))
+	return nil
+}
+
+// getImportId returns the id used to import the resource, both by terraform import and by the import blocks
+func getImportId(resource *tf_export.OCIResource) string {
+	if len(resource.ImportId) == 0 {
+		return resource.Id
+	}
+	return resource.ImportId
+}
+
 //func getOciResource(d *schema.ResourceData, resourceSchema map[string]*schema.Schema, compartmentId string, resourceHint *tf_export.TerraformResourceHints, resourceId string) (*tf_export.OCIResource, error) {
 //	resourceMap, err := tf_export.ConvertDatasourceItemToMap(d, "", resourceSchema)
 //	if err != nil {
@@ -1215,6 +1621,16 @@ func readEnvironmentVars(d *schema.ResourceData) error {
 			return err
 		}
 	}
+	if credentialProcess := getProviderEnvSettingWithDefaultVar(globalvar.CredentialProcessAttrName, ""); credentialProcess != "" {
+		if err := d.Set(globalvar.CredentialProcessAttrName, credentialProcess); err != nil {
+			return err
+		}
+	}
+	if sessionTokenRefresh, _ := strconv.ParseBool(getProviderEnvSettingWithDefaultVar(globalvar.SessionTokenRefreshAttrName, "false")); sessionTokenRefresh {
+		if err := d.Set(globalvar.SessionTokenRefreshAttrName, true); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
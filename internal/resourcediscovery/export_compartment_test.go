@@ -5,8 +5,10 @@ package resourcediscovery
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path"
@@ -2352,8 +2354,52 @@ func TestUnitPrintResourceGraphResources(t *testing.T) {
 
 func TestUnitRunListExportableResourcesCommand(t *testing.T) {
 
-	err := RunListExportableResourcesCommand()
+	err := RunListExportableResourcesCommand("")
 	assert.NoError(t, err, "error not expected")
+
+	outputDir, err := ioutil.TempDir("", "list-export-resources")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	resourcesJsonPath := path.Join(outputDir, "resources.json")
+	assert.NoError(t, RunListExportableResourcesCommand(resourcesJsonPath), "error not expected")
+	content, err := ioutil.ReadFile(resourcesJsonPath)
+	assert.NoError(t, err)
+	var hints []*ExportResourceHint
+	assert.NoError(t, json.Unmarshal(content, &hints))
+	assert.NotEmpty(t, hints)
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitGetExportResourceHints(t *testing.T) {
+	defer func(relatedResourcesGraph tf_export.TerraformResourceGraph) {
+		tf_export.ExportRelatedResourcesGraph = relatedResourcesGraph
+	}(tf_export.ExportRelatedResourcesGraph)
+	tf_export.ExportRelatedResourcesGraph = tf_export.TerraformResourceGraph{
+		"oci_test_parent": {{TerraformResourceHints: exportChildDefinition}},
+	}
+
+	hints := getExportResourceHints(map[string]tf_export.TerraformResourceGraph{"testing": compartmentTestingResourceGraph}, CompartmentScope)
+	assert.Len(t, hints, 2)
+
+	parent, child := hints[0], hints[1]
+	assert.Equal(t, "oci_test_parent", parent.ResourceClass)
+	assert.Equal(t, "oci_identity_compartment", parent.ParentResourceClass)
+	assert.Equal(t, "testing", parent.Service)
+	assert.Equal(t, CompartmentScope, parent.Scope)
+	assert.Equal(t, exportParentDefinition.DatasourceClass, parent.DatasourceClass)
+	assert.True(t, parent.HasRelatedResources)
+	assert.Equal(t, []string{"oci_test_child"}, parent.RelatedResourceClasses)
+
+	assert.Equal(t, "oci_test_child", child.ResourceClass)
+	assert.Equal(t, "oci_test_parent", child.ParentResourceClass)
+	assert.Equal(t, map[string]string{"parent_id": "id"}, child.DatasourceQueryParams)
+	assert.Equal(t, exportChildDefinition.DiscoverableLifecycleStates, child.DiscoverableLifecycleStates)
+	assert.True(t, child.RequireResourceRefresh)
+	assert.False(t, child.HasRelatedResources)
+	assert.Empty(t, child.RelatedResourceClasses)
 }
 
 func TestUnitGenerateStateParallel(t *testing.T) {
@@ -2822,3 +2868,65 @@ func TestUnitParseDeliveryPolicy(t *testing.T) {
    }
 
 */
+
+// issue-routing-tag: terraform/default
+func TestUnitGenerateImportsFile(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+
+	ctx := getTestCtx()
+	ctx.GenerateImportBlocks = true
+	defer os.RemoveAll(*ctx.OutputDir)
+
+	ctx.DiscoveredResources = []*tf_export.OCIResource{
+		{
+			TerraformResource: tf_export.TerraformResource{
+				Id:             "ocid1.parent.1",
+				TerraformClass: "oci_test_parent",
+				TerraformName:  "parent1",
+			},
+		},
+		{
+			TerraformResource: tf_export.TerraformResource{
+				Id:             "ocid1.child.1",
+				ImportId:       "parents/ocid1.parent.1/children/ocid1.child.1",
+				TerraformClass: "oci_test_child",
+				TerraformName:  "child1",
+			},
+		},
+		{
+			// resources with import error are not written
+			TerraformResource: tf_export.TerraformResource{
+				Id:             "ocid1.parent.2",
+				TerraformClass: "oci_test_parent",
+				TerraformName:  "parent2",
+			},
+			IsErrorResource: true,
+		},
+		{
+			// data sources are not imported
+			TerraformResource: tf_export.TerraformResource{
+				Id:                "ocid1.ad.1",
+				TerraformClass:    "oci_identity_availability_domain",
+				TerraformName:     "ad1",
+				TerraformTypeInfo: &tf_export.TerraformResourceHints{IsDataSource: true},
+			},
+		},
+	}
+
+	if err := generateImportsFile(ctx); err != nil {
+		t.Fatalf("generateImportsFile() error = %v", err)
+	}
+
+	content, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", *ctx.OutputDir, string(os.PathSeparator), globalvar.ImportsFile))
+	if err != nil {
+		t.Fatalf("unable to read imports file: %v", err)
+	}
+	imports := string(content)
+
+	assert.Contains(t, imports, "to = oci_test_parent.parent1\n  id = \"ocid1.parent.1\"")
+	assert.Contains(t, imports, "to = oci_test_child.child1\n  id = \"parents/ocid1.parent.1/children/ocid1.child.1\"")
+	assert.NotContains(t, imports, "parent2")
+	assert.NotContains(t, imports, "ad1")
+	assert.Equal(t, 2, strings.Count(imports, "import {"))
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+// externalReferenceLookup describes how an object outside the export is looked up using a datasource
+type externalReferenceLookup struct {
+	resourceClass    string   // resource used to read the object from its OCID
+	datasourceClass  string   // datasource listing the objects of a compartment
+	listAttribute    string   // attribute of the datasource with the objects found
+	filterAttributes []string // attributes identifying the object in its compartment, which do not change between tenancies
+}
+
+// externalReferenceLookups maps the type in the OCID e.g. ocid1.image.oc1... to the lookup of the object
+var externalReferenceLookups = map[string]externalReferenceLookup{
+	"drg":                  {"oci_core_drg", "oci_core_drgs", "drgs", []string{"display_name"}},
+	"image":                {"oci_core_image", "oci_core_images", "images", []string{"display_name"}},
+	"networksecuritygroup": {"oci_core_network_security_group", "oci_core_network_security_groups", "network_security_groups", []string{"display_name"}},
+	"subnet":               {"oci_core_subnet", "oci_core_subnets", "subnets", []string{"display_name"}},
+	"tagnamespace":         {"oci_identity_tag_namespace", "oci_identity_tag_namespaces", "tag_namespaces", []string{"name"}},
+	"vcn":                  {"oci_core_vcn", "oci_core_vcns", "virtual_networks", []string{"display_name"}},
+}
+
+var ocidTypeRegex = regexp.MustCompile(`^ocid1\.([a-z0-9]+)\.`)
+
+// the variables added for an OCID e.g. the image of an instance, are replaced by the datasource
+var variableReferenceRegex = regexp.MustCompile(`^"?(?:\$\{)?var\.([a-zA-Z0-9_\-]+)\}?"?$`)
+
+// externalReference is a datasource looking up an object outside the export
+type externalReference struct {
+	id              string
+	datasourceClass string
+	datasourceName  string
+	compartmentId   string // interpolation of the compartment of the object
+	filters         [][2]string
+}
+
+func (ref *externalReference) getHCLString(builder *strings.Builder) {
+	builder.WriteString(fmt.Sprintf("data %s %s {\n", ref.datasourceClass, ref.datasourceName))
+	builder.WriteString(fmt.Sprintf("compartment_id = %s\n", ref.compartmentId))
+	// the names can have regular expression metacharacters e.g. `Oracle-Linux-8.8`, so the filter matches the quoted name exactly
+	for _, filter := range ref.filters {
+		builder.WriteString(fmt.Sprintf("filter {\nname = %q\nvalues = [%s]\nregex = true\n}\n", filter[0], escapeHclString("^"+regexp.QuoteMeta(filter[1])+"$")))
+	}
+	builder.WriteString("}\n\n")
+}
+
+func escapeHclString(value string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(fmt.Sprintf("%q", value))
+}
+
+/*
+resolveExternalReferences replaces the OCIDs of objects outside the export with datasources
+The OCIDs are found in the attributes of the discovered resources, an OCID is outside the export if it is not the OCID of an exported resource
+The object is read to find its name and compartment, the datasource looks up the object by these attributes so the configuration can be applied in another tenancy
+The objects which cannot be read or are not supported keep their OCID
+*/
+func resolveExternalReferences(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep, exportedIds map[string]bool) []*externalReference {
+	inScope := map[string]bool{}
+	for id := range exportedIds {
+		inScope[id] = true
+	}
+	for _, step := range steps {
+		for _, resource := range step.getDiscoveredResources() {
+			inScope[resource.Id] = true
+		}
+		for _, resource := range step.getOmittedResources() {
+			inScope[resource.Id] = true
+		}
+	}
+
+	externalIds := map[string]bool{}
+	for _, step := range steps {
+		for _, resource := range step.getDiscoveredResources() {
+			findExternalIds(resource.SourceAttributes, inScope, externalIds)
+		}
+	}
+	sortedIds := make([]string, 0, len(externalIds))
+	for id := range externalIds {
+		sortedIds = append(sortedIds, id)
+	}
+	sort.Strings(sortedIds)
+
+	var result []*externalReference
+	compartmentVars := map[string]string{}
+	unresolved := 0
+	for _, id := range sortedIds {
+		lookup := externalReferenceLookups[ocidTypeRegex.FindStringSubmatch(id)[1]]
+		ref, err := lookupExternalReference(ctx, id, lookup, compartmentVars)
+		if err != nil {
+			utils.Logf("[WARN] unable to look up '%s' outside the export, the OCID is kept in the configuration: %s", id, err.Error())
+			unresolved++
+			continue
+		}
+
+		tf_export.RefMapLock.Lock()
+		variable := ""
+		if match := variableReferenceRegex.FindStringSubmatch(tf_export.ReferenceMap[id]); match != nil {
+			variable = match[1]
+		}
+		tf_export.ReferenceMap[id] = tf_export.TfHclVersionvar.GetDataSourceHclString(fmt.Sprintf("%s.%s", ref.datasourceClass, ref.datasourceName), fmt.Sprintf("%s.0.id", lookup.listAttribute))
+		tf_export.RefMapLock.Unlock()
+		if variable != "" {
+			removeUnusedVariable(variable)
+		}
+
+		utils.Debugf("[DEBUG] '%s' outside the export is looked up by 'data.%s.%s'", id, ref.datasourceClass, ref.datasourceName)
+		result = append(result, ref)
+	}
+
+	if unresolved > 0 {
+		ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("%d objects outside the export could not be looked up and are referenced by their OCID", unresolved))
+	}
+	return result
+}
+
+// findExternalIds collects the OCIDs in the resource attributes which can be looked up and are outside the export
+func findExternalIds(value interface{}, inScope map[string]bool, externalIds map[string]bool) {
+	switch v := value.(type) {
+	case string:
+		match := ocidTypeRegex.FindStringSubmatch(v)
+		if match == nil || inScope[v] {
+			return
+		}
+		if _, supported := externalReferenceLookups[match[1]]; !supported {
+			return
+		}
+		tf_export.RefMapLock.Lock()
+		reference, exists := tf_export.ReferenceMap[v]
+		tf_export.RefMapLock.Unlock()
+		if !exists || variableReferenceRegex.MatchString(reference) {
+			externalIds[v] = true
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			findExternalIds(item, inScope, externalIds)
+		}
+	case []interface{}:
+		for _, item := range v {
+			findExternalIds(item, inScope, externalIds)
+		}
+	case []map[string]interface{}:
+		for _, item := range v {
+			findExternalIds(item, inScope, externalIds)
+		}
+	}
+}
+
+// lookupExternalReference reads the object and returns the datasource looking it up by its compartment and name
+func lookupExternalReference(ctx *tf_export.ResourceDiscoveryContext, id string, lookup externalReferenceLookup, compartmentVars map[string]string) (*externalReference, error) {
+	resourceSchema, exists := tf_export.ResourcesMap[lookup.resourceClass]
+	if !exists || resourceSchema.Read == nil {
+		return nil, fmt.Errorf("[ERROR] no resource found to read '%s'", lookup.resourceClass)
+	}
+
+	d := resourceSchema.Data(nil)
+	d.SetId(id)
+	if err := resourceSchema.Read(d, ctx.Clients); err != nil {
+		return nil, err
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("[ERROR] object was not found")
+	}
+
+	ref := &externalReference{id: id, datasourceClass: lookup.datasourceClass}
+	for _, attribute := range lookup.filterAttributes {
+		value, ok := d.Get(attribute).(string)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("[ERROR] object has no %s", attribute)
+		}
+		ref.filters = append(ref.filters, [2]string{attribute, value})
+	}
+	ref.datasourceName = tf_export.GetValidUniqueTerraformName(fmt.Sprintf("external_%s", ref.filters[0][1]))
+
+	// platform images do not have a compartment and are listed in the tenancy
+	compartmentId, _ := d.Get("compartment_id").(string)
+	if compartmentId == "" {
+		compartmentId = ctx.TenancyOcid
+	}
+	ref.compartmentId = getExternalCompartmentReference(ctx, compartmentId, compartmentVars)
+	return ref, nil
+}
+
+/*
+getExternalCompartmentReference returns the interpolation of the compartment of an object outside the export
+The compartment is referenced if it is exported, otherwise a variable is added for it since the OCID is different in another tenancy
+*/
+func getExternalCompartmentReference(ctx *tf_export.ResourceDiscoveryContext, compartmentId string, compartmentVars map[string]string) string {
+	tf_export.RefMapLock.Lock()
+	reference, exists := tf_export.ReferenceMap[compartmentId]
+	tf_export.RefMapLock.Unlock()
+	if exists {
+		return reference
+	}
+
+	if compartmentId == ctx.TenancyOcid {
+		tf_export.Vars["tenancy_ocid"] = fmt.Sprintf("\"%s\"", compartmentId)
+		return tf_export.TfHclVersionvar.GetVarHclString("tenancy_ocid")
+	}
+
+	variable, exists := compartmentVars[compartmentId]
+	if !exists {
+		variable = fmt.Sprintf("external_compartment_ocid_%d", len(compartmentVars)+1)
+		compartmentVars[compartmentId] = variable
+		tf_export.Vars[variable] = fmt.Sprintf("\"%s\"", compartmentId)
+	}
+	return tf_export.TfHclVersionvar.GetVarHclString(variable)
+}
+
+// removeUnusedVariable removes a variable replaced by a datasource if it is not referenced anymore
+func removeUnusedVariable(variable string) {
+	tf_export.RefMapLock.Lock()
+	defer tf_export.RefMapLock.Unlock()
+	for _, reference := range tf_export.ReferenceMap {
+		if match := variableReferenceRegex.FindStringSubmatch(reference); match != nil && match[1] == variable {
+			return
+		}
+	}
+	delete(tf_export.Vars, variable)
+}
+
+// generateExternalReferencesFile writes the datasources looking up the objects outside the export
+func generateExternalReferencesFile(ctx *tf_export.ResourceDiscoveryContext, references []*externalReference) error {
+	if len(references) == 0 {
+		return nil
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString("## This configuration was generated by terraform-provider-oci\n")
+	builder.WriteString("## Objects referenced by the exported resources which are not part of the export\n\n")
+	for _, ref := range references {
+		ref.getHCLString(builder)
+	}
+
+	outputFile := filepath.Join(*ctx.OutputDir, tf_export.GetConfigFileName(globalvar.ExternalReferencesFile))
+	if err := writeFormattedConfiguration(outputFile, builder.String()); err != nil {
+		return err
+	}
+
+	ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Replaced %d OCIDs of objects outside the export with datasources under '%s'", len(references), outputFile))
+	return nil
+}
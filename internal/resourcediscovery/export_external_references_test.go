@@ -0,0 +1,127 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitResolveExternalReferences(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "external-references")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+	defer func(referenceMap map[string]string, resourceNameCount map[string]int, vars map[string]string, resourcesMap map[string]*schema.Resource) {
+		tf_export.ReferenceMap = referenceMap
+		tf_export.ResourceNameCount = resourceNameCount
+		tf_export.Vars = vars
+		tf_export.ResourcesMap = resourcesMap
+	}(tf_export.ReferenceMap, tf_export.ResourceNameCount, tf_export.Vars, tf_export.ResourcesMap)
+	tf_export.ResourceNameCount = map[string]int{}
+
+	// the objects outside the export are read using the resources
+	objects := map[string]map[string]string{
+		"ocid1.image.oc1..image1":   {"display_name": "Oracle-Linux-8.7-2023.01.31-0"},
+		"ocid1.subnet.oc1..subnet1": {"display_name": "shared-subnet", "compartment_id": "ocid1.compartment.oc1..network"},
+		"ocid1.subnet.oc1..subnet2": {"display_name": "${local}-subnet", "compartment_id": "ocid1.compartment.oc1..exported"},
+	}
+	readObject := func(d *schema.ResourceData, m interface{}) error {
+		object, exists := objects[d.Id()]
+		if !exists {
+			return errors.New("not authorized or not found")
+		}
+		for key, value := range object {
+			_ = d.Set(key, value)
+		}
+		return nil
+	}
+	objectSchema := map[string]*schema.Schema{
+		"compartment_id": {Type: schema.TypeString, Optional: true},
+		"display_name":   {Type: schema.TypeString, Optional: true},
+	}
+	tf_export.ResourcesMap = map[string]*schema.Resource{
+		"oci_core_image":  {Schema: objectSchema, Read: readObject},
+		"oci_core_subnet": {Schema: objectSchema, Read: readObject},
+	}
+
+	tf_export.Vars = map[string]string{
+		"compartment_ocid": "\"ocid1.compartment.oc1..exported\"",
+		"image_var":        "\"ocid1.image.oc1..image1\"",
+	}
+	tf_export.ReferenceMap = map[string]string{
+		"ocid1.compartment.oc1..exported": "var.compartment_ocid",
+		"ocid1.image.oc1..image1":         "var.image_var",
+		"ocid1.vcn.oc1..vcn1":             "oci_core_vcn.vcn1.id",
+	}
+
+	ctx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, ResolveExternalReferences: true},
+		TenancyOcid:       "ocid1.tenancy.oc1..tenancy",
+	}
+	step := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "core"}}
+	step.discoveredResources = []*tf_export.OCIResource{
+		{
+			TerraformResource: tf_export.TerraformResource{Id: "ocid1.vcn.oc1..vcn1", TerraformClass: "oci_core_vcn", TerraformName: "vcn1"},
+			SourceAttributes:  map[string]interface{}{"compartment_id": "ocid1.compartment.oc1..exported"},
+		},
+		{
+			TerraformResource: tf_export.TerraformResource{Id: "ocid1.instance.oc1..instance1", TerraformClass: "oci_core_instance", TerraformName: "instance1"},
+			SourceAttributes: map[string]interface{}{
+				"source_details": []interface{}{map[string]interface{}{"source_id": "ocid1.image.oc1..image1"}},
+				"create_vnic_details": []interface{}{map[string]interface{}{
+					"subnet_id": "ocid1.subnet.oc1..subnet1",
+					"nsg_ids":   []interface{}{"ocid1.networksecuritygroup.oc1..deleted"},
+				}},
+				"kms_key_id": "ocid1.key.oc1..key1",
+				"vcn_id":     "ocid1.vcn.oc1..vcn1",
+			},
+		},
+		{
+			TerraformResource: tf_export.TerraformResource{Id: "ocid1.vnicattachment.oc1..vnic1", TerraformClass: "oci_core_vnic_attachment", TerraformName: "vnic1"},
+			SourceAttributes:  map[string]interface{}{"subnet_id": "ocid1.subnet.oc1..subnet2"},
+		},
+	}
+
+	references := resolveExternalReferences(ctx, []resourceDiscoveryStep{step}, nil)
+	assert.Len(t, references, 3)
+
+	// the objects outside the export are referenced through the datasources, the variable of the image is replaced
+	assert.Equal(t, "data.oci_core_images.external_Oracle-Linux-8-7-2023-01-31-0.images.0.id", tf_export.ReferenceMap["ocid1.image.oc1..image1"])
+	assert.Equal(t, "data.oci_core_subnets.external_shared-subnet.subnets.0.id", tf_export.ReferenceMap["ocid1.subnet.oc1..subnet1"])
+	assert.Equal(t, "data.oci_core_subnets.external_-local--subnet.subnets.0.id", tf_export.ReferenceMap["ocid1.subnet.oc1..subnet2"])
+	assert.Equal(t, "oci_core_vcn.vcn1.id", tf_export.ReferenceMap["ocid1.vcn.oc1..vcn1"])
+	assert.NotContains(t, tf_export.ReferenceMap, "ocid1.key.oc1..key1")
+	assert.NotContains(t, tf_export.ReferenceMap, "ocid1.networksecuritygroup.oc1..deleted")
+	assert.Equal(t, map[string]string{
+		"compartment_ocid":            "\"ocid1.compartment.oc1..exported\"",
+		"tenancy_ocid":                "\"ocid1.tenancy.oc1..tenancy\"",
+		"external_compartment_ocid_1": "\"ocid1.compartment.oc1..network\"",
+	}, tf_export.Vars)
+	assert.Contains(t, strings.Join(ctx.SummaryStatements, "\n"), "1 objects outside the export could not be looked up")
+
+	assert.NoError(t, generateExternalReferencesFile(ctx, references))
+	content, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.ExternalReferencesFile))
+	assert.NoError(t, err)
+	config := string(content)
+	assert.Contains(t, config, "data oci_core_images external_Oracle-Linux-8-7-2023-01-31-0 {\n  compartment_id = var.tenancy_ocid\n  filter {\n    name   = \"display_name\"\n    values = [\"^Oracle-Linux-8\\\\.7-2023\\\\.01\\\\.31-0$\"]\n    regex  = true\n  }\n}")
+	assert.Contains(t, config, "compartment_id = var.external_compartment_ocid_1")
+	assert.Contains(t, config, "compartment_id = var.compartment_ocid")
+	assert.Contains(t, config, "values = [\"^\\\\$\\\\{local\\\\}-subnet$\"]")
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+/*
+groupResources collapses the resources of the same type and parent into a single resource using for_each, e.g. the security rules of a network security group
+Only the resource types with the IsGroupableByParent hint are grouped and a group has at least 2 resources
+The grouped resources become the instances of the resource keyed by their Terraform name e.g. oci_core_network_security_group_security_rule.export_nsg1_security_rule["export_rule1"]
+and keep their import IDs, the references to them and the state are updated with the address of the instances
+*/
+func groupResources(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep) error {
+	renamed := map[string]string{}
+	var groups []*tf_export.OCIResource
+	for _, step := range steps {
+		baseStep := step.getBaseStep()
+		stepGroups := map[string]*tf_export.OCIResource{}
+		var resources []*tf_export.OCIResource
+		for _, resource := range baseStep.discoveredResources {
+			if !isGroupableResource(resource) {
+				resources = append(resources, resource)
+				continue
+			}
+			key := fmt.Sprintf("%s:%s", resource.TerraformClass, resource.Parent.Id)
+			group, exists := stepGroups[key]
+			if !exists {
+				group = &tf_export.OCIResource{
+					TerraformResource: tf_export.TerraformResource{
+						TerraformClass:    resource.TerraformClass,
+						TerraformTypeInfo: resource.TerraformTypeInfo,
+					},
+					CompartmentId:    resource.CompartmentId,
+					SourceAttributes: map[string]interface{}{},
+					GetHclStringFn:   tf_export.GetForEachHclString,
+					Parent:           resource.Parent,
+					Provider:         resource.Provider,
+				}
+				stepGroups[key] = group
+				resources = append(resources, group)
+			}
+			group.ForEachResources = append(group.ForEachResources, resource)
+		}
+
+		for i, resource := range resources {
+			if len(resource.ForEachResources) == 0 {
+				continue
+			}
+			// a single resource is not written using for_each
+			if len(resource.ForEachResources) == 1 {
+				resources[i] = resource.ForEachResources[0]
+				continue
+			}
+			abbreviation := resource.TerraformClass
+			if resource.TerraformTypeInfo != nil && resource.TerraformTypeInfo.ResourceAbbreviation != "" {
+				abbreviation = resource.TerraformTypeInfo.ResourceAbbreviation
+			}
+			resource.TerraformName = tf_export.GetValidUniqueTerraformName(fmt.Sprintf("%s_%s", resource.Parent.TerraformName, abbreviation))
+			for _, instance := range resource.ForEachResources {
+				renamed[instance.GetTerraformReference()] = getForEachInstanceReference(resource, instance)
+			}
+			groups = append(groups, resource)
+			utils.Debugf("[DEBUG] grouped %d resources of '%s' into '%s'", len(resource.ForEachResources), resource.Parent.GetTerraformReference(), resource.GetTerraformReference())
+		}
+		baseStep.discoveredResources = resources
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+	renameResourceReferences(steps, renamed)
+
+	if ctx.GenerateState {
+		if err := groupStateResources(filepath.Join(*ctx.OutputDir, globalvar.DefaultStateFilename), groups); err != nil {
+			return err
+		}
+	}
+	ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Grouped %d resources into %d resources using for_each", len(renamed), len(groups)))
+	return nil
+}
+
+func isGroupableResource(resource *tf_export.OCIResource) bool {
+	return resource.TerraformTypeInfo != nil && resource.TerraformTypeInfo.IsGroupableByParent && !resource.TerraformTypeInfo.IsDataSource &&
+		resource.Parent != nil && !resource.IsErrorResource
+}
+
+// getForEachInstanceReference returns the address of a grouped resource e.g. oci_core_network_security_group_security_rule.export_nsg1_security_rule["export_rule1"]
+func getForEachInstanceReference(group *tf_export.OCIResource, instance *tf_export.OCIResource) string {
+	return fmt.Sprintf("%s[%q]", group.GetTerraformReference(), instance.TerraformName)
+}
+
+/*
+groupStateResources moves the grouped resources in the state file to the instances of their group
+The instances keep the attributes imported for the resources, so the state matches the configuration without importing the resources again
+*/
+func groupStateResources(stateFile string, groups []*tf_export.OCIResource) error {
+	content, err := ioutil.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&state); err != nil {
+		return fmt.Errorf("[ERROR] unable to read state file %s: %s", stateFile, err.Error())
+	}
+	resources, _ := state["resources"].([]interface{})
+
+	groupByAddress := map[string]*tf_export.OCIResource{}
+	for _, group := range groups {
+		for _, instance := range group.ForEachResources {
+			groupByAddress[instance.GetTerraformReference()] = group
+		}
+	}
+
+	groupStates := map[*tf_export.OCIResource]map[string]interface{}{}
+	var result []interface{}
+	for _, item := range resources {
+		resource, ok := item.(map[string]interface{})
+		if !ok || resource["mode"] != "managed" {
+			result = append(result, item)
+			continue
+		}
+		group, exists := groupByAddress[fmt.Sprintf("%v.%v", resource["type"], resource["name"])]
+		if !exists {
+			result = append(result, item)
+			continue
+		}
+
+		groupState, exists := groupStates[group]
+		if !exists {
+			groupState = map[string]interface{}{
+				"mode":      "managed",
+				"type":      group.TerraformClass,
+				"name":      group.TerraformName,
+				"provider":  resource["provider"],
+				"each":      "map",
+				"instances": []interface{}{},
+			}
+			groupStates[group] = groupState
+			result = append(result, groupState)
+		}
+		instances, _ := resource["instances"].([]interface{})
+		for _, instance := range instances {
+			if instanceMap, ok := instance.(map[string]interface{}); ok {
+				instanceMap["index_key"] = resource["name"]
+				groupState["instances"] = append(groupState["instances"].([]interface{}), instanceMap)
+			}
+		}
+	}
+	if len(groupStates) == 0 {
+		return nil
+	}
+
+	state["resources"] = result
+	if serial, ok := state["serial"].(json.Number); ok {
+		if value, err := serial.Int64(); err == nil {
+			state["serial"] = value + 1
+		}
+	}
+	stateBytes, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(stateFile, stateBytes, 0644)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+const groupResourcesTestState = `{
+	"version": 4,
+	"serial": 3,
+	"resources": [
+		{"mode": "managed", "type": "oci_core_network_security_group", "name": "nsg1", "provider": "provider[\"registry.terraform.io/hashicorp/oci\"]", "instances": [{"attributes": {"id": "ocid1.networksecuritygroup.1"}}]},
+		{"mode": "managed", "type": "oci_core_network_security_group_security_rule", "name": "rule1", "provider": "provider[\"registry.terraform.io/hashicorp/oci\"]", "instances": [{"attributes": {"id": "rule1"}}]},
+		{"mode": "managed", "type": "oci_core_network_security_group_security_rule", "name": "rule2", "provider": "provider[\"registry.terraform.io/hashicorp/oci\"]", "instances": [{"attributes": {"id": "rule2"}}]},
+		{"mode": "managed", "type": "oci_core_network_security_group_security_rule", "name": "rule3", "provider": "provider[\"registry.terraform.io/hashicorp/oci\"]", "instances": [{"attributes": {"id": "rule3"}}]}
+	]
+}`
+
+// issue-routing-tag: terraform/default
+func TestUnitGroupResources(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "group-resources")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+	stateFile := filepath.Join(outputDir, globalvar.DefaultStateFilename)
+	if err := ioutil.WriteFile(stateFile, []byte(groupResourcesTestState), 0644); err != nil {
+		t.Fatalf("unable to write state file: %v", err)
+	}
+
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+	defer func(referenceMap map[string]string, resourceNameCount map[string]int) {
+		tf_export.ReferenceMap = referenceMap
+		tf_export.ResourceNameCount = resourceNameCount
+	}(tf_export.ReferenceMap, tf_export.ResourceNameCount)
+	tf_export.ResourceNameCount = map[string]int{}
+
+	ruleHints := &tf_export.TerraformResourceHints{ResourceClass: "oci_core_network_security_group_security_rule", ResourceAbbreviation: "security_rule", IsGroupableByParent: true}
+	nsg1 := &tf_export.OCIResource{TerraformResource: tf_export.TerraformResource{Id: "ocid1.networksecuritygroup.1", TerraformClass: "oci_core_network_security_group", TerraformName: "nsg1"}}
+	nsg2 := &tf_export.OCIResource{TerraformResource: tf_export.TerraformResource{Id: "ocid1.networksecuritygroup.2", TerraformClass: "oci_core_network_security_group", TerraformName: "nsg2"}}
+	newRule := func(name string, parent *tf_export.OCIResource) *tf_export.OCIResource {
+		return &tf_export.OCIResource{
+			TerraformResource: tf_export.TerraformResource{Id: name, TerraformClass: "oci_core_network_security_group_security_rule", TerraformName: name, TerraformTypeInfo: ruleHints},
+			SourceAttributes:  map[string]interface{}{"direction": "INGRESS"},
+			Parent:            parent,
+		}
+	}
+	rule1, rule2, rule3 := newRule("rule1", nsg1), newRule("rule2", nsg1), newRule("rule3", nsg2)
+
+	tf_export.ReferenceMap = map[string]string{
+		"ocid1.networksecuritygroup.1": "oci_core_network_security_group.nsg1.id",
+		"rule1":                        "oci_core_network_security_group_security_rule.rule1.id",
+		"rule3":                        "oci_core_network_security_group_security_rule.rule3.id",
+	}
+
+	ctx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, GenerateState: true, GroupResources: true},
+	}
+	step := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "core"}}
+	step.discoveredResources = []*tf_export.OCIResource{nsg1, nsg2, rule1, rule2, rule3}
+
+	assert.NoError(t, groupResources(ctx, []resourceDiscoveryStep{step}))
+
+	// the rules of nsg1 are grouped, the single rule of nsg2 is kept as it is
+	resources := step.getDiscoveredResources()
+	assert.Len(t, resources, 4)
+	group := resources[2]
+	assert.Equal(t, "nsg1_security_rule", group.TerraformName)
+	assert.Equal(t, []*tf_export.OCIResource{rule1, rule2}, group.ForEachResources)
+	assert.Equal(t, rule3, resources[3])
+	assert.Equal(t, "oci_core_network_security_group_security_rule.nsg1_security_rule[\"rule1\"].id", tf_export.ReferenceMap["rule1"])
+	assert.Equal(t, "oci_core_network_security_group_security_rule.rule3.id", tf_export.ReferenceMap["rule3"])
+	assert.Contains(t, strings.Join(ctx.SummaryStatements, "\n"), "Grouped 2 resources into 1 resources using for_each")
+
+	// the grouped resources are moved to the instances of the group in the state
+	content, err := ioutil.ReadFile(stateFile)
+	assert.NoError(t, err)
+	var state struct {
+		Serial    int `json:"serial"`
+		Resources []struct {
+			Type      string                   `json:"type"`
+			Name      string                   `json:"name"`
+			Each      string                   `json:"each"`
+			Instances []map[string]interface{} `json:"instances"`
+		} `json:"resources"`
+	}
+	assert.NoError(t, json.Unmarshal(content, &state))
+	assert.Equal(t, 4, state.Serial)
+	assert.Len(t, state.Resources, 3)
+	assert.Equal(t, "nsg1", state.Resources[0].Name)
+	assert.Equal(t, "nsg1_security_rule", state.Resources[1].Name)
+	assert.Equal(t, "map", state.Resources[1].Each)
+	assert.Len(t, state.Resources[1].Instances, 2)
+	assert.Equal(t, "rule1", state.Resources[1].Instances[0]["index_key"])
+	assert.Equal(t, "rule2", state.Resources[1].Instances[1]["index_key"])
+	assert.Equal(t, "rule3", state.Resources[2].Name)
+	assert.Empty(t, state.Resources[2].Each)
+}
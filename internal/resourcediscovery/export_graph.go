@@ -0,0 +1,190 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// Types of the edges of the dependency graph
+const (
+	graphEdgeReference = "reference" // the resource refers to the OCID of the other resource in one of its attributes
+	graphEdgeParent    = "parent"    // the resource was discovered as a child of the other resource
+)
+
+// dependencyGraph is the graph of the exported resources, the edges go from a resource to the resource it depends on
+type dependencyGraph struct {
+	Nodes []dependencyGraphNode `json:"nodes"`
+	Edges []dependencyGraphEdge `json:"edges"`
+}
+
+type dependencyGraphNode struct {
+	Address       string `json:"address"`
+	Id            string `json:"id"`
+	ResourceType  string `json:"resource_type"`
+	CompartmentId string `json:"compartment_id,omitempty"`
+}
+
+type dependencyGraphEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Type      string `json:"type"`
+	Attribute string `json:"attribute,omitempty"` // attribute of the `from` resource referring to the `to` resource
+}
+
+/*
+buildDependencyGraph builds the dependency graph of the exported resources
+- a reference edge is added for every attribute whose OCID was replaced with an interpolation using the referenceMap
+- a parent edge is added from a resource to the resource it was discovered from in the resource graph
+Only the edges between exported resources are added, references to variables e.g. the compartment are not part of the graph
+*/
+func buildDependencyGraph(resources []*tf_export.OCIResource, referenceMap map[string]string) *dependencyGraph {
+	graph := &dependencyGraph{Nodes: []dependencyGraphNode{}, Edges: []dependencyGraphEdge{}}
+
+	resourcesById := map[string]*tf_export.OCIResource{}
+	for _, resource := range resources {
+		if resource.IsErrorResource {
+			continue
+		}
+		if len(resource.ForEachResources) == 0 {
+			resourcesById[resource.Id] = resource
+		}
+		// the references to the resources grouped using for_each are references to the resource
+		for _, instance := range resource.ForEachResources {
+			resourcesById[instance.Id] = resource
+		}
+		graph.Nodes = append(graph.Nodes, dependencyGraphNode{
+			Address:       resource.GetTerraformReference(),
+			Id:            resource.Id,
+			ResourceType:  resource.TerraformClass,
+			CompartmentId: resource.CompartmentId,
+		})
+	}
+
+	edges := map[dependencyGraphEdge]bool{}
+	for _, resource := range resourcesById {
+		from := resource.GetTerraformReference()
+
+		references := map[string][]string{}
+		findAttributeReferences(resource.SourceAttributes, "", referenceMap, references)
+		for _, instance := range resource.ForEachResources {
+			findAttributeReferences(instance.SourceAttributes, "", referenceMap, references)
+		}
+		for id, attributes := range references {
+			referenced, exists := resourcesById[id]
+			if !exists || referenced == resource {
+				continue
+			}
+			for _, attribute := range attributes {
+				edges[dependencyGraphEdge{From: from, To: referenced.GetTerraformReference(), Type: graphEdgeReference, Attribute: attribute}] = true
+			}
+		}
+
+		if resource.Parent != nil {
+			if parent, exists := resourcesById[resource.Parent.Id]; exists && parent != resource {
+				edges[dependencyGraphEdge{From: from, To: parent.GetTerraformReference(), Type: graphEdgeParent}] = true
+			}
+		}
+	}
+
+	for edge := range edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Address < graph.Nodes[j].Address
+	})
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Attribute < b.Attribute
+	})
+	return graph
+}
+
+// findAttributeReferences finds the OCIDs in the referenceMap used by the attributes, nested attributes are named by their path e.g. create_vnic_details.subnet_id
+func findAttributeReferences(value interface{}, path string, referenceMap map[string]string, references map[string][]string) {
+	switch v := value.(type) {
+	case string:
+		if _, exists := referenceMap[v]; exists && path != "" {
+			references[v] = append(references[v], path)
+		}
+	case []interface{}:
+		for _, item := range v {
+			findAttributeReferences(item, path, referenceMap, references)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			attributePath := key
+			if path != "" {
+				attributePath = fmt.Sprintf("%s.%s", path, key)
+			}
+			findAttributeReferences(item, attributePath, referenceMap, references)
+		}
+	}
+}
+
+// toDot returns the graph in Graphviz DOT format
+func (graph *dependencyGraph) toDot() string {
+	builder := &strings.Builder{}
+	builder.WriteString("// This graph was generated by terraform-provider-oci\n")
+	builder.WriteString("digraph resources {\n")
+	builder.WriteString("  rankdir = \"LR\";\n")
+	builder.WriteString("  node [shape = \"box\"];\n\n")
+
+	for _, node := range graph.Nodes {
+		builder.WriteString(fmt.Sprintf("  %q [tooltip = %q];\n", node.Address, node.Id))
+	}
+	if len(graph.Edges) > 0 {
+		builder.WriteString("\n")
+	}
+	for _, edge := range graph.Edges {
+		if edge.Type == graphEdgeParent {
+			builder.WriteString(fmt.Sprintf("  %q -> %q [style = \"dashed\"];\n", edge.From, edge.To))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("  %q -> %q [label = %q];\n", edge.From, edge.To, edge.Attribute))
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// generateDependencyGraphFiles writes the dependency graph of the exported resources as DOT and JSON to the output directory
+func generateDependencyGraphFiles(ctx *tf_export.ResourceDiscoveryContext) error {
+	defer elapsed("generating dependency graph", nil, 0)()
+	graph := buildDependencyGraph(ctx.DiscoveredResources, tf_export.ReferenceMap)
+
+	dotFile := filepath.Join(*ctx.OutputDir, globalvar.DependencyGraphDotFile)
+	if err := ioutil.WriteFile(dotFile, []byte(graph.toDot()), 0666); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return err
+	}
+	jsonFile := filepath.Join(*ctx.OutputDir, globalvar.DependencyGraphJsonFile)
+	if err := ioutil.WriteFile(jsonFile, content, 0666); err != nil {
+		return err
+	}
+
+	ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Generated dependency graph of %d resources with %d edges under '%s' and '%s'", len(graph.Nodes), len(graph.Edges), dotFile, jsonFile))
+	return nil
+}
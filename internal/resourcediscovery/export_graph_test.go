@@ -0,0 +1,91 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitGenerateDependencyGraphFiles(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "dependency-graph")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	defer func(referenceMap map[string]string) { tf_export.ReferenceMap = referenceMap }(tf_export.ReferenceMap)
+
+	newResource := func(id string, class string, name string, parent *tf_export.OCIResource, attributes map[string]interface{}) *tf_export.OCIResource {
+		return &tf_export.OCIResource{
+			TerraformResource: tf_export.TerraformResource{Id: id, TerraformClass: class, TerraformName: name},
+			CompartmentId:     resourceDiscoveryTestCompartmentOcid,
+			SourceAttributes:  attributes,
+			Parent:            parent,
+		}
+	}
+	compartment := newResource(resourceDiscoveryTestCompartmentOcid, "oci_identity_compartment", "export", nil, nil)
+	vcn := newResource("ocid1.vcn.1", "oci_core_vcn", "export_vcn1", compartment, map[string]interface{}{
+		"compartment_id": resourceDiscoveryTestCompartmentOcid,
+	})
+	subnet := newResource("ocid1.subnet.1", "oci_core_subnet", "export_subnet1", compartment, map[string]interface{}{
+		"vcn_id":            "ocid1.vcn.1",
+		"security_list_ids": []interface{}{"ocid1.securitylist.1"},
+	})
+	securityList := newResource("ocid1.securitylist.1", "oci_core_security_list", "export_security_list1", vcn, map[string]interface{}{
+		"vcn_id": "ocid1.vcn.1",
+	})
+	instance := newResource("ocid1.instance.1", "oci_core_instance", "export_instance1", compartment, map[string]interface{}{
+		"create_vnic_details": []interface{}{map[string]interface{}{"subnet_id": "ocid1.subnet.1"}},
+		"image":               "ocid1.image.1",
+	})
+	failed := newResource("ocid1.volume.1", "oci_core_volume", "export_volume1", compartment, map[string]interface{}{})
+	failed.IsErrorResource = true
+
+	tf_export.ReferenceMap = map[string]string{
+		resourceDiscoveryTestCompartmentOcid: "var.compartment_ocid",
+		"ocid1.vcn.1":                        "oci_core_vcn.export_vcn1.id",
+		"ocid1.subnet.1":                     "oci_core_subnet.export_subnet1.id",
+		"ocid1.securitylist.1":               "oci_core_security_list.export_security_list1.id",
+		"ocid1.instance.1":                   "oci_core_instance.export_instance1.id",
+	}
+
+	ctx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs:   &tf_export.ExportCommandArgs{OutputDir: &outputDir},
+		DiscoveredResources: []*tf_export.OCIResource{vcn, subnet, securityList, instance, failed},
+	}
+	assert.NoError(t, generateDependencyGraphFiles(ctx))
+	assert.Len(t, ctx.SummaryStatements, 1)
+
+	content, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.DependencyGraphJsonFile))
+	assert.NoError(t, err)
+	graph := &dependencyGraph{}
+	assert.NoError(t, json.Unmarshal(content, graph))
+
+	assert.Len(t, graph.Nodes, 4)
+	assert.Equal(t, []dependencyGraphEdge{
+		{From: "oci_core_instance.export_instance1", To: "oci_core_subnet.export_subnet1", Type: graphEdgeReference, Attribute: "create_vnic_details.subnet_id"},
+		{From: "oci_core_security_list.export_security_list1", To: "oci_core_vcn.export_vcn1", Type: graphEdgeParent},
+		{From: "oci_core_security_list.export_security_list1", To: "oci_core_vcn.export_vcn1", Type: graphEdgeReference, Attribute: "vcn_id"},
+		{From: "oci_core_subnet.export_subnet1", To: "oci_core_security_list.export_security_list1", Type: graphEdgeReference, Attribute: "security_list_ids"},
+		{From: "oci_core_subnet.export_subnet1", To: "oci_core_vcn.export_vcn1", Type: graphEdgeReference, Attribute: "vcn_id"},
+	}, graph.Edges)
+
+	dot, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.DependencyGraphDotFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(dot), "digraph resources {")
+	assert.Contains(t, string(dot), "  \"oci_core_vcn.export_vcn1\" [tooltip = \"ocid1.vcn.1\"];")
+	assert.Contains(t, string(dot), "  \"oci_core_subnet.export_subnet1\" -> \"oci_core_vcn.export_vcn1\" [label = \"vcn_id\"];")
+	assert.Contains(t, string(dot), "  \"oci_core_security_list.export_security_list1\" -> \"oci_core_vcn.export_vcn1\" [style = \"dashed\"];")
+	assert.NotContains(t, string(dot), "export_volume1")
+}
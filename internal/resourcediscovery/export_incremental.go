@@ -0,0 +1,303 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+// incrementalState is the state file of a previous export in the output directory
+type incrementalState struct {
+	content   interface{}
+	resources map[string]string // OCID of the managed resources mapped to their address e.g. oci_core_vcn.export_vcn1
+}
+
+/*
+loadIncrementalState reads the state file of the previous export from the output directory
+An incremental export requires the state file, without it every resource would be written again to the existing configuration
+*/
+func loadIncrementalState(outputDir string) (*incrementalState, error) {
+	stateFile := filepath.Join(outputDir, globalvar.DefaultStateFilename)
+	content, err := ioutil.ReadFile(stateFile)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] incremental export requires the state file of the previous export: %s", err.Error())
+	}
+
+	state := struct {
+		Resources []struct {
+			Mode      string `json:"mode"`
+			Type      string `json:"type"`
+			Name      string `json:"name"`
+			Instances []struct {
+				Attributes map[string]interface{} `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+	}{}
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to read state file %s: %s", stateFile, err.Error())
+	}
+
+	result := &incrementalState{resources: map[string]string{}}
+	if err := json.Unmarshal(content, &result.content); err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to read state file %s: %s", stateFile, err.Error())
+	}
+	for _, resource := range state.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			if id, ok := instance.Attributes["id"].(string); ok && id != "" {
+				result.resources[id] = fmt.Sprintf("%s.%s", resource.Type, resource.Name)
+			}
+		}
+	}
+	return result, nil
+}
+
+/*
+applyIncrementalExport updates the discovered resources with the state of the previous export
+- the resources found in the state keep their names and are not written again, so that the changes made to their configuration are kept
+- the new resources are renamed if their name is already used by a resource in the state
+- the resources in the state which are no longer found are reported in the summary
+The configuration of the new resources is appended to the existing configuration files
+*/
+func applyIncrementalExport(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep) error {
+	state, err := loadIncrementalState(*ctx.OutputDir)
+	if err != nil {
+		return err
+	}
+
+	usedAddresses := map[string]bool{}
+	var stateNames []string
+	for _, address := range state.resources {
+		usedAddresses[address] = true
+		stateNames = append(stateNames, strings.SplitN(address, ".", 2)[1])
+	}
+	registerResourceNames(stateNames)
+
+	renamed := map[string]string{}
+	foundIds := map[string]bool{}
+	exportedTypes := map[string]bool{}
+	existingCount := 0
+	for _, step := range steps {
+		baseStep := step.getBaseStep()
+		for resourceType := range getStepResourceTypes(step) {
+			exportedTypes[resourceType] = true
+		}
+
+		var newResources []*tf_export.OCIResource
+		for _, resource := range step.getDiscoveredResources() {
+			foundIds[resource.Id] = true
+			if address, exists := state.resources[resource.Id]; exists && strings.HasPrefix(address, resource.TerraformClass+".") {
+				renameIncrementalResource(resource, strings.TrimPrefix(address, resource.TerraformClass+"."), renamed)
+				existingCount++
+				continue
+			}
+			if usedAddresses[resource.GetTerraformReference()] {
+				name := resource.TerraformName
+				for usedAddresses[fmt.Sprintf("%s.%s", resource.TerraformClass, name)] {
+					name = tf_export.CheckDuplicateResourceName(resource.TerraformName)
+				}
+				renameIncrementalResource(resource, name, renamed)
+			}
+			usedAddresses[resource.GetTerraformReference()] = true
+			newResources = append(newResources, resource)
+		}
+		for _, resource := range step.getOmittedResources() {
+			foundIds[resource.Id] = true
+		}
+		baseStep.discoveredResources = newResources
+	}
+	renameResourceReferences(steps, renamed)
+
+	var vanished []string
+	for id, address := range state.resources {
+		if !foundIds[id] && exportedTypes[strings.SplitN(address, ".", 2)[0]] {
+			vanished = append(vanished, address)
+		}
+	}
+	sort.Strings(vanished)
+
+	if ctx.GenerateState {
+		// the new resources are imported to the state of the previous export
+		ctx.State = state.content
+	}
+	if err := loadIncrementalVars(*ctx.OutputDir); err != nil {
+		return err
+	}
+	if err := loadIncrementalSecrets(*ctx.OutputDir); err != nil {
+		return err
+	}
+
+	ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Kept %d resources found in the state of the previous export", existingCount))
+	if len(vanished) > 0 {
+		ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("%d resources in the state were not found, remove them from the configuration and the state if they were deleted:", len(vanished)))
+		ctx.SummaryStatements = append(ctx.SummaryStatements, vanished...)
+	}
+	return nil
+}
+
+// renameIncrementalResource renames the resource and updates its reference in the referenceMap
+func renameIncrementalResource(resource *tf_export.OCIResource, name string, renamed map[string]string) {
+	if resource.TerraformName == name {
+		return
+	}
+	oldAddress := resource.GetTerraformReference()
+	resource.TerraformName = name
+	renamed[oldAddress] = resource.GetTerraformReference()
+	utils.Debugf("[DEBUG] renamed '%s' to '%s' for incremental export", oldAddress, resource.GetTerraformReference())
+}
+
+/*
+renameResourceReferences updates the references to the renamed resources
+The references are in the referenceMap, in the interpolations added to the attributes and in the reference id strings of the resources
+*/
+func renameResourceReferences(steps []resourceDiscoveryStep, renamed map[string]string) {
+	if len(renamed) == 0 {
+		return
+	}
+	addresses := make([]string, 0, len(renamed))
+	for oldAddress := range renamed {
+		addresses = append(addresses, regexp.QuoteMeta(oldAddress))
+	}
+	// the address is followed by an attribute e.g. oci_core_vcn.vcn1.id and is not part of a longer name
+	referenceRegex := regexp.MustCompile(fmt.Sprintf(`(^|[^A-Za-z0-9_.\-])(%s)\.`, strings.Join(addresses, "|")))
+	rename := func(value string) string {
+		return referenceRegex.ReplaceAllStringFunc(value, func(match string) string {
+			groups := referenceRegex.FindStringSubmatch(match)
+			return groups[1] + renamed[groups[2]] + "."
+		})
+	}
+
+	tf_export.RefMapLock.Lock()
+	for id, reference := range tf_export.ReferenceMap {
+		tf_export.ReferenceMap[id] = rename(reference)
+	}
+	tf_export.RefMapLock.Unlock()
+
+	for _, step := range steps {
+		for _, resource := range step.getDiscoveredResources() {
+			for _, item := range append([]*tf_export.OCIResource{resource}, resource.ForEachResources...) {
+				item.TerraformReferenceIdString = rename(item.TerraformReferenceIdString)
+				renameInterpolations(item.SourceAttributes, renamed, rename)
+			}
+		}
+	}
+}
+
+func renameInterpolations(value interface{}, renamed map[string]string, rename func(string) string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if interpolation, ok := item.(tf_export.InterpolationString); ok {
+				if newAddress, exists := renamed[interpolation.ResourceReference]; exists {
+					interpolation.ResourceReference = newAddress
+				}
+				interpolation.Interpolation = rename(interpolation.Interpolation)
+				v[key] = interpolation
+				continue
+			}
+			renameInterpolations(item, renamed, rename)
+		}
+	case []interface{}:
+		for i, item := range v {
+			if interpolation, ok := item.(tf_export.InterpolationString); ok {
+				if newAddress, exists := renamed[interpolation.ResourceReference]; exists {
+					interpolation.ResourceReference = newAddress
+				}
+				interpolation.Interpolation = rename(interpolation.Interpolation)
+				v[i] = interpolation
+				continue
+			}
+			renameInterpolations(item, renamed, rename)
+		}
+	}
+}
+
+// getStepResourceTypes returns the resource types discovered by the step, the resources of other types in the state are not reported as not found
+func getStepResourceTypes(step resourceDiscoveryStep) map[string]bool {
+	resourceTypes := map[string]bool{}
+	if graphStep, ok := step.(*resourceDiscoveryWithGraph); ok {
+		for _, associations := range graphStep.resourceGraph {
+			for _, association := range associations {
+				resourceTypes[association.ResourceClass] = true
+			}
+		}
+		return resourceTypes
+	}
+	for _, resource := range step.getDiscoveredResources() {
+		resourceTypes[resource.TerraformClass] = true
+	}
+	return resourceTypes
+}
+
+// loadIncrementalVars adds the variables of the previous export, so that the variables used by the resources which are not written again are kept
+func loadIncrementalVars(outputDir string) error {
+	varsFile := filepath.Join(outputDir, globalvar.VarsFile)
+	content, err := ioutil.ReadFile(varsFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	file, diags := hclsyntax.ParseConfig(content, varsFile, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("[ERROR] unable to parse variables file %s: %s", varsFile, diags.Error())
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	for _, block := range body.Blocks {
+		if block.Type != "variable" || len(block.Labels) != 1 {
+			continue
+		}
+		if _, exists := tf_export.Vars[block.Labels[0]]; exists {
+			continue
+		}
+		if _, isSensitive := block.Body.Attributes["sensitive"]; isSensitive {
+			// the values of the sensitive variables are read from the secrets file
+			if _, exists := tf_export.SensitiveVars[block.Labels[0]]; !exists {
+				tf_export.SensitiveVars[block.Labels[0]] = ""
+			}
+			continue
+		}
+		defaultVal := ""
+		if attribute, exists := block.Body.Attributes["default"]; exists {
+			exprRange := attribute.Expr.Range()
+			defaultVal = string(content[exprRange.Start.Byte:exprRange.End.Byte])
+		}
+		tf_export.Vars[block.Labels[0]] = defaultVal
+	}
+	return nil
+}
+
+// getIncrementalImportConfigFileName returns the file with the temporary configuration to import the new resources, the existing configuration is not overwritten
+func (r *resourceDiscoveryBaseStep) getIncrementalImportConfigFileName() string {
+	return tf_export.GetConfigFileName(fmt.Sprintf("%s_import.tf", r.name))
+}
+
+func copyFile(source string, destination string) error {
+	content, err := ioutil.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(destination, content, 0644)
+}
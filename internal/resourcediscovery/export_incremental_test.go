@@ -0,0 +1,153 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+const incrementalTestState = `{
+  "version": 4,
+  "resources": [
+    {"mode": "managed", "type": "oci_core_vcn", "name": "my_vcn", "instances": [{"attributes": {"id": "ocid1.vcn.1"}}]},
+    {"mode": "managed", "type": "oci_core_vcn", "name": "vcn_new", "instances": [{"attributes": {"id": "ocid1.vcn.9"}}]},
+    {"mode": "managed", "type": "oci_core_subnet", "name": "gone", "instances": [{"attributes": {"id": "ocid1.subnet.9"}}]},
+    {"mode": "managed", "type": "oci_objectstorage_bucket", "name": "bucket", "instances": [{"attributes": {"id": "ocid1.bucket.1"}}]},
+    {"mode": "data", "type": "oci_identity_availability_domain", "name": "ad1", "instances": [{"attributes": {"id": "ocid1.ad.1"}}]}
+  ]
+}`
+
+// issue-routing-tag: terraform/default
+func TestUnitApplyIncrementalExport(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "incremental-export")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+	defer func(referenceMap map[string]string, resourceNameCount map[string]int, vars map[string]string) {
+		tf_export.ReferenceMap = referenceMap
+		tf_export.ResourceNameCount = resourceNameCount
+		tf_export.Vars = vars
+	}(tf_export.ReferenceMap, tf_export.ResourceNameCount, tf_export.Vars)
+	tf_export.ResourceNameCount = map[string]int{"vcn1": 1, "vcn9": 1, "vcn_new": 1, "subnet1": 1}
+	tf_export.Vars = map[string]string{"compartment_ocid": "\"ocid1.compartment.1\""}
+
+	ctx := &tf_export.ResourceDiscoveryContext{ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, Incremental: true}}
+	step := &resourceDiscoveryWithGraph{
+		resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "core"},
+		resourceGraph: tf_export.TerraformResourceGraph{"oci_identity_compartment": {
+			{TerraformResourceHints: &tf_export.TerraformResourceHints{ResourceClass: "oci_core_vcn"}},
+			{TerraformResourceHints: &tf_export.TerraformResourceHints{ResourceClass: "oci_core_subnet"}},
+		}},
+	}
+	newResource := func(id string, class string, name string, attributes map[string]interface{}) *tf_export.OCIResource {
+		return &tf_export.OCIResource{TerraformResource: tf_export.TerraformResource{Id: id, TerraformClass: class, TerraformName: name}, SourceAttributes: attributes}
+	}
+	existingVcn := newResource("ocid1.vcn.1", "oci_core_vcn", "vcn1", map[string]interface{}{})
+	renamedVcn := newResource("ocid1.vcn.9", "oci_core_vcn", "vcn9", map[string]interface{}{})
+	newVcn := newResource("ocid1.vcn.2", "oci_core_vcn", "vcn_new", map[string]interface{}{})
+	newSubnet := newResource("ocid1.subnet.1", "oci_core_subnet", "subnet1", map[string]interface{}{
+		"vcn_id": "ocid1.vcn.1",
+		"route_table_name": tf_export.InterpolationString{
+			ResourceReference: "oci_core_vcn.vcn_new",
+			Interpolation:     "oci_core_vcn.vcn_new.display_name",
+			Value:             "vcn",
+		},
+	})
+	step.discoveredResources = []*tf_export.OCIResource{existingVcn, renamedVcn, newVcn, newSubnet}
+	tf_export.ReferenceMap = map[string]string{
+		"ocid1.vcn.1":    "oci_core_vcn.vcn1.id",
+		"ocid1.vcn.9":    "oci_core_vcn.vcn9.id",
+		"ocid1.vcn.2":    "oci_core_vcn.vcn_new.id",
+		"ocid1.subnet.1": "oci_core_subnet.subnet1.id",
+	}
+
+	// the state of the previous export is required
+	assert.Error(t, applyIncrementalExport(ctx, []resourceDiscoveryStep{step}))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(outputDir, globalvar.DefaultStateFilename), []byte(incrementalTestState), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(outputDir, globalvar.VarsFile), []byte("variable compartment_ocid { default = \"ocid1.compartment.2\" }\nvariable shape { default = \"VM.Standard2.1\" }\n"), 0644))
+	assert.NoError(t, applyIncrementalExport(ctx, []resourceDiscoveryStep{step}))
+
+	// the resources in the state keep their names and are not written again
+	assert.Equal(t, "my_vcn", existingVcn.TerraformName)
+	assert.Equal(t, "vcn_new", renamedVcn.TerraformName)
+	assert.Equal(t, []*tf_export.OCIResource{newVcn, newSubnet}, step.getDiscoveredResources())
+
+	// the new resources do not reuse the names in the state
+	assert.Equal(t, "vcn_new_1", newVcn.TerraformName)
+	assert.Equal(t, "subnet1", newSubnet.TerraformName)
+	assert.Equal(t, map[string]string{
+		"ocid1.vcn.1":    "oci_core_vcn.my_vcn.id",
+		"ocid1.vcn.9":    "oci_core_vcn.vcn_new.id",
+		"ocid1.vcn.2":    "oci_core_vcn.vcn_new_1.id",
+		"ocid1.subnet.1": "oci_core_subnet.subnet1.id",
+	}, tf_export.ReferenceMap)
+	interpolation := newSubnet.SourceAttributes["route_table_name"].(tf_export.InterpolationString)
+	assert.Equal(t, "oci_core_vcn.vcn_new_1", interpolation.ResourceReference)
+	assert.Equal(t, "oci_core_vcn.vcn_new_1.display_name", interpolation.Interpolation)
+
+	// the variables of the previous export are kept
+	assert.Equal(t, "\"ocid1.compartment.1\"", tf_export.Vars["compartment_ocid"])
+	assert.Equal(t, "\"VM.Standard2.1\"", tf_export.Vars["shape"])
+
+	// only the resources of the exported services are reported as not found
+	summary := strings.Join(ctx.SummaryStatements, "\n")
+	assert.Contains(t, summary, "Kept 2 resources")
+	assert.Contains(t, summary, "oci_core_subnet.gone")
+	assert.NotContains(t, summary, "oci_objectstorage_bucket.bucket")
+	assert.NotContains(t, summary, "oci_identity_availability_domain.ad1")
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitWriteConfiguration_incremental(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+
+	outputDir, err := ioutil.TempDir("", "incremental-export")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	ctx := &tf_export.ResourceDiscoveryContext{ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, Incremental: true}}
+	step := &resourceDiscoveryBaseStep{ctx: ctx, name: "testing"}
+	step.discoveredResources = []*tf_export.OCIResource{{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.parent.2", TerraformClass: "oci_test_parent", TerraformName: "parent2"},
+		SourceAttributes:  map[string]interface{}{"a_string": "new"},
+		GetHclStringFn:    tf_export.GetHclStringFromGenericMap,
+	}}
+
+	existingConfig := "## This configuration was generated by terraform-provider-oci\n\nresource oci_test_parent parent1 {\n  # edited by hand\n  a_string = \"old\"\n}\n"
+	configFile := filepath.Join(outputDir, "testing.tf")
+	importConfigFile := filepath.Join(outputDir, "testing_import.tf")
+	assert.NoError(t, ioutil.WriteFile(configFile, []byte(existingConfig), 0644))
+
+	// the new resources are imported using a separate configuration file
+	assert.NoError(t, step.writeTmpConfigurationForImport())
+	assert.FileExists(t, importConfigFile)
+	content, err := ioutil.ReadFile(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, existingConfig, string(content))
+
+	assert.NoError(t, step.writeConfiguration())
+	assert.NoFileExists(t, importConfigFile)
+	content, err = ioutil.ReadFile(configFile)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), existingConfig), "got %s", string(content))
+	assert.Contains(t, string(content), "resource oci_test_parent parent2 {")
+	assert.Equal(t, 1, strings.Count(string(content), "generated by terraform-provider-oci"))
+}
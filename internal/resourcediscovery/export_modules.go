@@ -0,0 +1,115 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+/*
+generateServiceModules writes the discovered resources of each service as a child module when the layout is modules
+- each service is written to its own directory in the output_path, with its own variables
+- resources referring to resources of another service get a variable, which is wired to an output of the other module in the root module
+- the variables of the export, like compartment_ocid, are variables of the root module passed to each module
+*/
+func generateServiceModules(rootCtx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep) error {
+	modules, err := getServiceModules(rootCtx, steps)
+	if err != nil {
+		return err
+	}
+
+	exportedResources := map[string]*exportedResource{}
+	resourceModules := map[string]*exportModule{}
+	for _, module := range modules {
+		for _, resource := range module.steps[0].getDiscoveredResources() {
+			resourceModules[resource.Id] = module
+			if resource.TerraformTypeInfo != nil && resource.TerraformTypeInfo.IsDataSource {
+				continue
+			}
+			exportedResources[resource.Id] = &exportedResource{module: module, resource: resource}
+		}
+	}
+
+	// the references are scoped to the module, the references to the resources of other modules are added as variables
+	rootVars := tf_export.Vars
+	for _, module := range modules {
+		for id, reference := range tf_export.ReferenceMap {
+			if owner, exists := resourceModules[id]; exists && owner != module {
+				continue
+			}
+			module.referenceMap[id] = reference
+		}
+		for variableName, value := range rootVars {
+			module.vars[variableName] = value
+			module.moduleInputs[variableName] = tf_export.TfHclVersionvar.GetVarHclString(variableName)
+		}
+	}
+
+	for _, module := range modules {
+		if err := writeModuleConfiguration(module, exportedResources); err != nil {
+			return err
+		}
+		rootCtx.DiscoveredResources = append(rootCtx.DiscoveredResources, module.ctx.DiscoveredResources...)
+	}
+
+	for _, module := range modules {
+		if err := generateOutputsFile(module); err != nil {
+			return err
+		}
+	}
+
+	if err := generateRootModule(rootCtx, modules, rootVars); err != nil {
+		return err
+	}
+	rootCtx.SummaryStatements = append(rootCtx.SummaryStatements, fmt.Sprintf("Exported %d services as child modules. Root module generated under '%s'", len(modules), *rootCtx.OutputDir))
+	return nil
+}
+
+// getServiceModules returns a module for each step with discovered resources, the step writes its configuration to the directory of the module
+func getServiceModules(rootCtx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep) ([]*exportModule, error) {
+	var modules []*exportModule
+	moduleNames := map[string]int{}
+	for _, step := range steps {
+		if len(step.getDiscoveredResources()) == 0 {
+			continue
+		}
+		name := step.getBaseStep().name
+
+		outputDir := filepath.Join(*rootCtx.OutputDir, name)
+		if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("[ERROR] unable to create output directory %s for service %s: %s", outputDir, name, err.Error())
+		}
+
+		args := *rootCtx.ExportCommandArgs
+		args.OutputDir = &outputDir
+		ctx, err := createResourceDiscoveryContext(rootCtx.Clients, &args, rootCtx.TenancyOcid)
+		if err != nil {
+			return nil, err
+		}
+		// the resource IDs to export are validated by the root context
+		ctx.ExpectedResourceIds = map[string]bool{}
+		step.getBaseStep().ctx = ctx
+
+		module := &exportModule{
+			id:            *rootCtx.CompartmentId,
+			name:          name,
+			path:          name,
+			moduleName:    getUniqueModuleName(name, moduleNames),
+			ctx:           ctx,
+			steps:         []resourceDiscoveryStep{step},
+			referenceMap:  map[string]string{},
+			vars:          map[string]string{},
+			moduleInputs:  map[string]string{},
+			moduleOutputs: map[string]string{},
+		}
+		utils.Debugf("[DEBUG] writing %d '%s' resources to module '%s'", len(step.getDiscoveredResources()), name, module.moduleName)
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oracle/terraform-provider-oci/internal/acctest"
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitGenerateServiceModules(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+	exportConfigProvider = acctest.MockConfigurationProvider{}
+	sem = make(chan struct{}, 1)
+
+	outputDir, err := ioutil.TempDir("", "modules-export")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	defer func(referenceMap map[string]string, vars map[string]string, sensitiveVars map[string]string) {
+		tf_export.ReferenceMap = referenceMap
+		tf_export.Vars = vars
+		tf_export.SensitiveVars = sensitiveVars
+	}(tf_export.ReferenceMap, tf_export.Vars, tf_export.SensitiveVars)
+
+	compartmentId := "ocid1.compartment.1"
+	tf_export.Vars = map[string]string{"compartment_ocid": "\"ocid1.compartment.1\""}
+	tf_export.ReferenceMap = map[string]string{
+		compartmentId:    "var.compartment_ocid",
+		"ocid1.parent.1": "oci_test_parent.parent1.id",
+		"ocid1.child.1":  "oci_test_child.child1.id",
+	}
+	ctx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, CompartmentId: &compartmentId, Parallelism: 1, Layout: tf_export.LayoutModules},
+		ErrorList:         tf_export.ErrorList{Errors: []*tf_export.ResourceDiscoveryError{}},
+	}
+
+	parent := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.parent.1", TerraformClass: "oci_test_parent", TerraformName: "parent1"},
+		CompartmentId:     compartmentId,
+		SourceAttributes:  map[string]interface{}{"compartment_id": compartmentId, "a_string": "network"},
+		GetHclStringFn:    tf_export.GetHclStringFromGenericMap,
+	}
+	child := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.child.1", TerraformClass: "oci_test_child", TerraformName: "child1"},
+		CompartmentId:     compartmentId,
+		SourceAttributes:  map[string]interface{}{"compartment_id": compartmentId, "parent_id": "ocid1.parent.1"},
+		GetHclStringFn:    tf_export.GetHclStringFromGenericMap,
+	}
+	networkStep := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "network", discoveredResources: []*tf_export.OCIResource{parent}}}
+	appStep := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "app", discoveredResources: []*tf_export.OCIResource{child}}}
+	emptyStep := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "identity"}}
+
+	assert.NoError(t, generateServiceModules(ctx, []resourceDiscoveryStep{networkStep, appStep, emptyStep}))
+	assert.Len(t, ctx.DiscoveredResources, 2)
+	assert.Equal(t, filepath.Join(outputDir, "app"), *appStep.ctx.OutputDir)
+
+	// the references to the resources of the other service are variables of the module
+	config, err := ioutil.ReadFile(filepath.Join(outputDir, "app", "app.tf"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(config), "parent_id      = var.network_parent1_id")
+	assert.Contains(t, string(config), "compartment_id = var.compartment_ocid")
+	vars, err := ioutil.ReadFile(filepath.Join(outputDir, "app", globalvar.VarsFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(vars), "variable network_parent1_id { default = \"ocid1.parent.1\" }\n")
+
+	config, err = ioutil.ReadFile(filepath.Join(outputDir, "network", "network.tf"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(config), "resource oci_test_parent parent1 {")
+	outputs, err := ioutil.ReadFile(filepath.Join(outputDir, "network", globalvar.OutputsFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(outputs), "output parent1_id {\n  value = oci_test_parent.parent1.id\n}")
+
+	_, err = os.Stat(filepath.Join(outputDir, "identity"))
+	assert.True(t, os.IsNotExist(err), "no module should be generated for a service without resources")
+
+	// the root module wires the outputs of the modules to the variables of the other modules
+	modules, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.ModulesFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(modules), "module network {\n  source           = \"./network\"\n  compartment_ocid = var.compartment_ocid\n}")
+	assert.Contains(t, string(modules), "module app {\n  source             = \"./app\"\n  compartment_ocid   = var.compartment_ocid\n  network_parent1_id = module.network.parent1_id\n}")
+	vars, err = ioutil.ReadFile(filepath.Join(outputDir, globalvar.VarsFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(vars), "variable compartment_ocid { default = \"ocid1.compartment.1\" }\n")
+	assert.Contains(t, string(vars), "variable region {")
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+const (
+	nativeStateFormatVersion    = 4
+	nativeStateTerraformVersion = "0.13.0"
+	// the generated configuration does not declare required_providers, so Terraform resolves the oci provider to the default namespace
+	nativeStateProviderSource = "registry.terraform.io/hashicorp/oci"
+)
+
+/*
+generateNativeState is used if native_state is set, instead of running terraform import for each of the discovered resources
+- builds the state v4 JSON of each resource from the attributes already read by resource discovery and the resource schema
+- does not require the terraform CLI and does not read the resources again from the services
+The resources are refreshed on the next plan or apply, so the attributes not returned by the datasources are read then
+*/
+func generateNativeState(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep) error {
+	defer elapsed("generating state from the discovered resources", nil, 0)()
+	stateOutputFile := filepath.Join(*ctx.OutputDir, globalvar.DefaultStateFilename)
+
+	state, err := newNativeState()
+	if err != nil {
+		return err
+	}
+	importedResources := map[string]bool{}
+	if ctx.Incremental {
+		// the new resources are added to the state of the previous export
+		if state, err = readNativeState(stateOutputFile); err != nil {
+			return err
+		}
+		importedResources = getImportedResources(stateOutputFile)
+	}
+	resources, _ := state["resources"].([]interface{})
+
+	for _, step := range steps {
+		for _, resource := range step.getDiscoveredResources() {
+			ctx.DiscoveredResources = append(ctx.DiscoveredResources, resource)
+			if importedResources[resource.GetTerraformReference()] {
+				utils.Debugf("[DEBUG] skip adding '%s' to the state since it is in the state of the previous export", resource.GetTerraformReference())
+				continue
+			}
+			stateResource, err := getNativeStateResource(resource)
+			if err != nil {
+				utils.Logf("[ERROR] unable to add resource '%s' to the state: %s", resource.GetTerraformReference(), err.Error())
+				addImportError(ctx, resource, fmt.Errorf("[ERROR] unable to add resource '%s' to the state: %s Any references to this resource have been replaced with hard coded values in generated configurations", resource.GetTerraformReference(), err.Error()))
+				continue
+			}
+			if stateResource != nil {
+				resources = append(resources, stateResource)
+			}
+		}
+	}
+
+	if len(resources) == 0 {
+		utils.Logf("[INFO] ~~~~~~ no resources were added to the state file ~~~~~~")
+		return nil
+	}
+	state["resources"] = resources
+
+	stateBytes, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(stateOutputFile, stateBytes, 0644); err != nil {
+		return fmt.Errorf("[ERROR] error writing state file at %s: %s", stateOutputFile, err.Error())
+	}
+	utils.Logf("[INFO] state written to file at: %s", stateOutputFile)
+	return nil
+}
+
+func newNativeState() (map[string]interface{}, error) {
+	lineage, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to generate the lineage of the state: %s", err.Error())
+	}
+	return map[string]interface{}{
+		"version":           nativeStateFormatVersion,
+		"terraform_version": nativeStateTerraformVersion,
+		"serial":            1,
+		"lineage":           lineage,
+		"outputs":           map[string]interface{}{},
+		"resources":         []interface{}{},
+	}, nil
+}
+
+// readNativeState reads an existing state file to add resources to it, a new state is returned if the file does not exist
+func readNativeState(stateFile string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return newNativeState()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&state); err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to read state file %s: %s", stateFile, err.Error())
+	}
+	if serial, ok := state["serial"].(json.Number); ok {
+		if value, err := serial.Int64(); err == nil {
+			state["serial"] = value + 1
+		}
+	}
+	return state, nil
+}
+
+// getNativeStateResource returns the state of a discovered resource, nil is returned for the resources which are not imported e.g. datasources
+func getNativeStateResource(resource *tf_export.OCIResource) (map[string]interface{}, error) {
+	resourceSchema, exists := tf_export.ResourcesMap[resource.TerraformClass]
+	if !exists || (resource.TerraformTypeInfo != nil && resource.TerraformTypeInfo.IsDataSource) {
+		utils.Debugf("[DEBUG] skip adding '%s' to the state since it is not a Terraform OCI resource", resource.GetTerraformReference())
+		return nil, nil
+	}
+	if resourceSchema.Importer == nil {
+		utils.Logf("[WARN] unable to add '%s' to the state because import is not supported for '%s'", resource.GetTerraformReference(), resource.TerraformClass)
+		return nil, nil
+	}
+
+	attributes, err := getNativeStateObject(resource.SourceAttributes, resourceSchema.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := resource.SourceAttributes["id"].(string); ok && id != "" {
+		attributes["id"] = id
+	} else {
+		attributes["id"] = resource.Id
+	}
+
+	provider := fmt.Sprintf("provider[%q]", nativeStateProviderSource)
+	if alias := strings.TrimPrefix(resource.Provider, "oci."); resource.Provider != "" && alias != resource.Provider {
+		provider = fmt.Sprintf("%s.%s", provider, alias)
+	}
+
+	return map[string]interface{}{
+		"mode":     "managed",
+		"type":     resource.TerraformClass,
+		"name":     resource.TerraformName,
+		"provider": provider,
+		"instances": []interface{}{
+			map[string]interface{}{
+				"schema_version":       resourceSchema.SchemaVersion,
+				"attributes":           attributes,
+				"sensitive_attributes": []interface{}{},
+			},
+		},
+	}, nil
+}
+
+// getNativeStateObject converts the attributes of a resource or nested block to their state representation, the attributes which are not set are null
+func getNativeStateObject(sourceAttributes map[string]interface{}, resourceSchema map[string]*schema.Schema) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(resourceSchema))
+	for attribute, tfSchema := range resourceSchema {
+		value, err := getNativeStateValue(sourceAttributes[attribute], tfSchema)
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s': %s", attribute, err.Error())
+		}
+		result[attribute] = value
+	}
+	return result, nil
+}
+
+func getNativeStateValue(value interface{}, tfSchema *schema.Schema) (interface{}, error) {
+	if interpolation, ok := value.(tf_export.InterpolationString); ok {
+		value = interpolation.Value
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch tfSchema.Type {
+	case schema.TypeString:
+		return fmt.Sprintf("%v", value), nil
+	case schema.TypeBool:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+		return strconv.ParseBool(fmt.Sprintf("%v", value))
+	case schema.TypeInt:
+		switch v := value.(type) {
+		case int, int32, int64:
+			return v, nil
+		case float64:
+			return int64(v), nil
+		}
+		return strconv.ParseInt(fmt.Sprintf("%v", value), 10, 64)
+	case schema.TypeFloat:
+		switch v := value.(type) {
+		case float32, float64, int, int64:
+			return v, nil
+		}
+		return strconv.ParseFloat(fmt.Sprintf("%v", value), 64)
+	case schema.TypeMap:
+		items, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unsupported map value type %T", value)
+		}
+		elemSchema, ok := tfSchema.Elem.(*schema.Schema)
+		if !ok {
+			elemSchema = &schema.Schema{Type: schema.TypeString}
+		}
+		result := make(map[string]interface{}, len(items))
+		for key, item := range items {
+			stateValue, err := getNativeStateValue(item, elemSchema)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = stateValue
+		}
+		return result, nil
+	case schema.TypeList, schema.TypeSet:
+		var items []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			items = v
+		case []map[string]interface{}:
+			for _, item := range v {
+				items = append(items, item)
+			}
+		case *schema.Set:
+			items = v.List()
+		case []string:
+			for _, item := range v {
+				items = append(items, item)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported list value type %T", value)
+		}
+
+		result := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			var stateValue interface{}
+			var err error
+			switch elem := tfSchema.Elem.(type) {
+			case *schema.Resource:
+				itemMap, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("unsupported nested block value type %T", item)
+				}
+				stateValue, err = getNativeStateObject(itemMap, elem.Schema)
+			case *schema.Schema:
+				stateValue, err = getNativeStateValue(item, elem)
+			default:
+				stateValue, err = getNativeStateValue(item, &schema.Schema{Type: schema.TypeString})
+			}
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, stateValue)
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unsupported schema type %v", tfSchema.Type)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitGenerateNativeState(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "native-state")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	defer func(resourcesMap map[string]*schema.Resource) { tf_export.ResourcesMap = resourcesMap }(tf_export.ResourcesMap)
+	importer := &schema.ResourceImporter{State: schema.ImportStatePassthrough}
+	tf_export.ResourcesMap = map[string]*schema.Resource{
+		"oci_core_vcn": {
+			Importer:      importer,
+			SchemaVersion: 1,
+			Schema: map[string]*schema.Schema{
+				"cidr_blocks":   {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+				"display_name":  {Type: schema.TypeString, Optional: true},
+				"freeform_tags": {Type: schema.TypeMap, Optional: true, Elem: schema.TypeString},
+				"is_ipv6":       {Type: schema.TypeBool, Optional: true},
+				"mtu":           {Type: schema.TypeInt, Optional: true},
+				"dhcp_options": {Type: schema.TypeList, Optional: true, Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+					"type":        {Type: schema.TypeString, Required: true},
+					"server_type": {Type: schema.TypeString, Optional: true},
+				}}},
+			},
+		},
+		"oci_core_no_import": {Schema: map[string]*schema.Schema{"display_name": {Type: schema.TypeString, Optional: true}}},
+		"oci_core_invalid": {Importer: importer, Schema: map[string]*schema.Schema{
+			"cidr_blocks": {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		}},
+	}
+
+	ctx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, GenerateState: true, NativeState: true},
+		ErrorList:         tf_export.ErrorList{Errors: []*tf_export.ResourceDiscoveryError{}},
+	}
+	parent := &tf_export.OCIResource{TerraformResource: tf_export.TerraformResource{TerraformName: "export"}}
+	vcn := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.vcn.1", TerraformClass: "oci_core_vcn", TerraformName: "vcn1"},
+		SourceAttributes: map[string]interface{}{
+			"cidr_blocks":   []interface{}{"10.0.0.0/16"},
+			"display_name":  tf_export.InterpolationString{Value: "vcn1", Interpolation: "var.name"},
+			"freeform_tags": map[string]interface{}{"env": "dev"},
+			"is_ipv6":       false,
+			"mtu":           "9000",
+			"dhcp_options":  []interface{}{map[string]interface{}{"type": "DomainNameServer"}},
+		},
+		Parent: parent,
+	}
+	ad := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ad1", TerraformClass: "oci_identity_availability_domain", TerraformName: "ad1",
+			TerraformTypeInfo: &tf_export.TerraformResourceHints{IsDataSource: true}},
+		Parent: parent,
+	}
+	noImport := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.noimport.1", TerraformClass: "oci_core_no_import", TerraformName: "no_import"},
+		SourceAttributes:  map[string]interface{}{},
+		Parent:            parent,
+	}
+	invalid := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.invalid.1", TerraformClass: "oci_core_invalid", TerraformName: "invalid"},
+		SourceAttributes:  map[string]interface{}{"cidr_blocks": 10},
+		Parent:            parent,
+	}
+	step := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "core"}}
+	step.discoveredResources = []*tf_export.OCIResource{vcn, ad, noImport, invalid}
+
+	assert.NoError(t, generateNativeState(ctx, []resourceDiscoveryStep{step}))
+	assert.Len(t, ctx.DiscoveredResources, 4)
+
+	// the resources which cannot be converted are reported like the resources failing to import
+	assert.True(t, invalid.IsErrorResource)
+	assert.True(t, ctx.IsImportError)
+	assert.Len(t, ctx.ErrorList.Errors, 1)
+
+	content, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.DefaultStateFilename))
+	assert.NoError(t, err)
+	var state map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &state))
+	assert.Equal(t, float64(4), state["version"])
+	assert.Equal(t, float64(1), state["serial"])
+	assert.NotEmpty(t, state["lineage"])
+	resources := state["resources"].([]interface{})
+	assert.Len(t, resources, 1)
+
+	resource := resources[0].(map[string]interface{})
+	assert.Equal(t, "managed", resource["mode"])
+	assert.Equal(t, "oci_core_vcn", resource["type"])
+	assert.Equal(t, "vcn1", resource["name"])
+	assert.Equal(t, "provider[\"registry.terraform.io/hashicorp/oci\"]", resource["provider"])
+	instance := resource["instances"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(1), instance["schema_version"])
+	assert.Equal(t, map[string]interface{}{
+		"id":            "ocid1.vcn.1",
+		"cidr_blocks":   []interface{}{"10.0.0.0/16"},
+		"display_name":  "vcn1",
+		"freeform_tags": map[string]interface{}{"env": "dev"},
+		"is_ipv6":       false,
+		"mtu":           float64(9000),
+		"dhcp_options":  []interface{}{map[string]interface{}{"type": "DomainNameServer", "server_type": nil}},
+	}, instance["attributes"])
+
+	// the new resources of an incremental export are added to the existing state
+	ctx.Incremental = true
+	ctx.DiscoveredResources = nil
+	vcn2 := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.vcn.2", TerraformClass: "oci_core_vcn", TerraformName: "vcn2", ImportId: "ocid1.vcn.2"},
+		SourceAttributes:  map[string]interface{}{"display_name": "vcn2"},
+		Parent:            parent,
+	}
+	step.discoveredResources = []*tf_export.OCIResource{vcn, vcn2}
+	assert.NoError(t, generateNativeState(ctx, []resourceDiscoveryStep{step}))
+
+	content, err = ioutil.ReadFile(filepath.Join(outputDir, globalvar.DefaultStateFilename))
+	assert.NoError(t, err)
+	var incrementalState map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &incrementalState))
+	assert.Equal(t, state["lineage"], incrementalState["lineage"])
+	assert.Equal(t, float64(2), incrementalState["serial"])
+	resources = incrementalState["resources"].([]interface{})
+	assert.Len(t, resources, 2)
+	assert.Equal(t, "vcn1", resources[0].(map[string]interface{})["name"])
+	assert.Equal(t, "vcn2", resources[1].(map[string]interface{})["name"])
+}
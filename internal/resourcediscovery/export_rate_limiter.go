@@ -0,0 +1,199 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	oci_common "github.com/oracle/oci-go-sdk/v65/common"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+// the rate of a service is not lowered below this when its requests are throttled
+const minExportRateLimit = 0.5
+
+/*
+exportRateLimiterVar is shared by the clients used by export if rate_limit is set
+The requests to each service endpoint are limited by a token bucket, and the number of concurrent requests to the endpoint is adapted:
+- the concurrency and the rate are halved when a request is throttled with 429 TooManyRequests
+- they grow again by a step after as many requests as the concurrency succeeded, up to the concurrency of the export and rate_limit
+The retries of the throttled requests by the retry policy of the clients go through the limiter too
+*/
+var exportRateLimiterVar *exportRateLimiter
+
+type exportRateLimiter struct {
+	maxRate        float64 // requests per second to each service endpoint
+	maxConcurrency int
+	lock           sync.Mutex
+	services       map[string]*serviceRateLimiter // service endpoint host e.g. iaas.us-ashburn-1.oraclecloud.com to its limiter
+}
+
+type serviceRateLimiter struct {
+	lock           sync.Mutex
+	released       *sync.Cond
+	maxRate        float64
+	rate           float64
+	tokens         float64
+	lastRefill     time.Time
+	maxConcurrency int
+	concurrency    int
+	inFlight       int
+	successes      int // requests succeeded since the concurrency was last changed
+
+	requests     int
+	throttled    int
+	firstRequest time.Time
+	lastRequest  time.Time
+}
+
+// rateLimitedDispatcher is the HTTP client of an OCI client, it waits for the limiter of the service endpoint before each request
+type rateLimitedDispatcher struct {
+	limiter    *exportRateLimiter
+	dispatcher oci_common.HTTPRequestDispatcher
+}
+
+func newExportRateLimiter(maxRate float64, maxConcurrency int) *exportRateLimiter {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	return &exportRateLimiter{
+		maxRate:        maxRate,
+		maxConcurrency: maxConcurrency,
+		services:       map[string]*serviceRateLimiter{},
+	}
+}
+
+// wrap returns the HTTP client of an OCI client limited by the rate limiter
+func (l *exportRateLimiter) wrap(dispatcher oci_common.HTTPRequestDispatcher) oci_common.HTTPRequestDispatcher {
+	if _, isWrapped := dispatcher.(*rateLimitedDispatcher); isWrapped {
+		return dispatcher
+	}
+	return &rateLimitedDispatcher{limiter: l, dispatcher: dispatcher}
+}
+
+func (l *exportRateLimiter) getService(host string) *serviceRateLimiter {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	service, exists := l.services[host]
+	if !exists {
+		service = &serviceRateLimiter{
+			maxRate:        l.maxRate,
+			rate:           l.maxRate,
+			tokens:         math.Max(1, l.maxRate),
+			lastRefill:     time.Now(),
+			maxConcurrency: l.maxConcurrency,
+			concurrency:    l.maxConcurrency,
+		}
+		service.released = sync.NewCond(&service.lock)
+		l.services[host] = service
+	}
+	return service
+}
+
+func (d *rateLimitedDispatcher) Do(request *http.Request) (*http.Response, error) {
+	service := d.limiter.getService(request.URL.Host)
+	service.acquire()
+	response, err := d.dispatcher.Do(request)
+	service.release(response != nil && response.StatusCode == http.StatusTooManyRequests)
+	return response, err
+}
+
+// acquire waits until a request can be made to the service without exceeding its concurrency and its rate
+func (s *serviceRateLimiter) acquire() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for {
+		for s.inFlight >= s.concurrency {
+			s.released.Wait()
+		}
+
+		now := time.Now()
+		// the bucket holds at most a second of requests
+		s.tokens = math.Min(math.Max(1, s.rate), s.tokens+now.Sub(s.lastRefill).Seconds()*s.rate)
+		s.lastRefill = now
+		if s.tokens >= 1 {
+			s.tokens--
+			s.inFlight++
+			if s.firstRequest.IsZero() {
+				s.firstRequest = now
+			}
+			s.lastRequest = now
+			return
+		}
+
+		wait := time.Duration((1 - s.tokens) / s.rate * float64(time.Second))
+		s.lock.Unlock()
+		time.Sleep(wait)
+		s.lock.Lock()
+	}
+}
+
+// release records the result of a request and adapts the concurrency and the rate of the service
+func (s *serviceRateLimiter) release(throttled bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.inFlight--
+	s.requests++
+
+	if throttled {
+		s.throttled++
+		s.successes = 0
+		s.concurrency = int(math.Max(1, float64(s.concurrency/2)))
+		s.rate = math.Max(minExportRateLimit, s.rate/2)
+		s.tokens = 0
+		utils.Debugf("[DEBUG] request throttled, lowered concurrency to %d and rate to %.1f requests/s", s.concurrency, s.rate)
+	} else {
+		s.successes++
+		if s.successes >= s.concurrency {
+			s.successes = 0
+			s.concurrency = int(math.Min(float64(s.maxConcurrency), float64(s.concurrency+1)))
+			s.rate = math.Min(s.maxRate, s.rate+math.Max(minExportRateLimit, s.maxRate/10))
+		}
+	}
+	s.released.Broadcast()
+}
+
+// getSummaryStatements returns the observed rate of requests to each service endpoint, sorted by endpoint
+func (l *exportRateLimiter) getSummaryStatements() []string {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	hosts := make([]string, 0, len(l.services))
+	for host := range l.services {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var statements []string
+	for _, host := range hosts {
+		service := l.services[host]
+		service.lock.Lock()
+		if service.requests == 0 {
+			service.lock.Unlock()
+			continue
+		}
+		observedRate := float64(service.requests)
+		if duration := service.lastRequest.Sub(service.firstRequest).Seconds(); duration > 1 {
+			observedRate = float64(service.requests) / duration
+		}
+		statements = append(statements, fmt.Sprintf("API requests to '%s': %d at %.1f requests/s, %d throttled, final rate limit %.1f requests/s and concurrency %d",
+			host, service.requests, observedRate, service.throttled, service.rate, service.concurrency))
+		service.lock.Unlock()
+	}
+	return statements
+}
+
+func addRateLimiterSummary(ctx *tf_export.ResourceDiscoveryContext) {
+	ctx.SummaryStatements = append(ctx.SummaryStatements, exportRateLimiterVar.getSummaryStatements()...)
+}
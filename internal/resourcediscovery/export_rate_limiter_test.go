@@ -0,0 +1,80 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRateLimitedDispatcher struct {
+	statusCodes []int // the status codes of the responses in order, the last one is repeated
+	requests    int
+}
+
+func (d *testRateLimitedDispatcher) Do(request *http.Request) (*http.Response, error) {
+	statusCode := d.statusCodes[len(d.statusCodes)-1]
+	if d.requests < len(d.statusCodes) {
+		statusCode = d.statusCodes[d.requests]
+	}
+	d.requests++
+	return &http.Response{StatusCode: statusCode, Request: request}, nil
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitExportRateLimiter(t *testing.T) {
+	limiter := newExportRateLimiter(100, 8)
+	dispatcher := &testRateLimitedDispatcher{statusCodes: []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK}}
+	client := limiter.wrap(dispatcher)
+	assert.Equal(t, client, limiter.wrap(client), "the client is wrapped once")
+
+	request, _ := http.NewRequest(http.MethodGet, "https://iaas.us-ashburn-1.oraclecloud.com/20160918/vcns", nil)
+	for i := 0; i < 2; i++ {
+		response, err := client.Do(request)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	}
+
+	// the concurrency and the rate are halved for each throttled request
+	service := limiter.getService("iaas.us-ashburn-1.oraclecloud.com")
+	assert.Equal(t, 2, service.concurrency)
+	assert.Equal(t, 25.0, service.rate)
+
+	// and grow again as the requests succeed
+	for i := 0; i < 2; i++ {
+		_, err := client.Do(request)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, service.concurrency)
+	assert.Equal(t, 35.0, service.rate)
+	assert.Equal(t, 0, service.inFlight)
+
+	// the other services are not limited by the throttled service
+	assert.Equal(t, 8, limiter.getService("identity.us-ashburn-1.oraclecloud.com").concurrency)
+
+	// the services without requests are not in the summary
+	statements := limiter.getSummaryStatements()
+	assert.Len(t, statements, 1)
+	assert.True(t, strings.HasPrefix(statements[0], "API requests to 'iaas.us-ashburn-1.oraclecloud.com': 4 at"))
+	assert.Contains(t, statements[0], "2 throttled, final rate limit 35.0 requests/s and concurrency 3")
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitExportRateLimiterTokenBucket(t *testing.T) {
+	limiter := newExportRateLimiter(10, 4)
+	client := limiter.wrap(&testRateLimitedDispatcher{statusCodes: []int{http.StatusOK}})
+	request, _ := http.NewRequest(http.MethodGet, "https://iaas.us-ashburn-1.oraclecloud.com/20160918/vcns", nil)
+
+	// the bucket allows a second of requests at once, the next requests wait for the bucket to refill
+	start := time.Now()
+	for i := 0; i < 12; i++ {
+		_, err := client.Do(request)
+		assert.NoError(t, err)
+	}
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "requests were not limited, took %v", time.Since(start))
+}
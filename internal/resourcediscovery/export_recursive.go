@@ -0,0 +1,435 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	oci_common "github.com/oracle/oci-go-sdk/v65/common"
+	oci_identity "github.com/oracle/oci-go-sdk/v65/identity"
+
+	tf_client "github.com/oracle/terraform-provider-oci/internal/client"
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/tfresource"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+/*
+exportModule holds the discovery results written to a child module, a compartment in a recursive export or a service with layout modules
+Each module is written to its own directory, which is called as a module from the root module in the output_path
+*/
+type exportModule struct {
+	id         string
+	name       string
+	path       string // directory of the module relative to the output_path, follows the compartment hierarchy in a recursive export
+	moduleName string
+
+	ctx          *tf_export.ResourceDiscoveryContext
+	steps        []resourceDiscoveryStep
+	referenceMap map[string]string
+	vars         map[string]string
+
+	moduleInputs  map[string]string // variable name to the output of the module it is wired to in the root module
+	sensitiveVars map[string]string // sensitive variables of the module, their values are set from the secrets file of the root module
+	moduleOutputs map[string]string // output name to the reference of the resource referred by other modules
+}
+
+// exportedResource is a resource discovered in one of the modules of the export
+type exportedResource struct {
+	module   *exportModule
+	resource *tf_export.OCIResource
+}
+
+/*
+runRecursiveExportCommand exports the compartment in the context along with all the active compartments in its subtree
+- discovers the resources of every compartment before writing any configuration, so references to resources in other compartments are known
+- writes the configuration of each compartment to its own directory as a child module
+- resources referring to resources of another compartment get a variable, which is wired to an output of the other module in the root module
+*/
+func runRecursiveExportCommand(rootCtx *tf_export.ResourceDiscoveryContext) (error, Status) {
+	exportStart := time.Now()
+	defer elapsed("entire recursive export command", nil, 0)()
+
+	compartments, err := getCompartmentSubtree(rootCtx.Clients, *rootCtx.CompartmentId)
+	if err != nil {
+		utils.Logln(err.Error())
+		return err, StatusFail
+	}
+	utils.Logf("[INFO] exporting %d compartments recursively", len(compartments))
+
+	exportedResources := map[string]*exportedResource{}
+	for _, compartment := range compartments {
+		if err := discoverCompartment(rootCtx, compartment); err != nil {
+			utils.Logln(err.Error())
+			return err, StatusFail
+		}
+
+		for _, step := range compartment.steps {
+			for _, resource := range step.getDiscoveredResources() {
+				if resource.TerraformTypeInfo != nil && resource.TerraformTypeInfo.IsDataSource {
+					continue
+				}
+				if _, exists := exportedResources[resource.Id]; !exists {
+					exportedResources[resource.Id] = &exportedResource{module: compartment, resource: resource}
+				}
+			}
+		}
+	}
+
+	var errs *multierror.Error
+	status := StatusSuccess
+	for _, compartment := range compartments {
+		if err := writeModuleConfiguration(compartment, exportedResources); err != nil {
+			utils.Logln(err.Error())
+			return err, StatusFail
+		}
+
+		if len(compartment.ctx.ErrorList.Errors) > 0 {
+			err, _ := getListOfNotDiscoveredResources(compartment.ctx)
+			errs = multierror.Append(errs, fmt.Errorf("compartment '%s': %s", compartment.path, err.Error()))
+			status = StatusPartialSuccess
+		}
+	}
+
+	for _, compartment := range compartments {
+		if err := generateOutputsFile(compartment); err != nil {
+			utils.Logln(err.Error())
+			return err, StatusFail
+		}
+	}
+
+	if err := generateRootModule(rootCtx, compartments, nil); err != nil {
+		utils.Logln(err.Error())
+		return err, StatusFail
+	}
+
+	for _, statement := range exportRateLimiterVar.getSummaryStatements() {
+		utils.Logln(utils.Green(statement))
+	}
+	utils.Logln(utils.Green(fmt.Sprintf("Exported %d compartments. Root module generated under '%s'", len(compartments), *rootCtx.OutputDir)))
+	utils.Logln(utils.Green(fmt.Sprintf("Total time taken by entire recursive export: %v", time.Since(exportStart))))
+	return errs.ErrorOrNil(), status
+}
+
+/*
+getCompartmentSubtree returns the compartment and all the active compartments in its subtree
+The compartments are returned parent first and the siblings are sorted by name, so that the export is deterministic
+*/
+func getCompartmentSubtree(clients *tf_client.OracleClients, compartmentId string) ([]*exportModule, error) {
+	response, err := identityClientGetCompartmentVar(clients, oci_identity.GetCompartmentRequest{
+		CompartmentId: &compartmentId,
+		RequestMetadata: oci_common.RequestMetadata{
+			RetryPolicy: tfresource.GetRetryPolicy(true, "identity"),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] could not get compartment %s: %v", compartmentId, err)
+	}
+
+	root := &exportModule{id: compartmentId, name: compartmentId}
+	if response.Name != nil {
+		root.name = *response.Name
+	}
+	root.path = root.name
+
+	moduleNames := map[string]int{}
+	root.moduleName = getUniqueModuleName(root.path, moduleNames)
+
+	result := []*exportModule{root}
+	for i := 0; i < len(result); i++ {
+		parent := result[i]
+		children, err := listChildCompartments(clients, parent.id)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range children {
+			compartment := &exportModule{
+				id:   *child.Id,
+				name: *child.Name,
+				path: filepath.Join(parent.path, *child.Name),
+			}
+			compartment.moduleName = getUniqueModuleName(compartment.path, moduleNames)
+			result = append(result, compartment)
+		}
+	}
+	return result, nil
+}
+
+func listChildCompartments(clients *tf_client.OracleClients, parentId string) ([]oci_identity.Compartment, error) {
+	req := oci_identity.ListCompartmentsRequest{
+		CompartmentId:  &parentId,
+		LifecycleState: oci_identity.CompartmentLifecycleStateActive,
+	}
+
+	var result []oci_identity.Compartment
+	for {
+		resp, err := identityClientListCompartmentsVar(clients, req)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] could not list child compartments of %s: %v", parentId, err)
+		}
+
+		for _, compartment := range resp.Items {
+			if compartment.Id != nil && compartment.Name != nil {
+				result = append(result, compartment)
+			}
+		}
+
+		if resp.OpcNextPage == nil {
+			break
+		}
+		req.Page = resp.OpcNextPage
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return *result[i].Name < *result[j].Name
+	})
+	return result, nil
+}
+
+// getUniqueModuleName converts the compartment path to a valid module name e.g. app/web-tier to app_web-tier
+func getUniqueModuleName(path string, moduleNames map[string]int) string {
+	moduleName := regexp.MustCompile(`[^a-zA-Z0-9\-\_]+`).ReplaceAllString(filepath.ToSlash(path), "_")
+	if matched, _ := regexp.MatchString(`^[a-zA-Z_]`, moduleName); !matched {
+		moduleName = fmt.Sprintf("compartment_%s", moduleName)
+	}
+
+	if count, exists := moduleNames[moduleName]; exists {
+		moduleNames[moduleName] = count + 1
+		moduleName = fmt.Sprintf("%s_%d", moduleName, count)
+	}
+	moduleNames[moduleName] = 1
+	return moduleName
+}
+
+// discoverCompartment discovers the resources of a single compartment of the recursive export into its own context
+func discoverCompartment(rootCtx *tf_export.ResourceDiscoveryContext, compartment *exportModule) error {
+	utils.Logf("[INFO] ===> Discovering compartment '%s' (%s)", compartment.path, compartment.id)
+
+	outputDir := filepath.Join(*rootCtx.OutputDir, compartment.path)
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return fmt.Errorf("[ERROR] unable to create output directory %s for compartment %s: %s", outputDir, compartment.id, err.Error())
+	}
+
+	// references and variables are scoped to the module of the compartment
+	tf_export.ReferenceMap = map[string]string{}
+	tf_export.Vars = map[string]string{}
+
+	args := *rootCtx.ExportCommandArgs
+	args.CompartmentId = &compartment.id
+	args.OutputDir = &outputDir
+
+	ctx, err := createResourceDiscoveryContext(rootCtx.Clients, &args, rootCtx.TenancyOcid)
+	if err != nil {
+		return err
+	}
+
+	steps, err := discoverResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	compartment.ctx = ctx
+	compartment.steps = steps
+	compartment.referenceMap = tf_export.ReferenceMap
+	compartment.vars = tf_export.Vars
+	compartment.moduleInputs = map[string]string{}
+	compartment.moduleOutputs = map[string]string{}
+	return nil
+}
+
+// writeModuleConfiguration writes the configuration and variables of a module to its directory
+func writeModuleConfiguration(module *exportModule, exportedResources map[string]*exportedResource) error {
+	defer module.ctx.PrintSummary()
+	exportStart := time.Now()
+
+	tf_export.ReferenceMap = module.referenceMap
+	tf_export.Vars = module.vars
+	tf_export.SensitiveVars = map[string]string{}
+	tf_export.IsMissingRequiredAttributes = false
+
+	addCrossModuleReferences(module, exportedResources)
+
+	var externalReferences []*externalReference
+	if module.ctx.ResolveExternalReferences {
+		exportedIds := make(map[string]bool, len(exportedResources))
+		for id := range exportedResources {
+			exportedIds[id] = true
+		}
+		externalReferences = resolveExternalReferences(module.ctx, module.steps, exportedIds)
+	}
+
+	if err := generateConfiguration(module.ctx, module.steps); err != nil {
+		return err
+	}
+
+	if err := generateExternalReferencesFile(module.ctx, externalReferences); err != nil {
+		return err
+	}
+
+	if err := generateVarsFile(tf_export.Vars, tf_export.SensitiveVars, module.ctx.OutputDir); err != nil {
+		return err
+	}
+	module.sensitiveVars = tf_export.SensitiveVars
+	for variableName := range module.sensitiveVars {
+		module.moduleInputs[variableName] = tf_export.TfHclVersionvar.GetVarHclString(variableName)
+	}
+
+	if module.ctx.GenerateGraph {
+		if err := generateDependencyGraphFiles(module.ctx); err != nil {
+			return err
+		}
+	}
+
+	addMissingRequiredAttributesSummary(module.ctx)
+	addSchemaViolationsSummary(module.ctx)
+	module.ctx.TimeTakenForEntireExport = time.Since(exportStart) + module.ctx.TimeTakenToDiscover
+	module.ctx.PostValidate()
+	return nil
+}
+
+/*
+addCrossModuleReferences replaces the OCIDs of resources exported in other modules with variables
+The variable defaults to the OCID, so the module can still be used on its own, and is set from the output of the other module in the root module
+*/
+func addCrossModuleReferences(module *exportModule, exportedResources map[string]*exportedResource) {
+	referencedIds := map[string]bool{}
+	for _, step := range module.steps {
+		for _, resource := range step.getDiscoveredResources() {
+			findReferencedIds(resource.SourceAttributes, exportedResources, referencedIds)
+		}
+	}
+
+	for id := range referencedIds {
+		referenced := exportedResources[id]
+		if referenced.module == module {
+			continue
+		}
+		if _, exists := module.referenceMap[id]; exists {
+			continue
+		}
+
+		outputName := fmt.Sprintf("%s_id", referenced.resource.TerraformName)
+		variableName := fmt.Sprintf("%s_%s", referenced.module.moduleName, outputName)
+
+		module.referenceMap[id] = tf_export.TfHclVersionvar.GetVarHclString(variableName)
+		module.vars[variableName] = fmt.Sprintf("\"%s\"", id)
+		module.moduleInputs[variableName] = tf_export.TfHclVersionvar.GetDoubleExpHclString(fmt.Sprintf("module.%s", referenced.module.moduleName), outputName)
+		referenced.module.moduleOutputs[outputName] = referenced.resource.GetHclReferenceIdString()
+
+		utils.Debugf("[DEBUG] resource '%s' in module '%s' is referred from module '%s'", referenced.resource.GetTerraformReference(), referenced.module.path, module.path)
+	}
+}
+
+// findReferencedIds collects the string values in the resource attributes that are OCIDs of exported resources
+func findReferencedIds(value interface{}, exportedResources map[string]*exportedResource, referencedIds map[string]bool) {
+	switch v := value.(type) {
+	case string:
+		if _, exists := exportedResources[v]; exists {
+			referencedIds[v] = true
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			findReferencedIds(item, exportedResources, referencedIds)
+		}
+	case []interface{}:
+		for _, item := range v {
+			findReferencedIds(item, exportedResources, referencedIds)
+		}
+	case []map[string]interface{}:
+		for _, item := range v {
+			findReferencedIds(item, exportedResources, referencedIds)
+		}
+	}
+}
+
+// generateOutputsFile writes the outputs of a module that are referred by the other modules
+func generateOutputsFile(module *exportModule) error {
+	if len(module.moduleOutputs) == 0 {
+		return nil
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString("## This configuration was generated by terraform-provider-oci\n\n")
+	for _, outputName := range getSortedStringKeys(module.moduleOutputs) {
+		builder.WriteString(fmt.Sprintf("output %s {\nvalue = %s\n}\n\n", outputName, module.moduleOutputs[outputName]))
+	}
+
+	outputsFile := filepath.Join(*module.ctx.OutputDir, tf_export.GetConfigFileName(globalvar.OutputsFile))
+	return writeFormattedConfiguration(outputsFile, builder.String())
+}
+
+// generateRootModule writes the provider, variables and the module calls for each module to the output_path, rootVars are the variables of the root module passed to the modules
+func generateRootModule(rootCtx *tf_export.ResourceDiscoveryContext, modules []*exportModule, rootVars map[string]string) error {
+	builder := &strings.Builder{}
+	builder.WriteString("## This configuration was generated by terraform-provider-oci\n\n")
+	for _, module := range modules {
+		builder.WriteString(fmt.Sprintf("module %s {\nsource = %q\n", module.moduleName, "./"+filepath.ToSlash(module.path)))
+		for _, variableName := range getSortedStringKeys(module.moduleInputs) {
+			builder.WriteString(fmt.Sprintf("%s = %s\n", variableName, module.moduleInputs[variableName]))
+		}
+		builder.WriteString("}\n\n")
+	}
+
+	modulesFile := filepath.Join(*rootCtx.OutputDir, tf_export.GetConfigFileName(globalvar.ModulesFile))
+	if err := writeFormattedConfiguration(modulesFile, builder.String()); err != nil {
+		return err
+	}
+
+	region, err := getExportRegion()
+	if err != nil {
+		return err
+	}
+
+	if err := generateProviderFile(rootCtx.OutputDir); err != nil {
+		return err
+	}
+
+	// the sensitive variables of the modules are set from the root module, so that there is a single secrets file
+	sensitiveVars := map[string]string{}
+	for _, module := range modules {
+		for variableName, value := range module.sensitiveVars {
+			sensitiveVars[variableName] = value
+		}
+	}
+	vars := map[string]string{"region": fmt.Sprintf("\"%s\"", region)}
+	for variableName, value := range rootVars {
+		vars[variableName] = value
+	}
+	if err := generateVarsFile(vars, sensitiveVars, rootCtx.OutputDir); err != nil {
+		return err
+	}
+	return generateSecretsFile(rootCtx, sensitiveVars)
+}
+
+func writeFormattedConfiguration(outputFile string, config string) error {
+	formattedConfig, err := tf_export.FormatConfiguration([]byte(config))
+	if err != nil {
+		return err
+	}
+
+	tmpFile := fmt.Sprintf("%s.tmp", outputFile)
+	if err := ioutil.WriteFile(tmpFile, formattedConfig, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, outputFile)
+}
+
+func getSortedStringKeys(source map[string]string) []string {
+	keys := make([]string, 0, len(source))
+	for key := range source {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	oci_identity "github.com/oracle/oci-go-sdk/v65/identity"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oracle/terraform-provider-oci/internal/acctest"
+	tf_client "github.com/oracle/terraform-provider-oci/internal/client"
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitGetCompartmentSubtree(t *testing.T) {
+	getCompartment, listCompartments := identityClientGetCompartmentVar, identityClientListCompartmentsVar
+	defer func() {
+		identityClientGetCompartmentVar, identityClientListCompartmentsVar = getCompartment, listCompartments
+	}()
+
+	compartment := func(id string, name string) oci_identity.Compartment {
+		return oci_identity.Compartment{Id: &id, Name: &name}
+	}
+	children := map[string][]oci_identity.Compartment{
+		"ocid1.compartment.prod":    {compartment("ocid1.compartment.network", "network"), compartment("ocid1.compartment.app", "app")},
+		"ocid1.compartment.app":     {compartment("ocid1.compartment.web", "web-tier")},
+		"ocid1.compartment.network": {},
+	}
+
+	identityClientGetCompartmentVar = func(clients *tf_client.OracleClients, req oci_identity.GetCompartmentRequest) (oci_identity.GetCompartmentResponse, error) {
+		name := "prod"
+		return oci_identity.GetCompartmentResponse{Compartment: oci_identity.Compartment{Id: req.CompartmentId, Name: &name}}, nil
+	}
+	identityClientListCompartmentsVar = func(clients *tf_client.OracleClients, req oci_identity.ListCompartmentsRequest) (oci_identity.ListCompartmentsResponse, error) {
+		assert.Equal(t, oci_identity.CompartmentLifecycleStateActive, req.LifecycleState)
+		return oci_identity.ListCompartmentsResponse{Items: children[*req.CompartmentId]}, nil
+	}
+
+	compartments, err := getCompartmentSubtree(nil, "ocid1.compartment.prod")
+	assert.NoError(t, err)
+
+	var ids, paths, moduleNames []string
+	for _, compartment := range compartments {
+		ids = append(ids, compartment.id)
+		paths = append(paths, filepath.ToSlash(compartment.path))
+		moduleNames = append(moduleNames, compartment.moduleName)
+	}
+	assert.Equal(t, []string{"ocid1.compartment.prod", "ocid1.compartment.app", "ocid1.compartment.network", "ocid1.compartment.web"}, ids)
+	assert.Equal(t, []string{"prod", "prod/app", "prod/network", "prod/app/web-tier"}, paths)
+	assert.Equal(t, []string{"prod", "prod_app", "prod_network", "prod_app_web-tier"}, moduleNames)
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitGetUniqueModuleName(t *testing.T) {
+	moduleNames := map[string]int{}
+	assert.Equal(t, "prod_app", getUniqueModuleName("prod/app", moduleNames))
+	assert.Equal(t, "prod_app_1", getUniqueModuleName("prod_app", moduleNames))
+	assert.Equal(t, "compartment_1-dev_app", getUniqueModuleName("1-dev/app", moduleNames))
+	assert.Equal(t, "dev_v1_0", getUniqueModuleName("dev v1.0", moduleNames))
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitRecursiveExportCrossCompartmentReferences(t *testing.T) {
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+	exportConfigProvider = acctest.MockConfigurationProvider{}
+
+	outputDir, err := ioutil.TempDir("", "recursive-export")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	newCompartment := func(path string, moduleName string, resources ...*tf_export.OCIResource) *exportModule {
+		compartmentDir := filepath.Join(outputDir, path)
+		if err := os.MkdirAll(compartmentDir, os.ModePerm); err != nil {
+			t.Fatalf("unable to create compartment dir: %v", err)
+		}
+		ctx := &tf_export.ResourceDiscoveryContext{ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &compartmentDir}}
+		return &exportModule{
+			path:          path,
+			moduleName:    moduleName,
+			ctx:           ctx,
+			steps:         []resourceDiscoveryStep{&resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "core", discoveredResources: resources}}},
+			referenceMap:  map[string]string{},
+			vars:          map[string]string{},
+			moduleInputs:  map[string]string{},
+			moduleOutputs: map[string]string{},
+		}
+	}
+
+	subnet := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.subnet.1", TerraformClass: "oci_core_subnet", TerraformName: "export_subnet1"},
+		SourceAttributes:  map[string]interface{}{"cidr_block": "10.0.0.0/24"},
+	}
+	instance := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.instance.1", TerraformClass: "oci_core_instance", TerraformName: "export_instance1"},
+		SourceAttributes: map[string]interface{}{
+			"create_vnic_details": []interface{}{
+				map[string]interface{}{"subnet_id": "ocid1.subnet.1"},
+			},
+		},
+	}
+	network := newCompartment("prod/network", "prod_network", subnet)
+	app := newCompartment("prod/app", "prod_app", instance)
+	app.referenceMap["ocid1.instance.1"] = instance.GetHclReferenceIdString()
+	network.referenceMap["ocid1.subnet.1"] = subnet.GetHclReferenceIdString()
+
+	exportedResources := map[string]*exportedResource{
+		"ocid1.subnet.1":   {module: network, resource: subnet},
+		"ocid1.instance.1": {module: app, resource: instance},
+	}
+	addCrossModuleReferences(app, exportedResources)
+	addCrossModuleReferences(network, exportedResources)
+
+	assert.Equal(t, "var.prod_network_export_subnet1_id", app.referenceMap["ocid1.subnet.1"])
+	assert.Equal(t, "\"ocid1.subnet.1\"", app.vars["prod_network_export_subnet1_id"])
+	assert.Equal(t, map[string]string{"prod_network_export_subnet1_id": "module.prod_network.export_subnet1_id"}, app.moduleInputs)
+	assert.Equal(t, map[string]string{"export_subnet1_id": "oci_core_subnet.export_subnet1.id"}, network.moduleOutputs)
+	assert.Empty(t, network.moduleInputs)
+	assert.Empty(t, app.moduleOutputs)
+
+	compartments := []*exportModule{network, app}
+	for _, compartment := range compartments {
+		assert.NoError(t, generateOutputsFile(compartment))
+	}
+	rootCtx := &tf_export.ResourceDiscoveryContext{ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir}}
+	assert.NoError(t, generateRootModule(rootCtx, compartments, nil))
+
+	outputs, err := ioutil.ReadFile(filepath.Join(outputDir, "prod", "network", globalvar.OutputsFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(outputs), "output export_subnet1_id {\n  value = oci_core_subnet.export_subnet1.id\n}")
+	_, err = os.Stat(filepath.Join(outputDir, "prod", "app", globalvar.OutputsFile))
+	assert.True(t, os.IsNotExist(err), "outputs file should only be generated for referred compartments")
+
+	modules, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.ModulesFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(modules), "module prod_network {\n  source = \"./prod/network\"\n}")
+	assert.Contains(t, string(modules), "module prod_app {\n  source                         = \"./prod/app\"\n  prod_network_export_subnet1_id = module.prod_network.export_subnet1_id\n}")
+
+	for _, file := range []string{globalvar.ProviderFile, globalvar.VarsFile} {
+		_, err = os.Stat(filepath.Join(outputDir, file))
+		assert.NoError(t, err)
+	}
+}
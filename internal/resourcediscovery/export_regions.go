@@ -0,0 +1,276 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	tf_client "github.com/oracle/terraform-provider-oci/internal/client"
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+// exportRegion holds the discovery results of a single region in a multi-region export
+type exportRegion struct {
+	region string
+	index  int // position of the region in the regions argument
+	ctx    *tf_export.ResourceDiscoveryContext
+	steps  []resourceDiscoveryStep
+	// global resources discovered in this region which are exported from an earlier region
+	deduplicatedResourceCount int
+}
+
+// regionResource is a resource discovered in one of the regions of a multi-region export
+type regionResource struct {
+	region   *exportRegion
+	resource *tf_export.OCIResource
+}
+
+// linkCrossRegionResourceFns update the attributes of resources which refer to a resource exported in another region
+// The references themselves are generated from the referenceMap, which is shared by all the regions
+var linkCrossRegionResourceFns = map[string]func(*regionResource, map[string]*regionResource){
+	"oci_core_remote_peering_connection": linkRemotePeeringConnection,
+	"oci_core_volume_backup":             linkVolumeBackupCopy,
+}
+
+/*
+runMultiRegionExportCommand exports the compartment from each of the regions in the regions argument to the same configuration
+- builds the clients for each region and discovers the resources of every region before writing any configuration
+- the configuration of each region is written to files suffixed with the region e.g. core_us_ashburn_1.tf
+- every resource uses the provider configuration aliased with its region e.g. `provider = oci.us_ashburn_1`
+*/
+func runMultiRegionExportCommand(d *schema.ResourceData, rootCtx *tf_export.ResourceDiscoveryContext) (error, Status) {
+	exportStart := time.Now()
+	defer elapsed("entire multi-region export command", nil, 0)()
+
+	var regions []*exportRegion
+	regionSet := map[string]bool{}
+	for _, region := range rootCtx.Regions {
+		region = strings.TrimSpace(region)
+		if region == "" || regionSet[region] {
+			continue
+		}
+		regionSet[region] = true
+
+		exportRegion := &exportRegion{region: region, index: len(regions)}
+		if err := discoverRegion(d, rootCtx, exportRegion); err != nil {
+			utils.Logln(err.Error())
+			return err, StatusFail
+		}
+		regions = append(regions, exportRegion)
+	}
+	if len(regions) == 0 {
+		return fmt.Errorf("[ERROR] no valid region specified in regions"), StatusFail
+	}
+	utils.Logf("[INFO] discovered resources in %d regions", len(regions))
+
+	exportedResources := deduplicateRegionResources(regions)
+
+	for _, resource := range exportedResources {
+		if linkFn, exists := linkCrossRegionResourceFns[resource.resource.TerraformClass]; exists {
+			linkFn(resource, exportedResources)
+		}
+	}
+
+	var errs *multierror.Error
+	status := StatusSuccess
+	var discoveredResources []*tf_export.OCIResource
+	for _, region := range regions {
+		if err := writeRegionConfiguration(region); err != nil {
+			utils.Logln(err.Error())
+			return err, StatusFail
+		}
+		discoveredResources = append(discoveredResources, region.ctx.DiscoveredResources...)
+
+		if len(region.ctx.ErrorList.Errors) > 0 {
+			err, _ := getListOfNotDiscoveredResources(region.ctx)
+			errs = multierror.Append(errs, fmt.Errorf("region '%s': %s", region.region, err.Error()))
+			status = StatusPartialSuccess
+		}
+	}
+
+	if err := generateRegionProvidersFile(rootCtx.OutputDir, regions); err != nil {
+		utils.Logln(err.Error())
+		return err, StatusFail
+	}
+
+	tf_export.Vars["region"] = fmt.Sprintf("\"%s\"", regions[0].region)
+	if err := generateVarsFile(tf_export.Vars, tf_export.SensitiveVars, rootCtx.OutputDir); err != nil {
+		utils.Logln(err.Error())
+		return err, StatusFail
+	}
+	if err := generateSecretsFile(rootCtx, tf_export.SensitiveVars); err != nil {
+		utils.Logln(err.Error())
+		return err, StatusFail
+	}
+
+	// the imports and the dependency graph include the resources of all the regions
+	combinedCtx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs:   rootCtx.ExportCommandArgs,
+		DiscoveredResources: discoveredResources,
+	}
+	if rootCtx.GenerateImportBlocks {
+		if err := generateImportsFile(combinedCtx); err != nil {
+			utils.Logln(err.Error())
+			return err, StatusFail
+		}
+	}
+	if rootCtx.GenerateGraph {
+		if err := generateDependencyGraphFiles(combinedCtx); err != nil {
+			utils.Logln(err.Error())
+			return err, StatusFail
+		}
+	}
+	addRateLimiterSummary(combinedCtx)
+	for _, statement := range combinedCtx.SummaryStatements {
+		utils.Logln(utils.Green(statement))
+	}
+
+	utils.Logln(utils.Green(fmt.Sprintf("Exported %d regions. Generated under '%s'", len(regions), *rootCtx.OutputDir)))
+	utils.Logln(utils.Green(fmt.Sprintf("Total time taken by entire multi-region export: %v", time.Since(exportStart))))
+	return errs.ErrorOrNil(), status
+}
+
+/*
+deduplicateRegionResources returns the resources exported in each region by their id
+- the global resources e.g. identity users, groups, policies and compartments are discovered again in every region
+- they are only exported from the first region in the regions argument, so that a single resource manages each of them
+- the references to a global resource point to the resource exported from the first region
+- the copies discovered in the other regions are dropped from their steps and only counted, they are not reported as omitted
+*/
+func deduplicateRegionResources(regions []*exportRegion) map[string]*regionResource {
+	exportedResources := map[string]*regionResource{}
+	for _, region := range regions {
+		for _, step := range region.steps {
+			baseStep := step.getBaseStep()
+			discoveredResources := make([]*tf_export.OCIResource, 0, len(baseStep.discoveredResources))
+			for _, resource := range baseStep.discoveredResources {
+				if exported, exists := exportedResources[resource.Id]; exists {
+					utils.Debugf("[DEBUG] skipping '%s' in region '%s', it is exported as '%s' from region '%s'", resource.GetTerraformReference(), region.region, exported.resource.GetTerraformReference(), exported.region.region)
+					tf_export.ReferenceMap[resource.Id] = exported.resource.GetHclReferenceIdString()
+					region.deduplicatedResourceCount++
+					continue
+				}
+				resource.Provider = fmt.Sprintf("oci.%s", tf_export.GetRegionProviderAlias(region.region))
+				exportedResources[resource.Id] = &regionResource{region: region, resource: resource}
+				discoveredResources = append(discoveredResources, resource)
+			}
+			baseStep.discoveredResources = discoveredResources
+		}
+	}
+	return exportedResources
+}
+
+// discoverRegion builds the clients for the region and discovers its resources into its own context
+func discoverRegion(d *schema.ResourceData, rootCtx *tf_export.ResourceDiscoveryContext, region *exportRegion) error {
+	utils.Logf("[INFO] ===> Discovering region '%s'", region.region)
+
+	if err := d.Set(globalvar.RegionAttrName, region.region); err != nil {
+		return err
+	}
+	clients, err := getExportConfigVar(d)
+	if err != nil {
+		return fmt.Errorf("[ERROR] unable to create clients for region %s: %s", region.region, err.Error())
+	}
+
+	args := *rootCtx.ExportCommandArgs
+	ctx, err := createResourceDiscoveryContext(clients.(*tf_client.OracleClients), &args, rootCtx.TenancyOcid)
+	if err != nil {
+		return err
+	}
+	ctx.Region = region.region
+
+	steps, err := discoverResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	region.ctx = ctx
+	region.steps = steps
+	return nil
+}
+
+// writeRegionConfiguration writes the configuration of a region, the references and variables are shared by all the regions
+func writeRegionConfiguration(region *exportRegion) error {
+	defer region.ctx.PrintSummary()
+	exportStart := time.Now()
+	tf_export.IsMissingRequiredAttributes = false
+
+	if err := generateConfiguration(region.ctx, region.steps); err != nil {
+		return err
+	}
+
+	if region.deduplicatedResourceCount > 0 {
+		region.ctx.SummaryStatements = append(region.ctx.SummaryStatements, fmt.Sprintf("Skipped %d global resources of region '%s' which are exported from an earlier region", region.deduplicatedResourceCount, region.region))
+	}
+	addMissingRequiredAttributesSummary(region.ctx)
+	addSchemaViolationsSummary(region.ctx)
+	region.ctx.TimeTakenForEntireExport = time.Since(exportStart) + region.ctx.TimeTakenToDiscover
+	region.ctx.PostValidate()
+	return nil
+}
+
+// generateRegionProvidersFile writes the default provider and a provider configuration aliased with each of the regions
+func generateRegionProvidersFile(outputDir *string, regions []*exportRegion) error {
+	builder := &strings.Builder{}
+	builder.WriteString(fmt.Sprintf("provider oci {\nregion = %s\n}\n\n", tf_export.TfHclVersionvar.GetVarHclString("region")))
+	for _, region := range regions {
+		builder.WriteString(fmt.Sprintf("provider oci {\nalias = %q\nregion = %q\n}\n\n", tf_export.GetRegionProviderAlias(region.region), region.region))
+	}
+
+	providerFile := filepath.Join(*outputDir, tf_export.GetConfigFileName(globalvar.ProviderFile))
+	return writeFormattedConfiguration(providerFile, builder.String())
+}
+
+/*
+linkRemotePeeringConnection links the remote peering connections that are peered across the exported regions
+Only the connection from the later region in the regions argument keeps the peer, as the peering is established from one side
+Keeping the peer on both sides would result in a dependency cycle
+*/
+func linkRemotePeeringConnection(rpc *regionResource, exportedResources map[string]*regionResource) {
+	peerId, ok := rpc.resource.SourceAttributes["peer_id"].(string)
+	if !ok {
+		return
+	}
+	peer, exists := exportedResources[peerId]
+	if !exists || peer.region == rpc.region {
+		return
+	}
+
+	if rpc.region.index < peer.region.index {
+		delete(rpc.resource.SourceAttributes, "peer_id")
+		delete(rpc.resource.SourceAttributes, "peer_region_name")
+		return
+	}
+	utils.Debugf("[DEBUG] linked remote peering connection '%s' to '%s'", rpc.resource.GetTerraformReference(), peer.resource.GetTerraformReference())
+}
+
+// linkVolumeBackupCopy sets the source of a volume backup copied from a volume backup exported in another region
+func linkVolumeBackupCopy(backup *regionResource, exportedResources map[string]*regionResource) {
+	sourceId, ok := backup.resource.SourceAttributes["source_volume_backup_id"].(string)
+	if !ok {
+		return
+	}
+	source, exists := exportedResources[sourceId]
+	if !exists || source.region == backup.region {
+		return
+	}
+
+	backup.resource.SourceAttributes["source_details"] = []interface{}{
+		map[string]interface{}{
+			"region":           source.region.region,
+			"volume_backup_id": sourceId,
+		},
+	}
+	// the source volume is in the other region, the copy is created from the source backup instead
+	delete(backup.resource.SourceAttributes, "volume_id")
+	utils.Debugf("[DEBUG] linked volume backup copy '%s' to '%s'", backup.resource.GetTerraformReference(), source.resource.GetTerraformReference())
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitGenerateRegionProvidersFile(t *testing.T) {
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+
+	outputDir, err := ioutil.TempDir("", "regions-export")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	regions := []*exportRegion{{region: "us-ashburn-1", index: 0}, {region: "us-phoenix-1", index: 1}}
+	assert.NoError(t, generateRegionProvidersFile(&outputDir, regions))
+
+	providers, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.ProviderFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(providers), "provider oci {\n  region = var.region\n}")
+	assert.Contains(t, string(providers), "alias  = \"us_ashburn_1\"\n  region = \"us-ashburn-1\"")
+	assert.Contains(t, string(providers), "alias  = \"us_phoenix_1\"\n  region = \"us-phoenix-1\"")
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitGetConfigFileName_region(t *testing.T) {
+	ctx := &tf_export.ResourceDiscoveryContext{ExportCommandArgs: &tf_export.ExportCommandArgs{}}
+	step := &resourceDiscoveryBaseStep{ctx: ctx, name: "core"}
+	assert.Equal(t, "core.tf", step.getConfigFileName())
+
+	ctx.Region = "us-ashburn-1"
+	assert.Equal(t, "core_us_ashburn_1.tf", step.getConfigFileName())
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitValidateRegions(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "regions-export")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	args := &tf_export.ExportCommandArgs{OutputDir: &outputDir, Parallelism: 1, Regions: []string{"us-ashburn-1"}}
+	assert.NoError(t, args.Validate())
+
+	args.GenerateState = true
+	assert.Error(t, args.Validate())
+
+	args.GenerateState = false
+	args.Recursive = true
+	assert.Error(t, args.Validate())
+
+	args.Recursive = false
+	args.Resume = true
+	assert.Error(t, args.Validate())
+
+	args.Resume = false
+	args.ResolveExternalReferences = true
+	assert.Error(t, args.Validate())
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitLinkCrossRegionResources(t *testing.T) {
+	ashburn := &exportRegion{region: "us-ashburn-1", index: 0}
+	phoenix := &exportRegion{region: "us-phoenix-1", index: 1}
+
+	newResource := func(region *exportRegion, id string, class string, attributes map[string]interface{}) *regionResource {
+		return &regionResource{
+			region: region,
+			resource: &tf_export.OCIResource{
+				TerraformResource: tf_export.TerraformResource{Id: id, TerraformClass: class, TerraformName: id},
+				SourceAttributes:  attributes,
+			},
+		}
+	}
+
+	ashburnRpc := newResource(ashburn, "rpc1", "oci_core_remote_peering_connection", map[string]interface{}{"peer_id": "rpc2", "peer_region_name": "us-phoenix-1"})
+	phoenixRpc := newResource(phoenix, "rpc2", "oci_core_remote_peering_connection", map[string]interface{}{"peer_id": "rpc1", "peer_region_name": "us-ashburn-1"})
+	unknownPeerRpc := newResource(phoenix, "rpc3", "oci_core_remote_peering_connection", map[string]interface{}{"peer_id": "rpc4", "peer_region_name": "eu-frankfurt-1"})
+	sourceBackup := newResource(ashburn, "backup1", "oci_core_volume_backup", map[string]interface{}{"volume_id": "volume1"})
+	copiedBackup := newResource(phoenix, "backup2", "oci_core_volume_backup", map[string]interface{}{"volume_id": "volume1", "source_volume_backup_id": "backup1"})
+
+	exportedResources := map[string]*regionResource{}
+	for _, resource := range []*regionResource{ashburnRpc, phoenixRpc, unknownPeerRpc, sourceBackup, copiedBackup} {
+		exportedResources[resource.resource.Id] = resource
+	}
+	for _, resource := range exportedResources {
+		if linkFn, exists := linkCrossRegionResourceFns[resource.resource.TerraformClass]; exists {
+			linkFn(resource, exportedResources)
+		}
+	}
+
+	// the peering is kept only on the connection of the later region to avoid a dependency cycle
+	assert.NotContains(t, ashburnRpc.resource.SourceAttributes, "peer_id")
+	assert.NotContains(t, ashburnRpc.resource.SourceAttributes, "peer_region_name")
+	assert.Equal(t, "rpc1", phoenixRpc.resource.SourceAttributes["peer_id"])
+	assert.Equal(t, "rpc4", unknownPeerRpc.resource.SourceAttributes["peer_id"])
+
+	assert.Equal(t, map[string]interface{}{"volume_id": "volume1"}, sourceBackup.resource.SourceAttributes)
+	assert.Equal(t, []interface{}{map[string]interface{}{"region": "us-ashburn-1", "volume_backup_id": "backup1"}}, copiedBackup.resource.SourceAttributes["source_details"])
+	assert.NotContains(t, copiedBackup.resource.SourceAttributes, "volume_id")
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitGetHCLString_provider(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	defer func() { tf_export.IsMissingRequiredAttributes = false }()
+
+	resource := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{TerraformClass: "oci_test_parent", TerraformName: "parent1"},
+		SourceAttributes:  map[string]interface{}{"a_string": "string1"},
+		Provider:          "oci.us_ashburn_1",
+	}
+
+	tfHclVersions := map[tf_export.TfHclVersion]string{
+		&tf_export.TfHclVersion11{}: "resource oci_test_parent parent1 {\nprovider = \"oci.us_ashburn_1\"\n",
+		&tf_export.TfHclVersion12{}: "resource oci_test_parent parent1 {\nprovider = oci.us_ashburn_1\n",
+	}
+	for tfVersion, expected := range tfHclVersions {
+		tf_export.TfHclVersionvar = tfVersion
+		builder := &strings.Builder{}
+		assert.NoError(t, resource.GetHCLString(builder, nil))
+		assert.True(t, strings.HasPrefix(builder.String(), expected), "got %s", builder.String())
+		assert.Equal(t, 1, strings.Count(builder.String(), "provider ="))
+	}
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitDeduplicateRegionResources(t *testing.T) {
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+	defer func(referenceMap map[string]string) { tf_export.ReferenceMap = referenceMap }(tf_export.ReferenceMap)
+	tf_export.ReferenceMap = map[string]string{}
+
+	newResource := func(id string, class string, name string) *tf_export.OCIResource {
+		resource := &tf_export.OCIResource{TerraformResource: tf_export.TerraformResource{Id: id, TerraformClass: class, TerraformName: name}}
+		tf_export.ReferenceMap[id] = resource.GetHclReferenceIdString()
+		return resource
+	}
+	ashburnUser := newResource("ocid1.user.oc1..a", "oci_identity_user", "export_admin")
+	ashburnVcn := newResource("ocid1.vcn.oc1.iad.a", "oci_core_vcn", "export_vcn_iad")
+	ashburn := &exportRegion{region: "us-ashburn-1", index: 0, steps: []resourceDiscoveryStep{
+		&resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{name: "identity", discoveredResources: []*tf_export.OCIResource{ashburnUser}}},
+		&resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{name: "core", discoveredResources: []*tf_export.OCIResource{ashburnVcn}}},
+	}}
+	// the global resources are discovered again in the other regions
+	phoenixUser := newResource("ocid1.user.oc1..a", "oci_identity_user", "export_admin_1")
+	phoenixVcn := newResource("ocid1.vcn.oc1.phx.a", "oci_core_vcn", "export_vcn_phx")
+	phoenix := &exportRegion{region: "us-phoenix-1", index: 1, steps: []resourceDiscoveryStep{
+		&resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{name: "identity", discoveredResources: []*tf_export.OCIResource{phoenixUser}}},
+		&resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{name: "core", discoveredResources: []*tf_export.OCIResource{phoenixVcn}}},
+	}}
+
+	exportedResources := deduplicateRegionResources([]*exportRegion{ashburn, phoenix})
+	assert.Len(t, exportedResources, 3)
+	assert.Equal(t, ashburnUser, exportedResources["ocid1.user.oc1..a"].resource)
+	assert.Equal(t, "oci.us_ashburn_1", ashburnUser.Provider)
+	assert.Equal(t, "oci.us_phoenix_1", phoenixVcn.Provider)
+
+	assert.Empty(t, phoenix.steps[0].getDiscoveredResources())
+	assert.Empty(t, phoenix.steps[0].getOmittedResources())
+	assert.Equal(t, 0, ashburn.deduplicatedResourceCount)
+	assert.Equal(t, 1, phoenix.deduplicatedResourceCount)
+	assert.Equal(t, []*tf_export.OCIResource{phoenixVcn}, phoenix.steps[1].getDiscoveredResources())
+	assert.Equal(t, "oci_identity_user.export_admin.id", tf_export.ReferenceMap["ocid1.user.oc1..a"])
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+// Status of the resources in the export report
+const (
+	reportResourceDiscovered = "discovered"
+	reportResourceOmitted    = "omitted"
+	reportResourceFailed     = "failed"
+
+	// error types of the errors which are not a ResourceDiscoveryCustomError
+	reportDiscoveryError tf_export.ErrorTypeEnum = "DiscoveryError"
+	reportImportError    tf_export.ErrorTypeEnum = "ImportError"
+)
+
+var reportStatusNames = map[Status]string{
+	StatusSuccess:        "SUCCESS",
+	StatusFail:           "FAIL",
+	StatusPartialSuccess: "PARTIAL_SUCCESS",
+}
+
+/*
+exportReportVar collects the steps of every discovery run by the export command, it is only set when `report_path` is specified
+A single export has one discovery while recursive and multi-region exports have one discovery per compartment and region
+*/
+var exportReportVar *exportReport
+
+type exportReport struct {
+	lock        sync.Mutex
+	start       time.Time
+	discoveries []*reportDiscovery
+}
+
+type reportDiscovery struct {
+	ctx   *tf_export.ResourceDiscoveryContext
+	steps []resourceDiscoveryStep
+}
+
+// reportSummary is the JSON representation of the export command results written to `report_path`
+type reportSummary struct {
+	Status                     string           `json:"status"`
+	Error                      string           `json:"error,omitempty"`
+	TimeTakenToDiscoverMs      int64            `json:"time_taken_to_discover_ms"`
+	TimeTakenToGenerateStateMs int64            `json:"time_taken_to_generate_state_ms"`
+	TimeTakenForEntireExportMs int64            `json:"time_taken_for_entire_export_ms"`
+	DiscoveredResourceCount    int              `json:"discovered_resource_count"`
+	OmittedResourceCount       int              `json:"omitted_resource_count"`
+	FailedResourceCount        int              `json:"failed_resource_count"`
+	MissingRequiredAttributes  bool             `json:"missing_required_attributes"`
+	Steps                      []reportStep     `json:"steps"`
+	Resources                  []reportResource `json:"resources"`
+	Errors                     []reportError    `json:"errors"`
+}
+
+type reportStep struct {
+	Name                          string `json:"name"`
+	CompartmentId                 string `json:"compartment_id,omitempty"`
+	Region                        string `json:"region,omitempty"`
+	DiscoveredResourceCount       int    `json:"discovered_resource_count"`
+	OmittedResourceCount          int    `json:"omitted_resource_count"`
+	FailedResourceCount           int    `json:"failed_resource_count"`
+	TimeTakenForDiscoveryMs       int64  `json:"time_taken_for_discovery_ms"`
+	TimeTakenForGeneratingStateMs int64  `json:"time_taken_for_generating_state_ms"`
+}
+
+type reportResource struct {
+	Id                            string   `json:"id"`
+	ImportId                      string   `json:"import_id,omitempty"`
+	Address                       string   `json:"address"`
+	ResourceType                  string   `json:"resource_type"`
+	Service                       string   `json:"service"`
+	Step                          string   `json:"step"`
+	CompartmentId                 string   `json:"compartment_id,omitempty"`
+	Region                        string   `json:"region,omitempty"`
+	Status                        string   `json:"status"`
+	ErrorType                     string   `json:"error_type,omitempty"`
+	Error                         string   `json:"error,omitempty"`
+	MissingRequiredAttributes     []string `json:"missing_required_attributes,omitempty"`
+	TimeTakenForDiscoveryMs       int64    `json:"time_taken_for_discovery_ms"`
+	TimeTakenForGeneratingStateMs int64    `json:"time_taken_for_generating_state_ms"`
+}
+
+type reportError struct {
+	ResourceType                string   `json:"resource_type,omitempty"`
+	ParentResource              string   `json:"parent_resource,omitempty"`
+	CompartmentId               string   `json:"compartment_id,omitempty"`
+	Region                      string   `json:"region,omitempty"`
+	ErrorType                   string   `json:"error_type"`
+	Error                       string   `json:"error"`
+	NotDiscoveredChildResources []string `json:"not_discovered_child_resources,omitempty"`
+}
+
+func newExportReport() *exportReport {
+	return &exportReport{start: time.Now()}
+}
+
+// addDiscovery adds the steps of a discovery to the report, it does nothing if no report was requested
+func (r *exportReport) addDiscovery(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.discoveries = append(r.discoveries, &reportDiscovery{ctx: ctx, steps: steps})
+}
+
+// build creates the report from the discoveries once the export command has completed
+func (r *exportReport) build(status Status, exportErr error) *reportSummary {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	report := &reportSummary{
+		Status:                     reportStatusNames[status],
+		TimeTakenForEntireExportMs: time.Since(r.start).Milliseconds(),
+		Steps:                      []reportStep{},
+		Resources:                  []reportResource{},
+		Errors:                     []reportError{},
+	}
+	if exportErr != nil {
+		report.Error = exportErr.Error()
+	}
+
+	services := getServicesByResourceType()
+	for _, discovery := range r.discoveries {
+		ctx := discovery.ctx
+		compartmentId := ""
+		if ctx.CompartmentId != nil {
+			compartmentId = *ctx.CompartmentId
+		}
+		report.TimeTakenToDiscoverMs += ctx.TimeTakenToDiscover.Milliseconds()
+		report.TimeTakenToGenerateStateMs += ctx.TimeTakenToGenerateState.Milliseconds()
+
+		for _, step := range discovery.steps {
+			baseStep := step.getBaseStep()
+			stepReport := reportStep{
+				Name:                          baseStep.name,
+				CompartmentId:                 compartmentId,
+				Region:                        ctx.Region,
+				TimeTakenForDiscoveryMs:       baseStep.timeTakenForDiscovery.Milliseconds(),
+				TimeTakenForGeneratingStateMs: baseStep.timeTakenForGeneratingState.Milliseconds(),
+			}
+
+			newResource := func(resource *tf_export.OCIResource, resourceStatus string) reportResource {
+				// the steps of a compartment or tenancy export are named after their service
+				service := baseStep.name
+				if !isExportService(service) {
+					service = services[resource.TerraformClass]
+				}
+				return reportResource{
+					Id:                            resource.Id,
+					ImportId:                      resource.ImportId,
+					Address:                       resource.GetTerraformReference(),
+					ResourceType:                  resource.TerraformClass,
+					Service:                       service,
+					Step:                          baseStep.name,
+					CompartmentId:                 resource.CompartmentId,
+					Region:                        ctx.Region,
+					Status:                        resourceStatus,
+					MissingRequiredAttributes:     ctx.MissingAttributesPerResource[resource.GetTerraformReference()],
+					TimeTakenForDiscoveryMs:       stepReport.TimeTakenForDiscoveryMs,
+					TimeTakenForGeneratingStateMs: stepReport.TimeTakenForGeneratingStateMs,
+				}
+			}
+
+			for _, resource := range step.getDiscoveredResources() {
+				if resource.IsErrorResource {
+					failedResource := newResource(resource, reportResourceFailed)
+					failedResource.ErrorType = string(reportImportError)
+					failedResource.Error = getResourceError(ctx, resource)
+					report.Resources = append(report.Resources, failedResource)
+					stepReport.FailedResourceCount++
+					continue
+				}
+				// the resources grouped using for_each are reported as the instances of the resource
+				for _, instance := range resource.ForEachResources {
+					instanceReport := newResource(instance, reportResourceDiscovered)
+					instanceReport.Address = getForEachInstanceReference(resource, instance)
+					report.Resources = append(report.Resources, instanceReport)
+					stepReport.DiscoveredResourceCount++
+				}
+				if len(resource.ForEachResources) > 0 {
+					continue
+				}
+				report.Resources = append(report.Resources, newResource(resource, reportResourceDiscovered))
+				stepReport.DiscoveredResourceCount++
+				if len(ctx.MissingAttributesPerResource[resource.GetTerraformReference()]) > 0 {
+					report.MissingRequiredAttributes = true
+				}
+			}
+			for _, resource := range step.getOmittedResources() {
+				report.Resources = append(report.Resources, newResource(resource, reportResourceOmitted))
+				stepReport.OmittedResourceCount++
+			}
+
+			report.DiscoveredResourceCount += stepReport.DiscoveredResourceCount
+			report.OmittedResourceCount += stepReport.OmittedResourceCount
+			report.FailedResourceCount += stepReport.FailedResourceCount
+			report.Steps = append(report.Steps, stepReport)
+		}
+
+		for _, resourceDiscoveryError := range ctx.ErrorList.Errors {
+			errorReport := reportError{
+				ResourceType:   resourceDiscoveryError.ResourceType,
+				ParentResource: resourceDiscoveryError.ParentResource,
+				CompartmentId:  compartmentId,
+				Region:         ctx.Region,
+				ErrorType:      string(getErrorType(resourceDiscoveryError)),
+			}
+			if resourceDiscoveryError.Error != nil {
+				errorReport.Error = resourceDiscoveryError.Error.Error()
+			}
+			if !ctx.TargetSpecificResources {
+				errorReport.NotDiscoveredChildResources = resourceDiscoveryError.GetNotFoundChildren()
+			}
+			report.Errors = append(report.Errors, errorReport)
+		}
+	}
+
+	return report
+}
+
+// write writes the report as JSON to the given path
+func (r *exportReport) write(reportPath string, status Status, exportErr error) error {
+	report := r.build(status, exportErr)
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("[ERROR] unable to generate export report: %s", err.Error())
+	}
+
+	if dir := filepath.Dir(reportPath); dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return fmt.Errorf("[ERROR] unable to create directory for export report %s: %s", reportPath, err.Error())
+		}
+	}
+	if err := ioutil.WriteFile(reportPath, content, 0644); err != nil {
+		return fmt.Errorf("[ERROR] unable to write export report %s: %s", reportPath, err.Error())
+	}
+	utils.Logln(utils.Green(fmt.Sprintf("Export report generated under '%s'", reportPath)))
+	return nil
+}
+
+// getServicesByResourceType maps each resource type to the service whose resource graph it belongs to
+func getServicesByResourceType() map[string]string {
+	services := map[string]string{}
+	for _, resourceGraphs := range []map[string]tf_export.TerraformResourceGraph{tf_export.TenancyResourceGraphs, tf_export.CompartmentResourceGraphs} {
+		serviceNames := make([]string, 0, len(resourceGraphs))
+		for service := range resourceGraphs {
+			serviceNames = append(serviceNames, service)
+		}
+		// sort to keep the service deterministic for the resource types present in multiple graphs
+		sort.Strings(serviceNames)
+
+		for _, service := range serviceNames {
+			for _, associations := range resourceGraphs[service] {
+				for _, association := range associations {
+					if association.TerraformResourceHints == nil {
+						continue
+					}
+					if _, exists := services[association.ResourceClass]; !exists {
+						services[association.ResourceClass] = service
+					}
+				}
+			}
+		}
+	}
+	return services
+}
+
+func isExportService(service string) bool {
+	_, isCompartmentService := tf_export.CompartmentResourceGraphs[service]
+	_, isTenancyService := tf_export.TenancyResourceGraphs[service]
+	return isCompartmentService || isTenancyService
+}
+
+// getResourceError returns the import error of the resource from the errors of the discovery
+func getResourceError(ctx *tf_export.ResourceDiscoveryContext, resource *tf_export.OCIResource) string {
+	address := fmt.Sprintf("'%s'", resource.GetTerraformReference())
+	for _, resourceDiscoveryError := range ctx.ErrorList.Errors {
+		if resourceDiscoveryError.Error != nil && strings.Contains(resourceDiscoveryError.Error.Error(), address) {
+			return resourceDiscoveryError.Error.Error()
+		}
+	}
+	return ""
+}
+
+func getErrorType(resourceDiscoveryError *tf_export.ResourceDiscoveryError) tf_export.ErrorTypeEnum {
+	if resourceDiscoveryError.Error == nil {
+		return reportDiscoveryError
+	}
+	message := resourceDiscoveryError.Error.Error()
+	switch {
+	case strings.Contains(message, tf_export.IdsNotFoundErrorMessage):
+		return tf_export.IdsNotFoundError
+	case strings.Contains(message, "terraform import command failed"):
+		return reportImportError
+	}
+	return reportDiscoveryError
+}
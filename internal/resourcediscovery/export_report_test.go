@@ -0,0 +1,120 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitExportReport(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+
+	outputDir, err := ioutil.TempDir("", "export-report")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	ctx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs:            &tf_export.ExportCommandArgs{CompartmentId: &compartmentId},
+		MissingAttributesPerResource: map[string][]string{"oci_test_parent.parent1": {"a_required_string"}},
+		TimeTakenToDiscover:          2 * time.Second,
+	}
+
+	newResource := func(class string, name string) *tf_export.OCIResource {
+		return &tf_export.OCIResource{
+			CompartmentId:     compartmentId,
+			TerraformResource: tf_export.TerraformResource{Id: "ocid1." + name, TerraformClass: class, TerraformName: name},
+		}
+	}
+	discovered := newResource("oci_test_parent", "parent1")
+	failed := newResource("oci_test_child", "child1")
+	failed.IsErrorResource = true
+	omitted := newResource("oci_test_child", "child2")
+
+	step := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{
+		ctx:                         ctx,
+		name:                        "compartment_testing",
+		discoveredResources:         []*tf_export.OCIResource{discovered, failed},
+		omittedResources:            []*tf_export.OCIResource{omitted},
+		timeTakenForDiscovery:       1500 * time.Millisecond,
+		timeTakenForGeneratingState: 500 * time.Millisecond,
+	}}
+
+	graph := tf_export.TerraformResourceGraph{"oci_test_parent": {{TerraformResourceHints: &tf_export.TerraformResourceHints{ResourceClass: "oci_test_child"}}}}
+	ctx.AddErrorToList(&tf_export.ResourceDiscoveryError{ResourceType: "oci_test_parent", ParentResource: "export", Error: fmt.Errorf("service unavailable"), ResourceGraph: &graph})
+	ctx.AddErrorToList(&tf_export.ResourceDiscoveryError{ResourceType: "oci_test_child", ParentResource: "parent1", Error: fmt.Errorf("[ERROR] terraform import command failed for resource 'oci_test_child.child1' at id 'ocid1.child1': 404")})
+
+	report := newExportReport()
+	report.addDiscovery(ctx, []resourceDiscoveryStep{step})
+
+	reportPath := filepath.Join(outputDir, "reports", "report.json")
+	assert.NoError(t, report.write(reportPath, StatusPartialSuccess, fmt.Errorf("partial")))
+
+	content, err := ioutil.ReadFile(reportPath)
+	assert.NoError(t, err)
+	written := &reportSummary{}
+	assert.NoError(t, json.Unmarshal(content, written))
+
+	assert.Equal(t, "PARTIAL_SUCCESS", written.Status)
+	assert.Equal(t, "partial", written.Error)
+	assert.Equal(t, int64(2000), written.TimeTakenToDiscoverMs)
+	assert.Equal(t, 1, written.DiscoveredResourceCount)
+	assert.Equal(t, 1, written.OmittedResourceCount)
+	assert.Equal(t, 1, written.FailedResourceCount)
+	assert.True(t, written.MissingRequiredAttributes)
+	assert.Equal(t, []reportStep{{
+		Name:                          "compartment_testing",
+		CompartmentId:                 compartmentId,
+		DiscoveredResourceCount:       1,
+		OmittedResourceCount:          1,
+		FailedResourceCount:           1,
+		TimeTakenForDiscoveryMs:       1500,
+		TimeTakenForGeneratingStateMs: 500,
+	}}, written.Steps)
+
+	if assert.Len(t, written.Resources, 3) {
+		assert.Equal(t, reportResource{
+			Id:                            "ocid1.parent1",
+			Address:                       "oci_test_parent.parent1",
+			ResourceType:                  "oci_test_parent",
+			Service:                       "compartment_testing",
+			Step:                          "compartment_testing",
+			CompartmentId:                 compartmentId,
+			Status:                        reportResourceDiscovered,
+			MissingRequiredAttributes:     []string{"a_required_string"},
+			TimeTakenForDiscoveryMs:       1500,
+			TimeTakenForGeneratingStateMs: 500,
+		}, written.Resources[0])
+		assert.Equal(t, reportResourceFailed, written.Resources[1].Status)
+		assert.Equal(t, string(reportImportError), written.Resources[1].ErrorType)
+		assert.Contains(t, written.Resources[1].Error, "404")
+		assert.Equal(t, reportResourceOmitted, written.Resources[2].Status)
+	}
+
+	if assert.Len(t, written.Errors, 2) {
+		assert.Equal(t, string(reportDiscoveryError), written.Errors[0].ErrorType)
+		assert.Equal(t, []string{"oci_test_child"}, written.Errors[0].NotDiscoveredChildResources)
+		assert.Equal(t, string(reportImportError), written.Errors[1].ErrorType)
+	}
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitExportReport_noReport(t *testing.T) {
+	var report *exportReport
+	assert.NotPanics(t, func() { report.addDiscovery(&tf_export.ResourceDiscoveryContext{}, nil) })
+}
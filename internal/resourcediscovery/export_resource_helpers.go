@@ -4,6 +4,7 @@
 package resourcediscovery
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -24,8 +25,6 @@ import (
 	"github.com/oracle/terraform-provider-oci/internal/utils"
 
 	"github.com/hashicorp/terraform-exec/tfexec"
-
-	"github.com/hashicorp/hcl2/hclwrite"
 )
 
 var isInitDone bool
@@ -91,8 +90,8 @@ func createResourceDiscoveryContext(clients *tf_client.OracleClients, args *tf_e
 			break
 		}
 	}
-	// validate terraform version and initialize terraform for import - only required if generating state file
-	if args.GenerateState {
+	// validate terraform version and initialize terraform for import - only required if generating state file using terraform import
+	if args.GenerateState && !args.NativeState {
 		if tf, terraformCLIPath, err := createTerraformStruct(args); err != nil {
 			return result, err
 		} else {
@@ -193,7 +192,11 @@ func (r *resourceDiscoveryBaseStep) writeTmpState() error {
 	tmpStateOutputDir := filepath.Join(*r.ctx.OutputDir, "tmp", r.name)
 	tmpStateOutputFilePrefix := filepath.Join(tmpStateOutputDir, globalvar.DefaultTmpStateFile)
 
-	if err := os.RemoveAll(tmpStateOutputDir); err != nil {
+	importedResources := map[string]bool{}
+	if exportCheckpointVar.isResuming() {
+		// the resources imported to the tmp state files by the previous export are not imported again
+		importedResources = getImportedResourcesInDir(tmpStateOutputDir)
+	} else if err := os.RemoveAll(tmpStateOutputDir); err != nil {
 		utils.Logf("[WARN] unable to delete existing tmp state directory %s", tmpStateOutputDir)
 		return err
 	}
@@ -226,13 +229,17 @@ func (r *resourceDiscoveryBaseStep) writeTmpState() error {
 		}
 		go func(resources []*tf_export.OCIResource, chunkIndex int) {
 			for _, res := range resources {
-				fileName := tmpStateOutputFilePrefix + fmt.Sprint(chunkIndex)
-				importResource(r.ctx, res, fileName)
 				if res.TerraformTypeInfo != nil && !res.TerraformTypeInfo.IsDataSource {
 					isAllDataSourceLock.Lock()
 					isAllDataSources = false
 					isAllDataSourceLock.Unlock()
 				}
+				if importedResources[res.GetTerraformReference()] {
+					utils.Debugf("[DEBUG] skip importing '%s' since it was imported by the previous export", res.GetTerraformReference())
+					continue
+				}
+				fileName := tmpStateOutputFilePrefix + fmt.Sprint(chunkIndex)
+				importResource(r.ctx, res, fileName)
 			}
 			<-semImport
 			importWg.Done()
@@ -257,8 +264,12 @@ func (r *resourceDiscoveryBaseStep) writeTmpState() error {
 // The configuration will be discarded and written again after import is completed for all resources
 func (r *resourceDiscoveryBaseStep) writeTmpConfigurationForImport() error {
 	defer elapsed(fmt.Sprintf("writing temp configuration for %d %s resources", len(r.getDiscoveredResources()), r.name), nil, 0)()
-	configOutputFile := fmt.Sprintf("%s%s%s.tf", *r.ctx.OutputDir, string(os.PathSeparator), r.name)
-	tmpConfigOutputFile := fmt.Sprintf("%s%s%s.tf.tmp", *r.ctx.OutputDir, string(os.PathSeparator), r.name)
+	configFileName := r.getConfigFileName()
+	if r.ctx.Incremental {
+		configFileName = r.getIncrementalImportConfigFileName()
+	}
+	configOutputFile := fmt.Sprintf("%s%s%s", *r.ctx.OutputDir, string(os.PathSeparator), configFileName)
+	tmpConfigOutputFile := fmt.Sprintf("%s.tmp", configOutputFile)
 
 	file, err := os.OpenFile(tmpConfigOutputFile, os.O_CREATE|os.O_RDWR, 0666)
 	if err != nil {
@@ -280,7 +291,14 @@ func (r *resourceDiscoveryBaseStep) writeTmpConfigurationForImport() error {
 		r.ctx.CtxLock.Unlock()
 	}
 
-	_, err = file.WriteString(string(builder.String()))
+	// The tmp config is formatted only to convert it to JSON syntax if required
+	formattedString, err := tf_export.FormatConfiguration([]byte(builder.String()))
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	_, err = file.WriteString(string(formattedString))
 	if err != nil {
 		_ = file.Close()
 		return err
@@ -299,12 +317,20 @@ func (r *resourceDiscoveryBaseStep) writeTmpConfigurationForImport() error {
 func (r *resourceDiscoveryBaseStep) writeConfiguration() error {
 	defer elapsed(fmt.Sprintf("writing actual configuration for %d %s resources", len(r.getDiscoveredResources()), r.name), nil, 0)()
 
+	if r.ctx.Incremental {
+		// remove the temporary configuration written to import the new resources
+		importConfigFile := filepath.Join(*r.ctx.OutputDir, r.getIncrementalImportConfigFileName())
+		if err := os.RemoveAll(importConfigFile); err != nil {
+			return err
+		}
+	}
+
 	//Do not generate empty terraform configuration file
 	if len(r.getDiscoveredResources()) == 0 {
 		return nil
 	}
-	configOutputFile := fmt.Sprintf("%s%s%s.tf", *r.ctx.OutputDir, string(os.PathSeparator), r.name)
-	tmpConfigOutputFile := fmt.Sprintf("%s%s%s.tf.tmp", *r.ctx.OutputDir, string(os.PathSeparator), r.name)
+	configOutputFile := fmt.Sprintf("%s%s%s", *r.ctx.OutputDir, string(os.PathSeparator), r.getConfigFileName())
+	tmpConfigOutputFile := fmt.Sprintf("%s.tmp", configOutputFile)
 	file, err := os.OpenFile(tmpConfigOutputFile, os.O_CREATE|os.O_RDWR, 0666)
 	if err != nil {
 		return err
@@ -314,8 +340,19 @@ func (r *resourceDiscoveryBaseStep) writeConfiguration() error {
 	// Note that we still build a TF file even if no resources were discovered for this TF file.
 	// A user may run this command multiple times and may see stale resources if we don't overwrite the file with
 	// an empty one.
+	// The configuration of the new resources is appended to the configuration written by the previous export
+	existingConfig := []byte{}
+	if r.ctx.Incremental {
+		if existingConfig, err = ioutil.ReadFile(configOutputFile); err != nil && !os.IsNotExist(err) {
+			_ = file.Close()
+			return err
+		}
+	}
+
 	builder := &strings.Builder{}
-	builder.WriteString("## This configuration was generated by terraform-provider-oci\n\n")
+	if len(existingConfig) == 0 {
+		builder.WriteString("## This configuration was generated by terraform-provider-oci\n\n")
+	}
 
 	exportedResourceCount := 0
 	for _, resource := range r.discoveredResources {
@@ -354,7 +391,14 @@ func (r *resourceDiscoveryBaseStep) writeConfiguration() error {
 	}
 
 	// Format the HCL config
-	formattedString := hclwrite.Format([]byte(builder.String()))
+	formattedString, err := tf_export.FormatConfiguration([]byte(builder.String()))
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	if len(existingConfig) > 0 {
+		formattedString = append(append(bytes.TrimRight(existingConfig, "\n"), '\n', '\n'), formattedString...)
+	}
 
 	_, err = file.WriteString(string(formattedString))
 	if err != nil {
@@ -379,6 +423,14 @@ func (r *resourceDiscoveryBaseStep) writeConfiguration() error {
 	return nil
 }
 
+// getConfigFileName returns the name of the configuration file for the step, the region is added to it when exporting multiple regions
+func (r *resourceDiscoveryBaseStep) getConfigFileName() string {
+	if r.ctx.Region != "" {
+		return tf_export.GetConfigFileName(fmt.Sprintf("%s_%s.tf", r.name, tf_export.GetRegionProviderAlias(r.ctx.Region)))
+	}
+	return tf_export.GetConfigFileName(r.name + ".tf")
+}
+
 func (r *resourceDiscoveryBaseStep) getOmittedResources() []*tf_export.OCIResource {
 	return r.omittedResources
 }
@@ -518,7 +570,7 @@ func (r *resourceDiscoveryWithTargetIds) discover() error {
 			ociResource = processResults[0]
 		}
 
-		if ociResource.TerraformName, err = tf_export.GenerateTerraformNameFromResource(ociResource.SourceAttributes, resourceSchema.Schema); err != nil {
+		if ociResource.TerraformName, err = tf_export.GenerateTerraformNameForResource(ociResource, resourceSchema.Schema); err != nil {
 			ociResource.TerraformName = fmt.Sprintf("export_%s", resourceHint.ResourceAbbreviation)
 			ociResource.TerraformName = tf_export.CheckDuplicateResourceName(ociResource.TerraformName)
 		}
@@ -0,0 +1,139 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+/*
+generateSecretsFile writes the values of the sensitive variables to secrets.auto.tfvars, which is loaded by Terraform automatically
+- the attributes marked Sensitive in the resource schema are exported as sensitive variables instead of being written to the configuration
+- the values which are not returned by the services are left empty, to be set before running terraform plan
+The secrets file is added to the .gitignore of the output_path, so that the rest of the configuration can be committed
+*/
+func generateSecretsFile(ctx *tf_export.ResourceDiscoveryContext, sensitiveVars map[string]string) error {
+	if len(sensitiveVars) == 0 {
+		return nil
+	}
+	secretsFileName := tf_export.GetConfigFileName(globalvar.SecretsFile)
+	secretsFile := filepath.Join(*ctx.OutputDir, secretsFileName)
+
+	missingValues := 0
+	var content []byte
+	if tf_export.IsJsonSyntax() {
+		var err error
+		if content, err = json.MarshalIndent(sensitiveVars, "", "  "); err != nil {
+			return err
+		}
+	} else {
+		builder := &strings.Builder{}
+		builder.WriteString("## This file was generated by terraform-provider-oci, it has the values of the sensitive attributes and must not be committed\n\n")
+		for _, variable := range getSortedStringKeys(sensitiveVars) {
+			// the values are literals, so any template sequence is escaped
+			value := strings.NewReplacer("${", "$${", "%{", "%%{").Replace(sensitiveVars[variable])
+			builder.WriteString(fmt.Sprintf("%s = %q\n", variable, value))
+		}
+		content = []byte(builder.String())
+	}
+	for _, value := range sensitiveVars {
+		if value == "" {
+			missingValues++
+		}
+	}
+
+	if err := ioutil.WriteFile(secretsFile, content, 0600); err != nil {
+		return fmt.Errorf("[ERROR] unable to write secrets file %s: %s", secretsFile, err.Error())
+	}
+	if err := addToGitIgnore(*ctx.OutputDir, secretsFileName); err != nil {
+		return err
+	}
+	utils.Logf("[INFO] sensitive variables written to file at: %s", secretsFile)
+
+	summary := fmt.Sprintf("%d sensitive attributes were exported as variables with their values in '%s'", len(sensitiveVars), secretsFile)
+	if missingValues > 0 {
+		summary = fmt.Sprintf("%s, the values of %d of them were not found in discovery and are empty", summary, missingValues)
+	}
+	ctx.SummaryStatements = append(ctx.SummaryStatements, summary)
+	return nil
+}
+
+// addToGitIgnore adds a file to the .gitignore of a directory, the .gitignore is created if it does not exist
+func addToGitIgnore(outputDir string, fileName string) error {
+	gitIgnoreFile := filepath.Join(outputDir, globalvar.GitIgnoreFile)
+	content, err := ioutil.ReadFile(gitIgnoreFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == fileName {
+			return nil
+		}
+	}
+
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	content = append(content, []byte(fileName+"\n")...)
+	return ioutil.WriteFile(gitIgnoreFile, content, 0644)
+}
+
+// loadIncrementalSecrets adds the values of the secrets file of the previous export, so that the values set for the resources which are not written again are kept
+func loadIncrementalSecrets(outputDir string) error {
+	secretsFile := filepath.Join(outputDir, tf_export.GetConfigFileName(globalvar.SecretsFile))
+	content, err := ioutil.ReadFile(secretsFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if tf_export.IsJsonSyntax() {
+		values := map[string]string{}
+		if err := json.Unmarshal(content, &values); err != nil {
+			return fmt.Errorf("[ERROR] unable to parse secrets file %s: %s", secretsFile, err.Error())
+		}
+		for name, value := range values {
+			if existing, exists := tf_export.SensitiveVars[name]; !exists || existing == "" {
+				tf_export.SensitiveVars[name] = value
+			}
+		}
+		return nil
+	}
+
+	file, diags := hclsyntax.ParseConfig(content, secretsFile, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return fmt.Errorf("[ERROR] unable to parse secrets file %s: %s", secretsFile, diags.Error())
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	for name, attribute := range body.Attributes {
+		if value, exists := tf_export.SensitiveVars[name]; exists && value != "" {
+			continue
+		}
+		value, diags := attribute.Expr.Value(nil)
+		if diags.HasErrors() || value.IsNull() || !value.Type().Equals(cty.String) {
+			utils.Logf("[WARN] unable to read the value of '%s' from secrets file %s", name, secretsFile)
+			continue
+		}
+		tf_export.SensitiveVars[name] = value.AsString()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitGenerateSecretsFile(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "secrets-file")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+	if err := ioutil.WriteFile(filepath.Join(outputDir, globalvar.GitIgnoreFile), []byte(".terraform"), 0644); err != nil {
+		t.Fatalf("unable to write .gitignore: %v", err)
+	}
+
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+	defer func(vars map[string]string, sensitiveVars map[string]string) {
+		tf_export.Vars = vars
+		tf_export.SensitiveVars = sensitiveVars
+	}(tf_export.Vars, tf_export.SensitiveVars)
+
+	ctx := &tf_export.ResourceDiscoveryContext{ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir}}
+	sensitiveVars := map[string]string{
+		"oci_database_autonomous_database--admin_password--adb1": "",
+		"oci_kms_key--private_key--key1":                         "key${content}",
+	}
+	assert.NoError(t, generateVarsFile(map[string]string{"region": "\"us-ashburn-1\""}, sensitiveVars, &outputDir))
+	assert.NoError(t, generateSecretsFile(ctx, sensitiveVars))
+
+	content, err := ioutil.ReadFile(filepath.Join(outputDir, globalvar.VarsFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "variable oci_database_autonomous_database--admin_password--adb1 { sensitive = true }\n")
+	assert.Contains(t, string(content), "variable oci_kms_key--private_key--key1 { sensitive = true }\n")
+
+	content, err = ioutil.ReadFile(filepath.Join(outputDir, globalvar.SecretsFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "oci_database_autonomous_database--admin_password--adb1 = \"\"\n")
+	assert.Contains(t, string(content), "oci_kms_key--private_key--key1 = \"key$${content}\"\n")
+	assert.Contains(t, strings.Join(ctx.SummaryStatements, "\n"), "the values of 1 of them were not found in discovery")
+
+	// the secrets file is added to the existing .gitignore once
+	assert.NoError(t, addToGitIgnore(outputDir, globalvar.SecretsFile))
+	content, err = ioutil.ReadFile(filepath.Join(outputDir, globalvar.GitIgnoreFile))
+	assert.NoError(t, err)
+	assert.Equal(t, ".terraform\nsecrets.auto.tfvars\n", string(content))
+
+	// the sensitive variables and their values are kept by an incremental export
+	tf_export.Vars = map[string]string{}
+	tf_export.SensitiveVars = map[string]string{}
+	assert.NoError(t, loadIncrementalVars(outputDir))
+	assert.NoError(t, loadIncrementalSecrets(outputDir))
+	assert.Equal(t, map[string]string{"region": "\"us-ashburn-1\""}, tf_export.Vars)
+	assert.Equal(t, sensitiveVars, tf_export.SensitiveVars)
+
+	// the secrets file of an export with JSON syntax is found as well
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersionJson{Value: tf_export.TfVersionJson}
+	assert.NoError(t, generateSecretsFile(ctx, sensitiveVars))
+	assert.FileExists(t, filepath.Join(outputDir, globalvar.SecretsFile+".json"))
+	tf_export.SensitiveVars = map[string]string{}
+	assert.NoError(t, loadIncrementalSecrets(outputDir))
+	assert.Equal(t, sensitiveVars, tf_export.SensitiveVars)
+}
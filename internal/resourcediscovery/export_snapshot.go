@@ -0,0 +1,273 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	tf_client "github.com/oracle/terraform-provider-oci/internal/client"
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+	"github.com/oracle/terraform-provider-oci/internal/utils"
+)
+
+const exportSnapshotVersion = 1
+
+/*
+exportSnapshotVar is the snapshot the resources are exported from if from_snapshot is set
+The steps are restored from the snapshot instead of being discovered, so the export does not call the services
+*/
+var exportSnapshotVar *exportSnapshot
+
+/*
+exportSnapshot is the inventory of the resources discovered by the snapshot command
+It is written to <output_path>/snapshot.json and can be exported any number of times with export -from_snapshot, e.g. to try other naming strategies,
+filters or variables without discovering the resources again. The resources are written in the same format as the checkpoint of an export
+*/
+type exportSnapshot struct {
+	Version       int               `json:"version"`
+	CreatedAt     string            `json:"created_at"`
+	TenancyOcid   string            `json:"tenancy_ocid"`
+	CompartmentId string            `json:"compartment_id"`
+	Region        string            `json:"region"`
+	Vars          map[string]string `json:"vars,omitempty"`
+	ReferenceMap  map[string]string `json:"reference_map,omitempty"` // references which are not to the discovered resources e.g. compartment variables
+	Steps         []*snapshotStep   `json:"steps"`
+	Errors        []string          `json:"errors,omitempty"` // resources which could not be discovered
+}
+
+type snapshotStep struct {
+	Name string `json:"name"`
+	checkpointStepResources
+}
+
+// runSnapshotCommand discovers the resources of the export and writes them to a snapshot instead of generating the configuration
+func runSnapshotCommand(ctx *tf_export.ResourceDiscoveryContext) error {
+	utils.Logf("[INFO] Running snapshot command\n")
+	defer ctx.PrintSummary()
+	exportStart := time.Now()
+	defer elapsed("entire snapshot command", nil, 0)()
+
+	steps, err := discoverResources(ctx)
+	if err != nil {
+		return err
+	}
+
+	region, err := exportConfigProvider.Region()
+	if err != nil {
+		return err
+	}
+
+	snapshotFile := filepath.Join(*ctx.OutputDir, globalvar.SnapshotFile)
+	if err := writeExportSnapshot(ctx, steps, region, snapshotFile); err != nil {
+		return err
+	}
+
+	addRateLimiterSummary(ctx)
+	ctx.TimeTakenForEntireExport = time.Since(exportStart)
+	ctx.PostValidate()
+	return nil
+}
+
+func writeExportSnapshot(ctx *tf_export.ResourceDiscoveryContext, steps []resourceDiscoveryStep, region string, snapshotFile string) error {
+	snapshot := &exportSnapshot{
+		Version:      exportSnapshotVersion,
+		CreatedAt:    time.Now().UTC().Format(time.RFC3339),
+		TenancyOcid:  ctx.TenancyOcid,
+		Region:       region,
+		Vars:         tf_export.Vars,
+		ReferenceMap: map[string]string{},
+	}
+	if ctx.CompartmentId != nil {
+		snapshot.CompartmentId = *ctx.CompartmentId
+	}
+
+	discoveredIds := map[string]bool{}
+	total := 0
+	for _, step := range steps {
+		snapshotStep := &snapshotStep{Name: step.getBaseStep().name}
+		snapshotStep.DiscoveredResources = newCheckpointResources(step.getDiscoveredResources())
+		snapshotStep.OmittedResources = newCheckpointResources(step.getOmittedResources())
+		for _, resource := range step.getDiscoveredResources() {
+			discoveredIds[resource.Id] = true
+		}
+		total += len(snapshotStep.DiscoveredResources)
+		snapshot.Steps = append(snapshot.Steps, snapshotStep)
+	}
+
+	// the references to the discovered resources are generated again from their names when the snapshot is exported
+	tf_export.RefMapLock.Lock()
+	for id, reference := range tf_export.ReferenceMap {
+		if !discoveredIds[id] {
+			snapshot.ReferenceMap[id] = reference
+		}
+	}
+	tf_export.RefMapLock.Unlock()
+
+	for _, rdError := range ctx.ErrorList.Errors {
+		snapshot.Errors = append(snapshot.Errors, fmt.Sprintf("%s: %s", rdError.ResourceType, rdError.Error.Error()))
+	}
+
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(snapshotFile, content, 0644); err != nil {
+		return fmt.Errorf("[ERROR] unable to write snapshot %s: %s", snapshotFile, err.Error())
+	}
+
+	ctx.SummaryStatements = append(ctx.SummaryStatements, fmt.Sprintf("Snapshot of %d resources written to '%s'", total, snapshotFile))
+	return nil
+}
+
+func readExportSnapshot(snapshotFile string) (*exportSnapshot, error) {
+	content, err := ioutil.ReadFile(snapshotFile)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to read snapshot %s: %s", snapshotFile, err.Error())
+	}
+	snapshot := &exportSnapshot{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to parse snapshot %s: %s", snapshotFile, err.Error())
+	}
+	if snapshot.Version != exportSnapshotVersion {
+		return nil, fmt.Errorf("[ERROR] unsupported version %d of snapshot %s, take the snapshot again with this version of the provider", snapshot.Version, snapshotFile)
+	}
+	return snapshot, nil
+}
+
+// runExportFromSnapshot exports the resources of a snapshot, the clients are not configured since the services are not called
+func runExportFromSnapshot(args *tf_export.ExportCommandArgs) (error, Status) {
+	snapshot, err := readExportSnapshot(args.FromSnapshot)
+	if err != nil {
+		utils.Logln(err.Error())
+		return err, StatusFail
+	}
+	utils.Logf("[INFO] exporting the resources of snapshot %s taken at %s", args.FromSnapshot, snapshot.CreatedAt)
+
+	filterServices := len(args.Services) > 0 || len(args.ExcludeServices) > 0
+	args.CompartmentId = &snapshot.CompartmentId
+	sem = make(chan struct{}, args.Parallelism)
+
+	clients := &tf_client.OracleClients{
+		SdkClientMap:  map[string]interface{}{},
+		Configuration: map[string]string{"tenancy_ocid": snapshot.TenancyOcid},
+	}
+	ctx, err := createResourceDiscoveryContext(clients, args, snapshot.TenancyOcid)
+	if err != nil {
+		utils.Logln(err.Error())
+		return err, StatusFail
+	}
+	ctx.Filters = args.Filters
+	args.FinalizeServices(ctx)
+	if !filterServices {
+		ctx.Services = nil
+	}
+
+	exportSnapshotVar = snapshot
+	defer func() { exportSnapshotVar = nil }()
+
+	if err := runExportCommand(ctx); err != nil {
+		utils.Logln(err.Error())
+		return err, StatusFail
+	}
+	if len(ctx.ErrorList.Errors) > 0 {
+		return getListOfNotDiscoveredResources(ctx)
+	}
+	return nil, StatusSuccess
+}
+
+/*
+restoreSteps restores the steps of the export from the snapshot
+- only the steps of the services argument are restored if it is set, and the filters are applied to the resources again
+- the resources are renamed if a naming strategy is set, otherwise they keep the names from the snapshot
+*/
+func (s *exportSnapshot) restoreSteps(ctx *tf_export.ResourceDiscoveryContext) ([]resourceDiscoveryStep, error) {
+	for variable, value := range s.Vars {
+		tf_export.Vars[variable] = value
+	}
+	tf_export.RefMapLock.Lock()
+	for id, reference := range s.ReferenceMap {
+		if _, exists := tf_export.ReferenceMap[id]; !exists {
+			tf_export.ReferenceMap[id] = reference
+		}
+	}
+	tf_export.RefMapLock.Unlock()
+
+	services := tf_export.ConvertStringSliceToSet(ctx.Services, true)
+	var steps []resourceDiscoveryStep
+	for _, snapshotStep := range s.Steps {
+		if _, exists := services[snapshotStep.Name]; len(services) > 0 && !exists {
+			utils.Debugf("[DEBUG] skip step '%s' of the snapshot since it is not in the services", snapshotStep.Name)
+			continue
+		}
+		step := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: snapshotStep.Name}}
+		step.omittedResources = restoreCheckpointResources(ctx, snapshotStep.OmittedResources)
+
+		var err error
+		if step.discoveredResources, err = runFilters(restoreCheckpointResources(ctx, snapshotStep.DiscoveredResources), ctx.Filters); err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	renameSnapshotResources(steps)
+	for _, step := range steps {
+		for _, resource := range step.getDiscoveredResources() {
+			tf_export.RefMapLock.Lock()
+			tf_export.ReferenceMap[resource.Id] = resource.GetHclReferenceIdString()
+			tf_export.RefMapLock.Unlock()
+		}
+		utils.Logf("[INFO] restored %d resources of step '%s' from snapshot", len(step.getDiscoveredResources()), step.getBaseStep().name)
+	}
+	exportReportVar.addDiscovery(ctx, steps)
+	return steps, nil
+}
+
+/*
+renameSnapshotResources names the restored resources using the naming strategy
+The resources which cannot be named by the strategy keep the names from the snapshot, their names are registered first so that they are not reused
+The parents of the resources are set to the restored resources so that they have the new names
+*/
+func renameSnapshotResources(steps []resourceDiscoveryStep) {
+	byId := map[string]*tf_export.OCIResource{}
+	newNames := map[*tf_export.OCIResource]string{}
+	var keptNames []string
+	for _, step := range steps {
+		for _, resource := range append(step.getDiscoveredResources(), step.getOmittedResources()...) {
+			byId[resource.Id] = resource
+			if tf_export.ResourceNamingStrategyVar != nil {
+				if name, ok := tf_export.ResourceNamingStrategyVar.GetTerraformName(resource); ok && name != resource.TerraformName {
+					newNames[resource] = name
+					continue
+				}
+			}
+			keptNames = append(keptNames, resource.TerraformName)
+		}
+	}
+	registerResourceNames(keptNames)
+
+	renamed := map[string]string{}
+	for _, step := range steps {
+		for _, resource := range append(step.getDiscoveredResources(), step.getOmittedResources()...) {
+			if name, exists := newNames[resource]; exists {
+				oldAddress := resource.GetTerraformReference()
+				resource.TerraformName = tf_export.CheckDuplicateResourceName(name)
+				renamed[oldAddress] = resource.GetTerraformReference()
+			}
+			if resource.Parent != nil {
+				if parent, exists := byId[resource.Parent.Id]; exists {
+					resource.Parent = parent
+				}
+			}
+		}
+	}
+	renameResourceReferences(steps, renamed)
+}
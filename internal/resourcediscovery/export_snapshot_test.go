@@ -0,0 +1,147 @@
+// Copyright (c) 2017, 2023, Oracle and/or its affiliates. All rights reserved.
+// Licensed under the Mozilla Public License v2.0
+
+package resourcediscovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf_export "github.com/oracle/terraform-provider-oci/internal/commonexport"
+	"github.com/oracle/terraform-provider-oci/internal/globalvar"
+)
+
+// issue-routing-tag: terraform/default
+func TestUnitExportSnapshot(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "export-snapshot")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	defer func(tfVersion tf_export.TfHclVersion) { tf_export.TfHclVersionvar = tfVersion }(tf_export.TfHclVersionvar)
+	tf_export.TfHclVersionvar = &tf_export.TfHclVersion12{Value: tf_export.TfVersion12}
+	defer func(referenceMap map[string]string, resourceNameCount map[string]int, vars map[string]string, strategy tf_export.ResourceNamingStrategy) {
+		tf_export.ReferenceMap = referenceMap
+		tf_export.ResourceNameCount = resourceNameCount
+		tf_export.Vars = vars
+		tf_export.ResourceNamingStrategyVar = strategy
+	}(tf_export.ReferenceMap, tf_export.ResourceNameCount, tf_export.Vars, tf_export.ResourceNamingStrategyVar)
+	tf_export.ResourceNameCount = map[string]int{}
+	tf_export.ResourceNamingStrategyVar = nil
+
+	// the resources discovered by the snapshot command
+	compartmentId := "ocid1.compartment.1"
+	tf_export.Vars = map[string]string{"compartment_ocid": "\"ocid1.compartment.1\""}
+	tf_export.ReferenceMap = map[string]string{
+		compartmentId: "var.compartment_ocid",
+		"ocid1.vcn.1": "oci_core_vcn.vcn1.id",
+	}
+	ctx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, CompartmentId: &compartmentId, Snapshot: true},
+		TenancyOcid:       "ocid1.tenancy.1",
+	}
+	vcn := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.vcn.1", TerraformClass: "oci_core_vcn", TerraformName: "vcn1"},
+		CompartmentId:     compartmentId,
+		SourceAttributes: map[string]interface{}{
+			"compartment_id": compartmentId,
+			"display_name":   "vcn1",
+			"freeform_tags":  map[string]interface{}{"tf-name": "network"},
+			"cidr_blocks":    []interface{}{"10.0.0.0/16"},
+		},
+	}
+	subnet := &tf_export.OCIResource{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.subnet.1", TerraformClass: "oci_core_subnet", TerraformName: "subnet1", ImportId: "subnet-import-id"},
+		CompartmentId:     compartmentId,
+		SourceAttributes:  map[string]interface{}{"display_name": "subnet1", "vcn_id": "ocid1.vcn.1", "mtu": 9000},
+		Parent:            vcn,
+	}
+	coreStep := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "core"}}
+	coreStep.discoveredResources = []*tf_export.OCIResource{vcn, subnet}
+	identityStep := &resourceDiscoveryWithGraph{resourceDiscoveryBaseStep: resourceDiscoveryBaseStep{ctx: ctx, name: "identity"}}
+	identityStep.discoveredResources = []*tf_export.OCIResource{{
+		TerraformResource: tf_export.TerraformResource{Id: "ocid1.policy.1", TerraformClass: "oci_identity_policy", TerraformName: "policy1"},
+		SourceAttributes:  map[string]interface{}{"name": "policy1"},
+	}}
+
+	snapshotFile := filepath.Join(outputDir, globalvar.SnapshotFile)
+	assert.NoError(t, writeExportSnapshot(ctx, []resourceDiscoveryStep{coreStep, identityStep}, "us-ashburn-1", snapshotFile))
+	assert.Contains(t, strings.Join(ctx.SummaryStatements, "\n"), "Snapshot of 3 resources written to")
+
+	snapshot, err := readExportSnapshot(snapshotFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "ocid1.tenancy.1", snapshot.TenancyOcid)
+	assert.Equal(t, compartmentId, snapshot.CompartmentId)
+	assert.Equal(t, "us-ashburn-1", snapshot.Region)
+	assert.Equal(t, map[string]string{compartmentId: "var.compartment_ocid"}, snapshot.ReferenceMap)
+	assert.Len(t, snapshot.Steps, 2)
+
+	// the snapshot is exported with a naming strategy, the services and filters
+	tf_export.ResourceNameCount = map[string]int{}
+	tf_export.Vars = map[string]string{}
+	tf_export.ReferenceMap = map[string]string{}
+	tf_export.ResourceNamingStrategyVar, err = tf_export.ParseResourceNamingStrategy("tag:tf-name")
+	assert.NoError(t, err)
+	filter, err := tf_export.ParseFilterExpression("type != oci_core_route_table")
+	assert.NoError(t, err)
+	exportCtx := &tf_export.ResourceDiscoveryContext{
+		ExportCommandArgs: &tf_export.ExportCommandArgs{OutputDir: &outputDir, Services: []string{"core"}, Filters: []tf_export.ResourceFilter{filter}, FromSnapshot: snapshotFile},
+		TenancyOcid:       snapshot.TenancyOcid,
+	}
+	steps, err := snapshot.restoreSteps(exportCtx)
+	assert.NoError(t, err)
+	assert.Len(t, steps, 1)
+	resources := steps[0].getDiscoveredResources()
+	assert.Len(t, resources, 2)
+
+	restoredVcn, restoredSubnet := resources[0], resources[1]
+	assert.Equal(t, "network", restoredVcn.TerraformName)
+	assert.Equal(t, "subnet1", restoredSubnet.TerraformName)
+	assert.Equal(t, "subnet-import-id", restoredSubnet.ImportId)
+	assert.Equal(t, restoredVcn, restoredSubnet.Parent)
+	assert.Equal(t, 9000, restoredSubnet.SourceAttributes["mtu"])
+	assert.Equal(t, []interface{}{"10.0.0.0/16"}, restoredVcn.SourceAttributes["cidr_blocks"])
+	assert.Equal(t, map[string]string{
+		compartmentId:    "var.compartment_ocid",
+		"ocid1.vcn.1":    "oci_core_vcn.network.id",
+		"ocid1.subnet.1": "oci_core_subnet.subnet1.id",
+	}, tf_export.ReferenceMap)
+	assert.Equal(t, "\"ocid1.compartment.1\"", tf_export.Vars["compartment_ocid"])
+
+	exportSnapshotVar = snapshot
+	defer func() { exportSnapshotVar = nil }()
+	region, err := getExportRegion()
+	assert.NoError(t, err)
+	assert.Equal(t, "us-ashburn-1", region)
+}
+
+// issue-routing-tag: terraform/default
+func TestUnitValidateSnapshot(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "snapshot-export")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	args := &tf_export.ExportCommandArgs{OutputDir: &outputDir, Parallelism: 1, Snapshot: true}
+	assert.NoError(t, args.Validate())
+
+	args.GenerateState = true
+	assert.Error(t, args.Validate())
+
+	// the state of a snapshot can only be generated without terraform import
+	args = &tf_export.ExportCommandArgs{OutputDir: &outputDir, Parallelism: 1, FromSnapshot: "snapshot.json", GenerateState: true}
+	assert.Error(t, args.Validate())
+
+	args.NativeState = true
+	assert.NoError(t, args.Validate())
+
+	args.ResolveExternalReferences = true
+	assert.Error(t, args.Validate())
+}
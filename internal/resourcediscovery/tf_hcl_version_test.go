@@ -397,3 +397,27 @@ func TestUnitTfHclVersion12_getDoubleExpHclString(t *testing.T) {
 		})
 	}
 }
+
+// issue-routing-tag: terraform/default
+func TestUnitTfHclVersionJson(t *testing.T) {
+	tfversion := &tf_export.TfHclVersionJson{Value: tf_export.TfVersionJson}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"toString", tfversion.ToString(), "json"},
+		{"VarHclString", tfversion.GetVarHclString("variableName"), "\"${var.variableName}\""},
+		{"Reference", tfversion.GetReference("reference"), "\"reference\""},
+		{"DataSourceHclString", tfversion.GetDataSourceHclString("oci_core_images", "images"), "\"${data.oci_core_images.images}\""},
+		{"SingleExpHclString", tfversion.GetSingleExpHclString("oci_core_vcn.vcn1.id"), "\"${oci_core_vcn.vcn1.id}\""},
+		{"DoubleExpHclString", tfversion.GetDoubleExpHclString("oci_core_vcn.vcn1", "id"), "\"${oci_core_vcn.vcn1.id}\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("TfHclVersionJson %s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ func init() {
 	exportCoreNetworkSecurityGroupSecurityRuleHints.DatasourceClass = "oci_core_network_security_group_security_rules"
 	exportCoreNetworkSecurityGroupSecurityRuleHints.DatasourceItemsAttr = "security_rules"
 	exportCoreNetworkSecurityGroupSecurityRuleHints.ProcessDiscoveredResourcesFn = processNetworkSecurityGroupRules
+	exportCoreNetworkSecurityGroupSecurityRuleHints.IsGroupableByParent = true
 	exportCoreRouteTableHints.ProcessDiscoveredResourcesFn = processDefaultRouteTables
 	exportCoreSecurityListHints.ProcessDiscoveredResourcesFn = processDefaultSecurityLists
 	exportCoreVcnHints.ProcessDiscoveredResourcesFn = processCoreVcns
@@ -39,6 +40,7 @@ func init() {
 	exportCoreDrgRouteTableRouteRuleHints.DatasourceClass = "oci_core_drg_route_table_route_rules"
 	exportCoreDrgRouteTableRouteRuleHints.DatasourceItemsAttr = "drg_route_rules"
 	exportCoreDrgRouteTableRouteRuleHints.ProcessDiscoveredResourcesFn = processDrgRouteTableRouteRules
+	exportCoreDrgRouteTableRouteRuleHints.IsGroupableByParent = true
 	exportCoreDrgRouteDistributionHints.ProcessDiscoveredResourcesFn = processDrgRouteDistributions
 	tf_export.RegisterCompartmentGraphs("core", coreResourceGraph)
 	tf_export.RegisterRelatedResourcesGraph("oci_core_instance", relatedcoreinstance)
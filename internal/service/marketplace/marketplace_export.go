@@ -91,7 +91,7 @@ func findPublications(ctx *tf_export.ResourceDiscoveryContext, tfMeta *tf_export
 			Parent:         parent,
 		}
 
-		if resource.TerraformName, err = tf_export.GenerateTerraformNameFromResource(resource.SourceAttributes, publicationResource.Schema); err != nil {
+		if resource.TerraformName, err = tf_export.GenerateTerraformNameForResource(resource, publicationResource.Schema); err != nil {
 			resource.TerraformName = fmt.Sprintf("%s_%s", parent.Parent.TerraformName, *publication.Name)
 			resource.TerraformName = tf_export.CheckDuplicateResourceName(resource.TerraformName)
 		}
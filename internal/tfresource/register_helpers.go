@@ -1,6 +1,7 @@
 package tfresource
 
 import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/oracle/terraform-provider-oci/internal/globalvar"
 )
@@ -9,9 +10,53 @@ func RegisterResource(name string, resourceSchema *schema.Resource) {
 	if globalvar.OciResources == nil {
 		globalvar.OciResources = make(map[string]*schema.Resource)
 	}
+	addProviderTagsSupport(resourceSchema)
 	globalvar.OciResources[name] = resourceSchema
 }
 
+// addProviderTagsSupport plans the tags of the resource with the default_tags and the ignored tags of its provider, and merges the default tags into the tags when it is created or updated
+func addProviderTagsSupport(resourceSchema *schema.Resource) {
+	updatable := resourceSchema.Update != nil || resourceSchema.UpdateContext != nil
+	// The tags must be computed, as the planned tags are not the ones of the configuration
+	tagsKeys := map[string]bool{}
+	for _, tagsKey := range []string{"freeform_tags", "defined_tags"} {
+		if tags, ok := resourceSchema.Schema[tagsKey]; ok && tags.Type == schema.TypeMap && tags.Optional && tags.Computed {
+			tagsKeys[tagsKey] = updatable && !tags.ForceNew
+		}
+	}
+	if len(tagsKeys) == 0 {
+		return
+	}
+
+	if resourceSchema.CustomizeDiff == nil {
+		resourceSchema.CustomizeDiff = ProviderTagsCustomizeDiff(tagsKeys)
+	} else {
+		resourceSchema.CustomizeDiff = customdiff.All(resourceSchema.CustomizeDiff, ProviderTagsCustomizeDiff(tagsKeys))
+	}
+	if create := resourceSchema.Create; create != nil {
+		resourceSchema.Create = func(d *schema.ResourceData, m interface{}) error {
+			if err := setProviderDefaultTags(d, m, tagsKeys); err != nil {
+				return err
+			}
+			return create(d, m)
+		}
+	}
+	updatableTagsKeys := map[string]bool{}
+	for tagsKey, updatable := range tagsKeys {
+		if updatable {
+			updatableTagsKeys[tagsKey] = true
+		}
+	}
+	if update := resourceSchema.Update; update != nil && len(updatableTagsKeys) > 0 {
+		resourceSchema.Update = func(d *schema.ResourceData, m interface{}) error {
+			if err := setProviderDefaultTags(d, m, updatableTagsKeys); err != nil {
+				return err
+			}
+			return update(d, m)
+		}
+	}
+}
+
 func RegisterDatasource(name string, datasourceSchema *schema.Resource) {
 	if globalvar.OciDatasources == nil {
 		globalvar.OciDatasources = make(map[string]*schema.Resource)
@@ -4,13 +4,19 @@
 package tfresource
 
 import (
+	"context"
 	"fmt"
+	"path"
 	"reflect"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/oracle/terraform-provider-oci/internal/client"
 )
 
+// DefinedTagsToSuppress are the ignored defined tags of the provider, they are only used for the defined tags nested in other attributes
+// The tags of the resources are planned with the tags of their own provider by ProviderTagsCustomizeDiff
 var DefinedTagsToSuppress []string
 
 func DefinedTagsToMap(definedTags map[string]map[string]interface{}) map[string]interface{} {
@@ -45,14 +51,6 @@ func MapToDefinedTags(rawMap map[string]interface{}) (map[string]map[string]inte
 
 func DefinedTagsDiffSuppressFunction(key string, old string, new string, d *schema.ResourceData) bool {
 	keyParts := strings.Split(key, ".")
-	for _, tags := range DefinedTagsToSuppress {
-		if strings.EqualFold(strings.Join(keyParts[1:], "."), tags) {
-			return true
-		}
-	}
-	if old != "" && new != "" {
-		return false
-	}
 
 	// Find the specific defined_tag key name (mainly if a resource supports tagging at multiple levels)
 	// For example: "create_vnic_details.0.defined_tags.mynamespace.mykey" => "create_vnic_details.0.defined_tags"
@@ -63,6 +61,12 @@ func DefinedTagsDiffSuppressFunction(key string, old string, new string, d *sche
 			break
 		}
 	}
+	if isIgnoredTag(strings.Join(keyParts[len(definedTagKeyParts):], "."), DefinedTagsToSuppress) {
+		return true
+	}
+	if old != "" && new != "" {
+		return false
+	}
 
 	//Old value comes from refreshed state, while new value comes from config
 	oldRaw, newRaw := d.GetChange(strings.Join(definedTagKeyParts, "."))
@@ -85,6 +89,187 @@ func DefinedTagsDiffSuppressFunction(key string, old string, new string, d *sche
 	return false
 }
 
+/*
+ProviderTagsCustomizeDiff plans the tags of the resource with the default_tags and the ignored tags of its provider, which are read from the provider meta
+- the default tags which are not set in the configuration are merged into the tags, so an existing resource is updated when a default tag is added or changed
+- the ignored tags keep their value in the state
+- the keys of the defined tags are compared case-insensitively and keep their case in the state
+The tagsKeys map the tags attributes to whether they can be updated in place, the default tags missing from the resources that cannot be updated are not planned
+The tags attributes must be computed, since the planned tags are not the ones of the configuration
+*/
+func ProviderTagsCustomizeDiff(tagsKeys map[string]bool) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		// The tags of a new resource are merged with the default tags when it is created
+		clients, ok := meta.(*client.OracleClients)
+		if !ok || d.Id() == "" {
+			return nil
+		}
+		for tagsKey, updatable := range tagsKeys {
+			defaultTags, ignoredTags, ignoreCase := clients.DefaultFreeformTags, clients.IgnoreFreeformTags, false
+			if tagsKey == "defined_tags" {
+				defaultTags, ignoredTags, ignoreCase = clients.DefaultDefinedTags, clients.IgnoreDefinedTags, true
+			}
+			if !d.NewValueKnown(tagsKey) {
+				continue
+			}
+			oldRaw, newRaw := d.GetChange(tagsKey)
+			oldTags, oldOk := oldRaw.(map[string]interface{})
+			newTags, newOk := newRaw.(map[string]interface{})
+			if !oldOk || !newOk {
+				continue
+			}
+			if !updatable {
+				// Adding a default tag must not replace the resource
+				defaultTags = getExistingTags(defaultTags, oldTags, ignoreCase)
+			}
+
+			// The tags are always planned, so that the diff does not depend on the ignored tags of another provider
+			plannedTags := getPlannedTags(oldTags, newTags, defaultTags, ignoredTags, ignoreCase)
+			if isSameTags(plannedTags, oldTags) {
+				plannedTags = oldTags
+			} else if !updatable {
+				continue
+			}
+			if err := d.SetNew(tagsKey, plannedTags); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+/*
+getPlannedTags returns the tags of the configuration merged with the default tags and the ignored tags of the state
+If the configuration does not set the tags, the tags of the state are kept as the attribute is computed, and the default tags are set over them
+*/
+func getPlannedTags(oldTags map[string]interface{}, newTags map[string]interface{}, defaultTags map[string]interface{}, ignoredTags []string, ignoreCase bool) map[string]interface{} {
+	plannedTags := mergeDefaultTags(newTags, defaultTags, ignoreCase)
+	if len(newTags) == 0 {
+		plannedTags = mergeDefaultTags(defaultTags, oldTags, ignoreCase)
+	}
+
+	// The ignored tags are not managed by the configuration, they keep their value in the state
+	for tag := range plannedTags {
+		if isIgnoredTag(tag, ignoredTags) {
+			delete(plannedTags, tag)
+		}
+	}
+	for tag, value := range oldTags {
+		if isIgnoredTag(tag, ignoredTags) {
+			plannedTags[tag] = value
+		}
+	}
+
+	if ignoreCase {
+		oldKeys := make(map[string]string, len(oldTags))
+		for tag := range oldTags {
+			oldKeys[strings.ToLower(tag)] = tag
+		}
+		for tag, value := range plannedTags {
+			if oldTag, ok := oldKeys[strings.ToLower(tag)]; ok && oldTag != tag {
+				delete(plannedTags, tag)
+				plannedTags[oldTag] = value
+			}
+		}
+	}
+	return plannedTags
+}
+
+// getExistingTags returns the tags which are also in the existing tags
+func getExistingTags(tags map[string]interface{}, existingTags map[string]interface{}, ignoreCase bool) map[string]interface{} {
+	if ignoreCase {
+		existingTags = ToLowerCaseKeyMap(existingTags)
+	}
+	result := map[string]interface{}{}
+	for tag, value := range tags {
+		lookupTag := tag
+		if ignoreCase {
+			lookupTag = strings.ToLower(tag)
+		}
+		if _, ok := existingTags[lookupTag]; ok {
+			result[tag] = value
+		}
+	}
+	return result
+}
+
+// isSameTags returns true if both the tags have the same keys and values, the values of the state are strings
+func isSameTags(tags map[string]interface{}, otherTags map[string]interface{}) bool {
+	if len(tags) != len(otherTags) {
+		return false
+	}
+	for tag, value := range tags {
+		otherValue, ok := otherTags[tag]
+		if !ok || fmt.Sprintf("%v", value) != fmt.Sprintf("%v", otherValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIgnoredTag returns true if the tag key matches one of the ignored tags, which are tag keys or glob patterns like "Oracle-Tags.*", compared case-insensitively
+func isIgnoredTag(tag string, ignoredTags []string) bool {
+	for _, ignoredTag := range ignoredTags {
+		if strings.EqualFold(tag, ignoredTag) {
+			return true
+		}
+		if matched, err := path.Match(strings.ToLower(ignoredTag), strings.ToLower(tag)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+setProviderDefaultTags merges the default tags of the provider into the tags of the resource before it is created or updated, so that they are part of the request
+The tags are already planned with the default tags, unless they were not known when the resource was planned
+*/
+func setProviderDefaultTags(d *schema.ResourceData, meta interface{}, tagsKeys map[string]bool) error {
+	clients, ok := meta.(*client.OracleClients)
+	if !ok {
+		return nil
+	}
+	for tagsKey := range tagsKeys {
+		defaultTags, ignoreCase := clients.DefaultFreeformTags, false
+		if tagsKey == "defined_tags" {
+			defaultTags, ignoreCase = clients.DefaultDefinedTags, true
+		}
+		if len(defaultTags) == 0 {
+			continue
+		}
+		tags, ok := d.Get(tagsKey).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := d.Set(tagsKey, mergeDefaultTags(tags, defaultTags, ignoreCase)); err != nil {
+			return fmt.Errorf("unable to set the default %s: %v", strings.Replace(tagsKey, "_", " ", -1), err)
+		}
+	}
+	return nil
+}
+
+func mergeDefaultTags(tags map[string]interface{}, defaultTags map[string]interface{}, ignoreCase bool) map[string]interface{} {
+	mergedTags := make(map[string]interface{}, len(tags)+len(defaultTags))
+	keys := make(map[string]bool, len(tags))
+	for key, value := range tags {
+		mergedTags[key] = value
+		if ignoreCase {
+			key = strings.ToLower(key)
+		}
+		keys[key] = true
+	}
+	for key, value := range defaultTags {
+		lookupKey := key
+		if ignoreCase {
+			lookupKey = strings.ToLower(key)
+		}
+		if !keys[lookupKey] {
+			mergedTags[key] = value
+		}
+	}
+	return mergedTags
+}
+
 func ToLowerCaseKeyMap(original map[string]interface{}) map[string]interface{} {
 	lowercaseKeyMap := make(map[string]interface{}, len(original))
 	for key, value := range original {
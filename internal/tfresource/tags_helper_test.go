@@ -1,10 +1,14 @@
 package tfresource
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/oracle/terraform-provider-oci/internal/client"
 )
 
 func TestUnitDefinedTagsToMap(t *testing.T) {
@@ -268,3 +272,362 @@ func TestUnitDefinedTagsDiffSuppressFunction(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitMergeDefaultTags(t *testing.T) {
+	defaultTags := map[string]interface{}{"CostCenter": "42", "Department": "Finance"}
+	freeformTags := map[string]interface{}{"Department": "Sales"}
+	want := map[string]interface{}{"CostCenter": "42", "Department": "Sales"}
+	if got := mergeDefaultTags(freeformTags, defaultTags, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeDefaultTags() = %v, want %v", got, want)
+	}
+	if len(freeformTags) != 1 {
+		t.Errorf("mergeDefaultTags() modified the tags of the resource: %v", freeformTags)
+	}
+
+	// the keys of the defined tags are case-insensitive
+	defaultTags = map[string]interface{}{"Operations.CostCenter": "42"}
+	definedTags := map[string]interface{}{"operations.costcenter": "1"}
+	if got := mergeDefaultTags(definedTags, defaultTags, true); !reflect.DeepEqual(got, definedTags) {
+		t.Errorf("mergeDefaultTags() = %v, want %v", got, definedTags)
+	}
+	if got := mergeDefaultTags(nil, defaultTags, true); !reflect.DeepEqual(got, defaultTags) {
+		t.Errorf("mergeDefaultTags() = %v, want %v", got, defaultTags)
+	}
+}
+
+func TestUnitSetProviderDefaultTags(t *testing.T) {
+	clients := &client.OracleClients{
+		DefaultFreeformTags: map[string]interface{}{"CostCenter": "42", "Department": "Finance"},
+		DefaultDefinedTags:  map[string]interface{}{"Operations.CostCenter": "42"},
+	}
+	taggedSchema := map[string]*schema.Schema{
+		"freeform_tags": {Type: schema.TypeMap, Optional: true, Computed: true, Elem: schema.TypeString},
+		"defined_tags":  {Type: schema.TypeMap, Optional: true, Computed: true, Elem: schema.TypeString},
+	}
+	tagsKeys := map[string]bool{"freeform_tags": true, "defined_tags": true}
+	d := schema.TestResourceDataRaw(t, taggedSchema, map[string]interface{}{"freeform_tags": map[string]interface{}{"Department": "Sales"}})
+	if err := setProviderDefaultTags(d, clients, tagsKeys); err != nil {
+		t.Errorf("setProviderDefaultTags() error = %v", err)
+	}
+	if freeformTags, ok := d.GetOkExists("freeform_tags"); !ok || !reflect.DeepEqual(freeformTags, map[string]interface{}{"CostCenter": "42", "Department": "Sales"}) {
+		t.Errorf("setProviderDefaultTags() freeform_tags = %v", freeformTags)
+	}
+	if definedTags, ok := d.GetOkExists("defined_tags"); !ok || !reflect.DeepEqual(definedTags, map[string]interface{}{"Operations.CostCenter": "42"}) {
+		t.Errorf("setProviderDefaultTags() defined_tags = %v", definedTags)
+	}
+
+	// the tags are not changed without the clients of a provider
+	d = schema.TestResourceDataRaw(t, taggedSchema, map[string]interface{}{"freeform_tags": map[string]interface{}{"Department": "Sales"}})
+	if err := setProviderDefaultTags(d, nil, tagsKeys); err != nil {
+		t.Errorf("setProviderDefaultTags() error = %v", err)
+	}
+	if freeformTags := d.Get("freeform_tags"); !reflect.DeepEqual(freeformTags, map[string]interface{}{"Department": "Sales"}) {
+		t.Errorf("setProviderDefaultTags() freeform_tags = %v", freeformTags)
+	}
+}
+
+func TestUnitProviderTagsCustomizeDiff(t *testing.T) {
+	// the ignored defined tags of another provider are not used for the tags of the resource
+	defer func(definedTags []string) { DefinedTagsToSuppress = definedTags }(DefinedTagsToSuppress)
+	DefinedTagsToSuppress = []string{"Operations.*"}
+
+	newResource := func() *schema.Resource {
+		resource := &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"freeform_tags": {Type: schema.TypeMap, Optional: true, Computed: true, Elem: schema.TypeString},
+				"defined_tags":  {Type: schema.TypeMap, Optional: true, Computed: true, DiffSuppressFunc: DefinedTagsDiffSuppressFunction, Elem: schema.TypeString},
+			},
+			Update: func(*schema.ResourceData, interface{}) error { return nil },
+		}
+		addProviderTagsSupport(resource)
+		return resource
+	}
+	defaultTagsClients := &client.OracleClients{
+		DefaultFreeformTags: map[string]interface{}{"CostCenter": "42"},
+		DefaultDefinedTags:  map[string]interface{}{"Operations.CostCenter": "42"},
+		IgnoreDefinedTags:   []string{"Oracle-Tags.*"},
+	}
+	ignoredTagsClients := &client.OracleClients{
+		IgnoreFreeformTags: []string{"CreatedBy*"},
+		IgnoreDefinedTags:  []string{"Oracle-Tags.*"},
+	}
+	config := map[string]interface{}{
+		"freeform_tags": map[string]interface{}{"Department": "Finance"},
+		"defined_tags":  map[string]interface{}{"Operations.Env": "prod"},
+	}
+
+	tests := []struct {
+		name       string
+		clients    *client.OracleClients
+		resource   *schema.Resource
+		config     map[string]interface{}
+		attributes map[string]string
+		wantDiff   map[string]string
+	}{
+		{
+			name:    "Test an existing resource is updated with a newly added default tag",
+			clients: defaultTagsClients,
+			attributes: map[string]string{
+				"freeform_tags.%":                    "1",
+				"freeform_tags.Department":           "Finance",
+				"defined_tags.%":                     "2",
+				"defined_tags.Operations.Env":        "prod",
+				"defined_tags.Oracle-Tags.CreatedBy": "user",
+			},
+			wantDiff: map[string]string{
+				"freeform_tags.%":                    "2",
+				"freeform_tags.CostCenter":           "42",
+				"defined_tags.%":                     "3",
+				"defined_tags.Operations.CostCenter": "42",
+			},
+		},
+		{
+			name:    "Test an existing resource is updated with the new value of a default tag",
+			clients: defaultTagsClients,
+			attributes: map[string]string{
+				"freeform_tags.%":                    "2",
+				"freeform_tags.CostCenter":           "41",
+				"freeform_tags.Department":           "Finance",
+				"defined_tags.%":                     "2",
+				"defined_tags.Operations.CostCenter": "42",
+				"defined_tags.Operations.Env":        "prod",
+			},
+			wantDiff: map[string]string{
+				"freeform_tags.CostCenter": "42",
+			},
+		},
+		{
+			name:    "Test an existing resource with the default tags is not updated",
+			clients: defaultTagsClients,
+			attributes: map[string]string{
+				"freeform_tags.%":                    "2",
+				"freeform_tags.CostCenter":           "42",
+				"freeform_tags.Department":           "Finance",
+				"defined_tags.%":                     "2",
+				"defined_tags.operations.costcenter": "42",
+				"defined_tags.Operations.Env":        "prod",
+			},
+			wantDiff: map[string]string{},
+		},
+		{
+			name:    "Test a default tag set in the configuration wins over the default tags",
+			clients: defaultTagsClients,
+			config: map[string]interface{}{
+				"freeform_tags": map[string]interface{}{"Department": "Finance", "CostCenter": "1"},
+				"defined_tags":  map[string]interface{}{"Operations.Env": "prod"},
+			},
+			attributes: map[string]string{
+				"freeform_tags.%":                    "2",
+				"freeform_tags.CostCenter":           "42",
+				"freeform_tags.Department":           "Finance",
+				"defined_tags.%":                     "2",
+				"defined_tags.Operations.CostCenter": "42",
+				"defined_tags.Operations.Env":        "prod",
+			},
+			wantDiff: map[string]string{
+				"freeform_tags.CostCenter": "1",
+			},
+		},
+		{
+			name:    "Test the diff of the ignored tags is suppressed",
+			clients: ignoredTagsClients,
+			attributes: map[string]string{
+				"freeform_tags.%":                    "2",
+				"freeform_tags.CreatedByTool":        "packer",
+				"freeform_tags.Department":           "Finance",
+				"defined_tags.%":                     "3",
+				"defined_tags.Oracle-Tags.CreatedBy": "user",
+				"defined_tags.Oracle-Tags.CreatedOn": "2023-01-01",
+				"defined_tags.Operations.Env":        "prod",
+			},
+			wantDiff: map[string]string{},
+		},
+		{
+			name:    "Test the diff of the other tags is not suppressed",
+			clients: ignoredTagsClients,
+			config: map[string]interface{}{
+				"freeform_tags": map[string]interface{}{"Department": "Sales"},
+				"defined_tags":  map[string]interface{}{"Operations.Env": "dev"},
+			},
+			attributes: map[string]string{
+				"freeform_tags.%":                    "2",
+				"freeform_tags.CreatedByTool":        "packer",
+				"freeform_tags.Department":           "Finance",
+				"defined_tags.%":                     "2",
+				"defined_tags.Oracle-Tags.CreatedBy": "user",
+				"defined_tags.Operations.Env":        "prod",
+			},
+			wantDiff: map[string]string{
+				"freeform_tags.Department":    "Sales",
+				"defined_tags.Operations.Env": "dev",
+			},
+		},
+		{
+			name:    "Test the tags of a resource without update are not updated with a newly added default tag",
+			clients: defaultTagsClients,
+			resource: func() *schema.Resource {
+				resource := &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"freeform_tags": {Type: schema.TypeMap, Optional: true, Computed: true, ForceNew: true, Elem: schema.TypeString},
+						"defined_tags":  {Type: schema.TypeMap, Optional: true, Computed: true, ForceNew: true, Elem: schema.TypeString},
+					},
+				}
+				addProviderTagsSupport(resource)
+				return resource
+			}(),
+			attributes: map[string]string{
+				"freeform_tags.%":                    "1",
+				"freeform_tags.Department":           "Finance",
+				"defined_tags.%":                     "2",
+				"defined_tags.Operations.CostCenter": "42",
+				"defined_tags.Operations.Env":        "prod",
+			},
+			wantDiff: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := tt.resource
+			if resource == nil {
+				resource = newResource()
+			}
+			rawConfig := tt.config
+			if rawConfig == nil {
+				rawConfig = config
+			}
+			tt.attributes["id"] = "ocid1.vcn.1"
+			state := &terraform.InstanceState{ID: "ocid1.vcn.1", Attributes: tt.attributes}
+			diff, err := resource.Diff(context.Background(), state, terraform.NewResourceConfigRaw(rawConfig), tt.clients)
+			if err != nil {
+				t.Errorf("Diff() error = %v", err)
+				return
+			}
+			gotDiff := map[string]string{}
+			if diff != nil {
+				for key, attribute := range diff.Attributes {
+					gotDiff[key] = attribute.New
+				}
+			}
+			if !reflect.DeepEqual(gotDiff, tt.wantDiff) {
+				t.Errorf("Diff() = %v, want %v", gotDiff, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestUnitProviderTagsCustomizeDiffAliasedProviders(t *testing.T) {
+	resource := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"freeform_tags": {Type: schema.TypeMap, Optional: true, Computed: true, Elem: schema.TypeString},
+		},
+		Update: func(*schema.ResourceData, interface{}) error { return nil },
+	}
+	addProviderTagsSupport(resource)
+	state := &terraform.InstanceState{
+		ID: "ocid1.vcn.1",
+		Attributes: map[string]string{
+			"id":                       "ocid1.vcn.1",
+			"freeform_tags.%":          "2",
+			"freeform_tags.Department": "Finance",
+			"freeform_tags.Region":     "iad",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"freeform_tags": map[string]interface{}{"Department": "Finance"}})
+
+	// each resource is planned with the default tags of its own provider, whichever provider was configured last
+	ashburn := &client.OracleClients{DefaultFreeformTags: map[string]interface{}{"Region": "iad"}}
+	phoenix := &client.OracleClients{DefaultFreeformTags: map[string]interface{}{"Region": "phx"}}
+	diff, err := resource.Diff(context.Background(), state, config, ashburn)
+	if err != nil || (diff != nil && len(diff.Attributes) > 0) {
+		t.Errorf("Diff() = %v, %v, want no diff with the default tags of the provider of the resource", diff, err)
+	}
+	diff, err = resource.Diff(context.Background(), state, config, phoenix)
+	if err != nil || diff == nil || diff.Attributes["freeform_tags.Region"] == nil || diff.Attributes["freeform_tags.Region"].New != "phx" {
+		t.Errorf("Diff() = %v, %v, want the default tag of the other provider", diff, err)
+	}
+}
+
+func TestUnitIsIgnoredTag(t *testing.T) {
+	ignoredTags := []string{"Oracle-Tags.*", "orcl-cloud.*", "CreatedBy*", "Operations.CostCenter"}
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{tag: "Oracle-Tags.CreatedBy", want: true},
+		{tag: "oracle-tags.createdon", want: true},
+		{tag: "orcl-cloud.free-tier-retained", want: true},
+		{tag: "CreatedByTool", want: true},
+		{tag: "operations.costcenter", want: true},
+		{tag: "Operations.Env", want: false},
+		{tag: "Department", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := isIgnoredTag(tt.tag, ignoredTags); got != tt.want {
+				t.Errorf("isIgnoredTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitIgnoredTagsDiffSuppressFunction(t *testing.T) {
+	defer func(definedTags []string) { DefinedTagsToSuppress = definedTags }(DefinedTagsToSuppress)
+	DefinedTagsToSuppress = []string{"Oracle-Tags.*"}
+
+	// the ignored defined tags of the provider are used for the defined tags nested in other attributes
+	resource := &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"create_vnic_details": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"defined_tags": {Type: schema.TypeMap, Optional: true, DiffSuppressFunc: DefinedTagsDiffSuppressFunction, Elem: schema.TypeString},
+					},
+				},
+			},
+		},
+	}
+	state := &terraform.InstanceState{
+		ID: "ocid1.instance.1",
+		Attributes: map[string]string{
+			"id":                                   "ocid1.instance.1",
+			"create_vnic_details.#":                "1",
+			"create_vnic_details.0.defined_tags.%": "2",
+			"create_vnic_details.0.defined_tags.Oracle-Tags.CreatedBy": "user",
+			"create_vnic_details.0.defined_tags.Operations.Env":        "prod",
+		},
+	}
+	tests := []struct {
+		name     string
+		env      string
+		wantDiff bool
+	}{
+		{name: "Test diff of the ignored tags is suppressed", env: "prod", wantDiff: false},
+		{name: "Test diff of the other tags is not suppressed", env: "dev", wantDiff: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"create_vnic_details": []interface{}{map[string]interface{}{"defined_tags": map[string]interface{}{"Operations.Env": tt.env}}},
+			})
+			diff, err := resource.Diff(context.Background(), state, config, nil)
+			if err != nil {
+				t.Errorf("Diff() error = %v", err)
+				return
+			}
+			gotDiff := false
+			if diff != nil {
+				for key := range diff.Attributes {
+					if key != "create_vnic_details.0.defined_tags.%" {
+						gotDiff = true
+					}
+				}
+			}
+			if gotDiff != tt.wantDiff {
+				t.Errorf("Diff() = %v, want diff %v", diff, tt.wantDiff)
+			}
+		})
+	}
+}
@@ -27,8 +27,9 @@ func init() {
 }
 
 func main() {
-	// TODO: input for resource discovery from a config file
-	var command = flag.String("command", "", "Command to run. Supported commands include: 'export', 'list_export_resources' and 'list_export_services'. 'list_export_services' supports json format.")
+	var command = flag.String("command", "", "Command to run. Supported commands include: 'export', 'snapshot', 'list_export_resources' and 'list_export_services'. 'snapshot' discovers the resources like 'export' and writes them to snapshot.json under output_path instead of the configuration. 'list_export_resources' and 'list_export_services' support json format.")
+	var configPath = flag.String("config", "", "[export] Path to a YAML or JSON file with the export arguments. Arguments passed on the command line take precedence over the values in the file")
+	var listExportResourcesPath = flag.String("list_export_resources_path", "", "[export] Path to output list of discoverable resources in json format, with the data source, parent resource, lifecycle states and scope used to discover each resource")
 	var listExportServicesPath = flag.String("list_export_services_path", "", "[export] Path to output list of supported services in json format")
 	var compartmentId = flag.String("compartment_id", "", "[export] OCID of a compartment to export. If no compartment id nor name is specified, the root compartment will be used.")
 	var compartmentName = flag.String("compartment_name", "", "[export] The name of a compartment to export.")
@@ -38,9 +39,23 @@ func main() {
 	var excludeServices = flag.String("exclude_services", "", "[export] [experimental] Comma-separated list of service resources to exclude from export. If a service is present in both 'services' and 'exclude_services' argument, it will be excluded.")
 	var ids = flag.String("ids", "", "[export] Comma-separated list of tuples <resource Type:resource ID> for resources to export. The ID could either be an OCID or a Terraform import ID. By default, all resources are exported.")
 	var generateStateFile = flag.Bool("generate_state", false, "[export][experimental] Set this to import the discovered resources into a state file along with the Terraform configuration")
+	var nativeState = flag.Bool("native_state", false, "[export][experimental] Set this with generate_state to write the state file from the discovered resources instead of running terraform import for each resource. The terraform CLI is not required. Cannot be used with tf_version 0.11")
+	var generateImportBlocks = flag.Bool("generate_imports", false, "[export][experimental] Set this to write Terraform v1.5+ `import` blocks for the discovered resources to imports.tf instead of generating a state file. Cannot be used with generate_state")
+	var recursive = flag.Bool("recursive", false, "[export][experimental] Set this to export the compartment along with all the compartments in its subtree. Each compartment is exported to its own directory and called as a module from the generated root module. Cannot be used with generate_state, generate_imports or ids")
+	var regions = flag.String("regions", "", "[export][experimental] Comma-separated list of regions to export in a single run. The resources of each region use a provider configuration aliased with the region. By default, the region of the provider configuration is exported")
+	var generateGraph = flag.Bool("generate_graph", false, "[export] Set this to write the dependency graph of the exported resources, built from their references and parent resources, to resource_graph.dot (Graphviz DOT) and resource_graph.json")
+	var resume = flag.Bool("resume", false, "[export][experimental] Set this to resume an interrupted or partially failed export from the checkpoint in output_path. The completed steps are not discovered again and only the failed imports are retried. Cannot be used with recursive or regions")
+	var incremental = flag.Bool("incremental", false, "[export][experimental] Set this to export into an output_path with the state file of a previous export. The resources in the state keep their names and configuration, only the new resources are appended to the configuration and the resources no longer found are reported. Cannot be used with recursive, regions or tf_version json")
+	var namingStrategy = flag.String("naming_strategy", "", "[export] The strategy to name the exported resources. The allowed values are :\n * default (the display name of the resource)\n * tag:<tag key> (the value of a freeform tag or a defined tag, e.g. tag:Operations.tf-name)\n * template:<Go template> (a template of the resource attributes, e.g. template:{{.display_name}}_{{.availability_domain | short}})\n * hash (a hash of the OCID of the resource)\nThe resources which cannot be named by the strategy are named from their display name")
+	var layout = flag.String("layout", "flat", "[export][experimental] The layout of the generated configuration. The allowed values are :\n * flat (the resources of each service are written to <service>.tf in output_path)\n * modules (each service is written to its own directory as a child module, the references to the resources of other services are module variables wired to the outputs of the other modules in the generated root module)\nCannot be used with recursive, which exports each compartment as a child module, or with generate_state, generate_imports, regions or incremental")
+	var groupResources = flag.Bool("group_resources", false, "[export][experimental] Set this to write the resources of the same type and parent, like the security rules of a network security group or the route rules of a DRG route table, as a single resource using for_each over a local map. Cannot be used with incremental or tf_version 0.11")
+	var resolveExternalReferences = flag.Bool("resolve_external_references", false, "[export][experimental] Set this to replace the OCIDs of objects outside the export, like images, subnets and tag namespaces, with data sources looking up the object by its name and compartment. The data sources are written to external_references.tf. Cannot be used with incremental or regions")
+	var fromSnapshot = flag.String("from_snapshot", "", "[export][experimental] Path to a snapshot.json written by the snapshot command. The configuration is generated from the resources of the snapshot without calling the services. Cannot be used with recursive, regions, resume, incremental, resolve_external_references or ids, and the state can only be generated with native_state")
+	var reportPath = flag.String("report_path", "", "[export] Path to write a JSON report of the export with the discovered, omitted and failed resources, their errors and the time taken by each step")
 	var help = flag.Bool("help", false, "Prints usage options")
-	var tfVersion = flag.String("tf_version", "0.12", "The version of terraform syntax to generate for configurations. The state file will be written in v0.12 only. The allowed values are :\n * 0.11\n * 0.12")
+	var tfVersion = flag.String("tf_version", "0.12", "The version of terraform syntax to generate for configurations. The state file will be written in v0.12 only. The allowed values are :\n * 0.11\n * 0.12\n * json (Terraform JSON syntax, generates .tf.json files)")
 	var retryTimeout = flag.String("retry_timeout", "15s", "[export] The time duration for which API calls will wait and retry operation in case of API errors. By default, the retry timeout duration is 15s")
+	var rateLimit = flag.Float64("rate_limit", tf_export.DefaultExportRateLimit, "[export] The maximum number of API requests per second to each service. The number of concurrent requests to a service is lowered when its requests are throttled and raised again as they succeed. Set to 0 to not limit the requests. By default the value is 10")
 	var parallelism = flag.Int("parallelism", 1, "The number of threads to use for resource discovery. By default the value is 1")
 	var varsResourceLevel = flag.String("variables_resource_level", "", "[export] List of top-level attributes to be export as variable following format resourceType.attribute, if attribute is present in variables_global_level, it will be excluded for this resourceType")
 	var varsGlobalLevel = flag.String("variables_global_level", "", "[export] List of top-level attributes to be export as variable following format attribute1,attribute2, if attribute present in variables_resource_level, it will be excluded for this resourceType")
@@ -62,57 +77,132 @@ func main() {
 		})
 	} else {
 		switch *command {
-		case "export":
-
-			var terraformVersion tf_export.TfHclVersion
-			if tf_export.TfVersionEnum(*tfVersion) == tf_export.TfVersion11 {
-				terraformVersion = &tf_export.TfHclVersion11{Value: tf_export.TfVersionEnum(*tfVersion)}
-			} else if *tfVersion == "" || tf_export.TfVersionEnum(*tfVersion) == tf_export.TfVersion12 {
-				terraformVersion = &tf_export.TfHclVersion12{Value: tf_export.TfVersionEnum(*tfVersion)}
-			} else {
-				color.Red("[ERROR]: Invalid tf_version '%s', supported values: 0.11, 0.12\n", *tfVersion)
-				os.Exit(1)
+		case "export", "snapshot":
+
+			exportConfig := &tf_export.ExportConfig{}
+			if *configPath != "" {
+				var err error
+				if exportConfig, err = tf_export.LoadExportConfig(*configPath); err != nil {
+					color.Red("%v", err)
+					os.Exit(1)
+				}
 			}
 
-			if *parallelism < 1 {
-				color.Red("[ERROR] parallelism cannot be less than 1, specify at least 1")
-				os.Exit(1)
+			// Values passed on the command line take precedence over the values in the config file
+			setFlags := map[string]bool{}
+			flag.Visit(func(f *flag.Flag) {
+				setFlags[f.Name] = true
+			})
+			if setFlags["compartment_id"] || exportConfig.CompartmentId == "" {
+				exportConfig.CompartmentId = *compartmentId
 			}
-
-			args := &tf_export.ExportCommandArgs{
-				CompartmentId:                compartmentId,
-				CompartmentName:              compartmentName,
-				OutputDir:                    outputPath,
-				GenerateState:                *generateStateFile,
-				TFVersion:                    &terraformVersion,
-				RetryTimeout:                 retryTimeout,
-				IsExportWithRelatedResources: *includeRelatedResources,
-				Parallelism:                  *parallelism,
+			if setFlags["compartment_name"] || exportConfig.CompartmentName == "" {
+				exportConfig.CompartmentName = *compartmentName
 			}
-
-			if services != nil && *services != "" {
-				args.Services = strings.Split(*services, ",")
+			if setFlags["output_path"] || exportConfig.OutputPath == "" {
+				exportConfig.OutputPath = *outputPath
 			}
-
-			if varsResourceLevel != nil && *varsResourceLevel != "" {
-				args.VarsExportResourceLevel = strings.Split(*varsResourceLevel, ",")
+			if setFlags["retry_timeout"] || exportConfig.RetryTimeout == "" {
+				exportConfig.RetryTimeout = *retryTimeout
+			}
+			if setFlags["tf_version"] || exportConfig.TfVersion == "" {
+				exportConfig.TfVersion = *tfVersion
+			}
+			if setFlags["parallelism"] || exportConfig.Parallelism == 0 {
+				exportConfig.Parallelism = *parallelism
+			}
+			if setFlags["rate_limit"] || exportConfig.RateLimit == nil {
+				exportConfig.RateLimit = rateLimit
+			}
+			if setFlags["generate_state"] {
+				exportConfig.GenerateState = *generateStateFile
+			}
+			if setFlags["native_state"] {
+				exportConfig.NativeState = *nativeState
+			}
+			if setFlags["generate_imports"] {
+				exportConfig.GenerateImportBlocks = *generateImportBlocks
+			}
+			if setFlags["recursive"] {
+				exportConfig.Recursive = *recursive
+			}
+			if setFlags["regions"] || len(exportConfig.Regions) == 0 {
+				exportConfig.Regions = splitFlagValue(*regions)
+			}
+			if setFlags["generate_graph"] {
+				exportConfig.GenerateGraph = *generateGraph
+			}
+			if setFlags["resume"] {
+				exportConfig.Resume = *resume
+			}
+			if setFlags["incremental"] {
+				exportConfig.Incremental = *incremental
+			}
+			if setFlags["naming_strategy"] || exportConfig.NamingStrategy == "" {
+				exportConfig.NamingStrategy = *namingStrategy
+			}
+			if setFlags["layout"] || exportConfig.Layout == "" {
+				exportConfig.Layout = *layout
+			}
+			if setFlags["group_resources"] {
+				exportConfig.GroupResources = *groupResources
+			}
+			if setFlags["from_snapshot"] || exportConfig.FromSnapshot == "" {
+				exportConfig.FromSnapshot = *fromSnapshot
+			}
+			if setFlags["resolve_external_references"] {
+				exportConfig.ResolveExternalRefs = *resolveExternalReferences
+			}
+			if setFlags["report_path"] || exportConfig.ReportPath == "" {
+				exportConfig.ReportPath = *reportPath
+			}
+			if setFlags["include_related_resources"] {
+				exportConfig.IncludeRelatedResources = *includeRelatedResources
+			}
+			if setFlags["services"] || len(exportConfig.Services) == 0 {
+				exportConfig.Services = splitFlagValue(*services)
+			}
+			if setFlags["exclude_services"] || len(exportConfig.ExcludeServices) == 0 {
+				exportConfig.ExcludeServices = splitFlagValue(*excludeServices)
+			}
+			if setFlags["ids"] || len(exportConfig.IDs) == 0 {
+				exportConfig.IDs = splitFlagValue(*ids)
+			}
+			if setFlags["variables_resource_level"] || len(exportConfig.VariablesResourceLevel) == 0 {
+				exportConfig.VariablesResourceLevel = splitFlagValue(*varsResourceLevel)
+			}
+			if setFlags["variables_global_level"] || len(exportConfig.VariablesGlobalLevel) == 0 {
+				exportConfig.VariablesGlobalLevel = splitFlagValue(*varsGlobalLevel)
 			}
 
-			if varsGlobalLevel != nil && *varsGlobalLevel != "" {
-				args.VarExportGlobalLevel = strings.Split(*varsGlobalLevel, ",")
+			var terraformVersion tf_export.TfHclVersion
+			if tf_export.TfVersionEnum(exportConfig.TfVersion) == tf_export.TfVersion11 {
+				terraformVersion = &tf_export.TfHclVersion11{Value: tf_export.TfVersionEnum(exportConfig.TfVersion)}
+			} else if exportConfig.TfVersion == "" || tf_export.TfVersionEnum(exportConfig.TfVersion) == tf_export.TfVersion12 {
+				terraformVersion = &tf_export.TfHclVersion12{Value: tf_export.TfVersionEnum(exportConfig.TfVersion)}
+			} else if tf_export.TfVersionEnum(exportConfig.TfVersion) == tf_export.TfVersionJson {
+				terraformVersion = &tf_export.TfHclVersionJson{Value: tf_export.TfVersionEnum(exportConfig.TfVersion)}
+			} else {
+				color.Red("[ERROR]: Invalid tf_version '%s', supported values: 0.11, 0.12, json\n", exportConfig.TfVersion)
+				os.Exit(1)
 			}
 
-			if excludeServices != nil && *excludeServices != "" {
-				args.ExcludeServices = strings.Split(*excludeServices, ",")
+			if exportConfig.Parallelism < 1 {
+				color.Red("[ERROR] parallelism cannot be less than 1, specify at least 1")
+				os.Exit(1)
 			}
 
-			if ids != nil && *ids != "" {
-				args.IDs = strings.Split(*ids, ",")
+			args, err := exportConfig.ToExportCommandArgs(&terraformVersion)
+			if err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
 			}
 
-			if filterFlag != nil {
-				args.Filters = filterFlag
+			// filters passed on the command line are applied in addition to the filters in the config file
+			if len(filterFlag) > 0 {
+				args.Filters = append(args.Filters, filterFlag...)
 			}
+			args.Snapshot = *command == "snapshot"
 
 			err, status := resourcediscovery.RunExportCommand(args)
 			if err != nil {
@@ -121,7 +211,7 @@ func main() {
 			os.Exit(int(status))
 
 		case "list_export_resources":
-			if err := resourcediscovery.RunListExportableResourcesCommand(); err != nil {
+			if err := resourcediscovery.RunListExportableResourcesCommand(*listExportResourcesPath); err != nil {
 				color.Red("%v", err)
 				os.Exit(1)
 			}
@@ -136,3 +226,11 @@ func main() {
 		}
 	}
 }
+
+// splitFlagValue splits a comma-separated flag value, an empty value results in a nil slice
+func splitFlagValue(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}